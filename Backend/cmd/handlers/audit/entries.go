@@ -0,0 +1,122 @@
+// Package audit contains the controllers for reading the audit log
+package audit
+
+import (
+	"Home-Intranet-v2-Backend/internal/audit/models"
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// actions are the values the action query parameter accepts
+var actions = []string{models.ActionCreate, models.ActionUpdate, models.ActionDelete}
+
+// ListEntries returns the audit log, newest first. The user, collection, document and action query
+// parameters narrow it down to the changes made by one user, to one collection or document, or of
+// one kind, and from and to (RFC 3339 times) to the changes made at or after from and before to.
+func (handler Handler) ListEntries(w http.ResponseWriter, request *http.Request) {
+	values := request.URL.Query()
+
+	cursor, limit, err := repository.ParsePaging(values)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Error parsing paging parameters", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	filter, err := buildEntryFilter(values)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Error building audit entry filter", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	sort := repository.Sort{
+		repository.Desc("timestamp"),
+	}
+
+	page, err := repository.ListPage[models.AuditEntry](request.Context(), handler.Repository, filter, sort, cursor, limit)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		response.Error(w, err)
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue retriving audit entries", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	response.Page(w, request, page)
+	return
+}
+
+// buildEntryFilter converts the query parameters of ListEntries into a filter
+func buildEntryFilter(values url.Values) (repository.Filter, error) {
+	filters := []repository.Filter{}
+
+	for _, param := range []struct {
+		name  string
+		field string
+	}{
+		{name: "user", field: "actor.user_id"},
+		{name: "document", field: "document_id"},
+	} {
+		hex := strings.TrimSpace(values.Get(param.name))
+		if hex == "" {
+			continue
+		}
+
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			return repository.Filter{}, fmt.Errorf("invalid %s id %q", param.name, hex)
+		}
+
+		filters = append(filters, repository.Eq(param.field, id))
+	}
+
+	if collection := strings.TrimSpace(values.Get("collection")); collection != "" {
+		filters = append(filters, repository.Eq("collection", collection))
+	}
+
+	if action := strings.TrimSpace(values.Get("action")); action != "" {
+		if !slices.Contains(actions, action) {
+			return repository.Filter{}, fmt.Errorf("action must be one of %s", strings.Join(actions, ", "))
+		}
+
+		filters = append(filters, repository.Eq("action", action))
+	}
+
+	var bounds [2]time.Time
+	for i, name := range []string{"from", "to"} {
+		value := strings.TrimSpace(values.Get(name))
+		if value == "" {
+			continue
+		}
+
+		bound, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return repository.Filter{}, fmt.Errorf("%s must be an RFC 3339 time, such as 2026-01-02T15:04:05Z", name)
+		}
+
+		bounds[i] = bound.UTC()
+	}
+
+	if !bounds[0].IsZero() && !bounds[1].IsZero() && !bounds[0].Before(bounds[1]) {
+		return repository.Filter{}, fmt.Errorf("from must be before to")
+	}
+
+	filters = append(filters, repository.Between("timestamp", bounds[0], bounds[1]))
+
+	return repository.And(filters...), nil
+}
@@ -0,0 +1,177 @@
+package audit
+
+import (
+	"Home-Intranet-v2-Backend/cmd/handlers/handlertest"
+	"Home-Intranet-v2-Backend/internal/audit/models"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"context"
+	"net/http"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHandler_ListEntries(t *testing.T) {
+	handler := Handler{Repository: repository.NewMemoryRepository()}
+
+	ada := models.Actor{UserID: primitive.NewObjectID(), Username: "ada"}
+	book := primitive.NewObjectID()
+	author := primitive.NewObjectID()
+
+	for _, entry := range []models.AuditEntry{
+		{Actor: ada, Action: models.ActionCreate, Collection: "books", DocumentID: book, Timestamp: time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)},
+		{Actor: models.System, Action: models.ActionUpdate, Collection: "books", DocumentID: book, Timestamp: time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)},
+		{Actor: ada, Action: models.ActionCreate, Collection: "authors", DocumentID: author, Timestamp: time.Date(2026, time.March, 3, 9, 0, 0, 0, time.UTC)},
+		{Actor: ada, Action: models.ActionDelete, Collection: "books", DocumentID: book, Timestamp: time.Date(2026, time.March, 4, 9, 0, 0, 0, time.UTC)},
+	} {
+		if err := handler.Repository.Create(context.Background(), &entry); err != nil {
+			t.Fatalf("Failed to create audit entry: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+		wantDays []int
+	}{
+		{
+			name:     "Newest first",
+			query:    "",
+			wantCode: http.StatusOK,
+			wantDays: []int{4, 3, 2, 1},
+		},
+		{
+			name:     "By user",
+			query:    "user=" + ada.UserID.Hex(),
+			wantCode: http.StatusOK,
+			wantDays: []int{4, 3, 1},
+		},
+		{
+			name:     "By collection and action",
+			query:    "collection=books&action=create",
+			wantCode: http.StatusOK,
+			wantDays: []int{1},
+		},
+		{
+			name:     "By document",
+			query:    "document=" + author.Hex(),
+			wantCode: http.StatusOK,
+			wantDays: []int{3},
+		},
+		{
+			name:     "Time range includes from and excludes to",
+			query:    "from=2026-03-02T09:00:00Z&to=2026-03-04T09:00:00Z",
+			wantCode: http.StatusOK,
+			wantDays: []int{3, 2},
+		},
+		{
+			name:     "Invalid user",
+			query:    "user=ada",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid action",
+			query:    "action=read",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid time",
+			query:    "from=yesterday",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Range ends before it starts",
+			query:    "from=2026-03-04T00:00:00Z&to=2026-03-01T00:00:00Z",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid limit",
+			query:    "limit=ten",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid cursor",
+			query:    "cursor=nonsense",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := handlertest.Serve(t, handler.ListEntries, http.MethodGet, "/v1/audit", "/v1/audit?"+tt.query, "")
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("ListEntries status code = %v, want: %v", rec.Code, tt.wantCode)
+			}
+
+			if tt.wantDays == nil {
+				return
+			}
+
+			var page repository.Page[models.AuditEntry]
+			handlertest.DecodeData(t, rec, &page)
+
+			days := []int{}
+			for _, entry := range page.Items {
+				days = append(days, entry.Timestamp.Day())
+			}
+
+			if !reflect.DeepEqual(days, tt.wantDays) {
+				t.Errorf("ListEntries days = %v, want: %v", days, tt.wantDays)
+			}
+		})
+	}
+}
+
+func TestHandler_ListEntries_paging(t *testing.T) {
+	handler := Handler{Repository: repository.NewMemoryRepository()}
+
+	for day := 1; day <= 5; day++ {
+		entry := models.AuditEntry{
+			Actor:      models.System,
+			Action:     models.ActionUpdate,
+			Collection: "books",
+			DocumentID: primitive.NewObjectID(),
+			Timestamp:  time.Date(2026, time.March, day, 9, 0, 0, 0, time.UTC),
+		}
+
+		if err := handler.Repository.Create(context.Background(), &entry); err != nil {
+			t.Fatalf("Failed to create audit entry: %v", err)
+		}
+	}
+
+	linkPattern := regexp.MustCompile(`<([^>]+)>; rel="next"`)
+
+	target := "/v1/audit?limit=2"
+	var pages [][]int
+
+	for target != "" && len(pages) < 5 {
+		rec := handlertest.Serve(t, handler.ListEntries, http.MethodGet, "/v1/audit", target, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("ListEntries status code = %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		var page repository.Page[models.AuditEntry]
+		handlertest.DecodeData(t, rec, &page)
+
+		days := []int{}
+		for _, entry := range page.Items {
+			days = append(days, entry.Timestamp.Day())
+		}
+		pages = append(pages, days)
+
+		target = ""
+		if match := linkPattern.FindStringSubmatch(rec.Header().Get("Link")); match != nil {
+			target = match[1]
+		}
+	}
+
+	want := [][]int{{5, 4}, {3, 2}, {1}}
+	if !reflect.DeepEqual(pages, want) {
+		t.Errorf("ListEntries pages = %v, want: %v", pages, want)
+	}
+}
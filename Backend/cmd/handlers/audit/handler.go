@@ -0,0 +1,11 @@
+// Package audit contains the controllers for reading the audit log
+package audit
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+)
+
+// Handler is used to allow us to pass our data persistance objects as mocks for better testing
+type Handler struct {
+	Repository repository.Repository
+}
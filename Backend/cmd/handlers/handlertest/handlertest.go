@@ -0,0 +1,62 @@
+// Package handlertest contains the helpers shared by the tests of the handler packages for sending
+// requests to a handler and reading its response.
+package handlertest
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Serve sends a request to a handler mounted on a chi router at the given route pattern, so url
+// parameters are parsed the same way as in the application
+func Serve(t *testing.T, handlerFunc http.HandlerFunc, method string, pattern string, target string, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	router := chi.NewRouter()
+	router.MethodFunc(method, pattern, handlerFunc)
+
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+// DecodeData unmarshals the data field of a response into the given value
+func DecodeData(t *testing.T, recorder *httptest.ResponseRecorder, value interface{}) {
+	t.Helper()
+
+	var body struct {
+		Data json.RawMessage `json:"data"`
+	}
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to parse response body: %v", err)
+	}
+
+	if err := json.Unmarshal(body.Data, value); err != nil {
+		t.Fatalf("Failed to parse response data: %v", err)
+	}
+}
+
+// DecodeProblem unmarshals an error response, checking it is sent as problem details
+func DecodeProblem(t *testing.T, recorder *httptest.ResponseRecorder) response.Problem {
+	t.Helper()
+
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/problem+json" {
+		t.Errorf("Error response Content-Type = %v, want: application/problem+json", contentType)
+	}
+
+	var problem response.Problem
+	if err := json.Unmarshal(recorder.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("Failed to parse problem details: %v", err)
+	}
+
+	return problem
+}
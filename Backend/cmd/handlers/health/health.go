@@ -0,0 +1,130 @@
+// Package health contains the controllers that report whether the service is able to serve requests
+package health
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// The states a dependency, or the service as a whole, can be reported in
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// Check is a dependency the service needs in order to serve requests. Ping should return an error
+// when the dependency can't be used.
+type Check struct {
+	Name string
+	Ping func(ctx context.Context) error
+}
+
+// Handler is used to pass the dependency checks to the health controllers
+type Handler struct {
+	Checks []Check
+
+	// Timeout is how long each check may take before its dependency is reported as unavailable
+	Timeout time.Duration
+}
+
+// Report is the body of a health response
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// CheckResult is the outcome of checking one dependency. The error is kept short so the reason a
+// check failed doesn't leak connection details, the full error is logged.
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Liveness reports that the process is up and able to answer requests. It doesn't look at any
+// dependency, so a database outage doesn't get the service restarted.
+func (handler Handler) Liveness(w http.ResponseWriter, _ *http.Request) {
+	writeReport(w, http.StatusOK, Report{Status: StatusOK})
+	return
+}
+
+// Readiness checks every dependency at the same time and reports each one's status and latency.
+// The response is a 503 when any of them is unavailable, so traffic is kept away until it recovers.
+func (handler Handler) Readiness(w http.ResponseWriter, request *http.Request) {
+	report := Report{
+		Status: StatusOK,
+		Checks: make(map[string]CheckResult, len(handler.Checks)),
+	}
+
+	var mutex sync.Mutex
+	var wait sync.WaitGroup
+
+	for _, check := range handler.Checks {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+
+			result := handler.run(request.Context(), check)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			report.Checks[check.Name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusUnavailable
+			}
+		}()
+	}
+
+	wait.Wait()
+
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	writeReport(w, status, report)
+	return
+}
+
+// run pings one dependency within the check timeout
+func (handler Handler) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, handler.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Ping(ctx)
+	latency := time.Since(start)
+
+	result := CheckResult{
+		Status:    StatusOK,
+		LatencyMS: float64(latency.Microseconds()) / 1000,
+	}
+
+	if err != nil {
+		logger.FromContext(ctx).Error("Readiness check failed", zap.String("check", check.Name), zap.Error(err))
+
+		result.Status = StatusUnavailable
+		result.Error = "unreachable"
+		if errors.Is(err, context.DeadlineExceeded) {
+			result.Error = "timed out"
+		}
+	}
+
+	return result
+}
+
+// writeReport sends a report. Health responses are never cached, as they describe this moment.
+func writeReport(w http.ResponseWriter, status int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
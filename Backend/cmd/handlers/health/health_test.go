@@ -0,0 +1,123 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_Liveness(t *testing.T) {
+	handler := Handler{
+		Checks: []Check{{Name: "database", Ping: func(_ context.Context) error { return errors.New("down") }}},
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.Liveness(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Liveness status code = %v, want: %v", recorder.Code, http.StatusOK)
+	}
+
+	var report Report
+	if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to unmarshal response body: %v", err)
+	}
+
+	if report.Status != StatusOK || report.Checks != nil {
+		t.Errorf("Liveness report = %+v, want ok without checks", report)
+	}
+}
+
+func TestHandler_Readiness(t *testing.T) {
+	healthy := func(_ context.Context) error { return nil }
+	failing := func(_ context.Context) error { return errors.New("connection refused by db:27017") }
+	hanging := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name       string
+		checks     []Check
+		wantCode   int
+		wantStatus string
+		wantChecks map[string]CheckResult
+	}{
+		{
+			name:       "Every dependency is up",
+			checks:     []Check{{Name: "database", Ping: healthy}},
+			wantCode:   http.StatusOK,
+			wantStatus: StatusOK,
+			wantChecks: map[string]CheckResult{
+				"database": {Status: StatusOK},
+			},
+		},
+		{
+			name:       "A failing dependency makes the service unavailable",
+			checks:     []Check{{Name: "database", Ping: failing}, {Name: "cache", Ping: healthy}},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: StatusUnavailable,
+			wantChecks: map[string]CheckResult{
+				"database": {Status: StatusUnavailable, Error: "unreachable"},
+				"cache":    {Status: StatusOK},
+			},
+		},
+		{
+			name:       "A hanging dependency times out",
+			checks:     []Check{{Name: "database", Ping: hanging}},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: StatusUnavailable,
+			wantChecks: map[string]CheckResult{
+				"database": {Status: StatusUnavailable, Error: "timed out"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handler{
+				Checks:  tt.checks,
+				Timeout: 50 * time.Millisecond,
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.Readiness(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if recorder.Code != tt.wantCode {
+				t.Errorf("Readiness status code = %v, want: %v", recorder.Code, tt.wantCode)
+			}
+
+			if recorder.Header().Get("Cache-Control") != "no-store" {
+				t.Errorf("Readiness Cache-Control = %v, want: no-store", recorder.Header().Get("Cache-Control"))
+			}
+
+			var report Report
+			if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil {
+				t.Fatalf("Failed to unmarshal response body: %v", err)
+			}
+
+			if report.Status != tt.wantStatus {
+				t.Errorf("Readiness status = %v, want: %v", report.Status, tt.wantStatus)
+			}
+
+			if len(report.Checks) != len(tt.wantChecks) {
+				t.Fatalf("Readiness checks = %+v, want: %+v", report.Checks, tt.wantChecks)
+			}
+
+			for name, want := range tt.wantChecks {
+				got := report.Checks[name]
+				if got.Status != want.Status || got.Error != want.Error {
+					t.Errorf("Readiness check %s = %+v, want: %+v", name, got, want)
+				}
+
+				if got.LatencyMS < 0 {
+					t.Errorf("Readiness check %s latency = %v, want it measured", name, got.LatencyMS)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,437 @@
+// Package library contains all the controllers for the library functionality
+package library
+
+import (
+	"Home-Intranet-v2-Backend/internal/library/models"
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// errUnknownAuthor is returned when a book references an author id that doesn't exist
+var errUnknownAuthor = errors.New("unknown author")
+
+// authorFilterFields are the author fields that can be used in filter[...] query parameters
+var authorFilterFields = repository.FilterFields{
+	"_id":         repository.ObjectIDField,
+	"first_name":  repository.StringField,
+	"middle_name": repository.StringField,
+	"last_name":   repository.StringField,
+	"created_at":  repository.TimeField,
+	"updated_at":  repository.TimeField,
+}
+
+// ListAuthors returns a list of authors, optionally narrowed down by the name and filter[...] query
+// parameters
+func (handler Handler) ListAuthors(w http.ResponseWriter, request *http.Request) {
+	values := request.URL.Query()
+
+	cursor, limit, err := repository.ParsePaging(values)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Error parsing paging parameters", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	filter := bson.D{}
+	if terms := strings.Fields(values.Get("name")); len(terms) > 0 {
+		filter = append(filter, everyTerm(terms, "first_name", "middle_name", "last_name"))
+	}
+
+	filter, err = withQueryFilter(filter, values, authorFilterFields)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Error building author filter", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	page, err := repository.ListPage[models.Author](request.Context(), handler.Repository, filter, repository.Sort{repository.Asc("last_name")}, cursor, limit)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		response.Error(w, err)
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue retriving authors", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	response.Page(w, request, page)
+	return
+}
+
+// CreateAuthor is the handler for adding a new author to the library
+func (handler Handler) CreateAuthor(w http.ResponseWriter, request *http.Request) {
+	byteData, err := io.ReadAll(request.Body)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue reading request body", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	var author models.Author
+	err = json.Unmarshal(byteData, &author)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue unmarshalling json", zap.Error(err))
+		response.InvalidJSON(w, err)
+		return
+	}
+
+	author.Model = repository.Model{}
+
+	if err = author.Validate(); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	var existing models.Author
+	err = handler.Repository.Read(request.Context(), &existing, authorNameFilter(author))
+	if err == nil {
+		response.Conflict(w, fmt.Sprintf("author already exists with id %s", existing.ID.Hex()))
+		return
+	}
+
+	if !handler.Repository.IsNotFoundError(err) {
+		logger.FromContext(request.Context()).Error("Issue Finding Document", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	if err = handler.Repository.Create(request.Context(), &author); err != nil {
+		logger.FromContext(request.Context()).Error("Issue creating author", zap.Error(err))
+		response.Error(w, err)
+		return
+	}
+
+	response.SuccessResponse(w, &author)
+	return
+}
+
+// GetAuthor returns a single author by its id
+func (handler Handler) GetAuthor(w http.ResponseWriter, request *http.Request) {
+	id, err := parseID(request)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue parsing author id", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	var author models.Author
+	err = handler.Repository.Read(request.Context(), &author, idFilter(id))
+	if handler.Repository.IsNotFoundError(err) {
+		response.NotFound(w, fmt.Sprintf("author %s not found", id.Hex()))
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue retrieving author", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	response.SuccessResponse(w, &author)
+	return
+}
+
+// UpdateAuthor is the handler for replacing every field of an author
+func (handler Handler) UpdateAuthor(w http.ResponseWriter, request *http.Request) {
+	handler.updateAuthor(w, request, false)
+}
+
+// PatchAuthor is the handler for changing only the fields of an author that are sent in the request
+func (handler Handler) PatchAuthor(w http.ResponseWriter, request *http.Request) {
+	handler.updateAuthor(w, request, true)
+}
+
+// updateAuthor applies the request body to a stored author and copies the new name into every
+// book that references the author
+func (handler Handler) updateAuthor(w http.ResponseWriter, request *http.Request, partial bool) {
+	id, err := parseID(request)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue parsing author id", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	var existing models.Author
+	err = handler.Repository.Read(request.Context(), &existing, idFilter(id))
+	if handler.Repository.IsNotFoundError(err) {
+		response.NotFound(w, fmt.Sprintf("author %s not found", id.Hex()))
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue retrieving author", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	byteData, err := io.ReadAll(request.Body)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue reading request body", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	var author models.Author
+	if partial {
+		author = existing
+	}
+
+	err = json.Unmarshal(byteData, &author)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue unmarshalling json", zap.Error(err))
+		response.InvalidJSON(w, err)
+		return
+	}
+
+	// The id and creation time always come from the stored document
+	author.ID = existing.ID
+	author.CreatedAt = existing.CreatedAt
+
+	if err = author.Validate(); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	// Renaming an author to the exact name of another one would make the two duplicates
+	var named models.Author
+	err = handler.Repository.Read(request.Context(), &named, authorNameFilter(author))
+	if err == nil && named.ID != id {
+		response.Conflict(w, fmt.Sprintf("author already exists with id %s", named.ID.Hex()))
+		return
+	}
+
+	if err != nil && !handler.Repository.IsNotFoundError(err) {
+		logger.FromContext(request.Context()).Error("Issue Finding Document", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	err = handler.Repository.Update(request.Context(), &author, idFilter(id))
+	if handler.Repository.IsNotFoundError(err) {
+		response.NotFound(w, fmt.Sprintf("author %s not found", id.Hex()))
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue updating author", zap.Error(err))
+		response.Error(w, err)
+		return
+	}
+
+	if _, err = handler.replaceAuthorInBooks(request.Context(), id, author); err != nil {
+		logger.FromContext(request.Context()).Error("Issue updating author's books", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	response.SuccessResponse(w, &author)
+	return
+}
+
+// DeleteAuthor is the handler for removing an author that no book references any more
+func (handler Handler) DeleteAuthor(w http.ResponseWriter, request *http.Request) {
+	id, err := parseID(request)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue parsing author id", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	var book models.Book
+	err = handler.Repository.Read(request.Context(), &book, authorBooksFilter(id))
+	if err == nil {
+		response.Conflict(w, fmt.Sprintf("author %s is still referenced by book %s", id.Hex(), book.ID.Hex()))
+		return
+	}
+
+	if !handler.Repository.IsNotFoundError(err) {
+		logger.FromContext(request.Context()).Error("Issue retrieving books", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	err = handler.Repository.Delete(request.Context(), &models.Author{}, idFilter(id))
+	if handler.Repository.IsNotFoundError(err) {
+		response.NotFound(w, fmt.Sprintf("author %s not found", id.Hex()))
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue deleting author", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	response.SuccessResponse(w, id.Hex())
+	return
+}
+
+// ListAuthorBooks returns the books written by an author
+func (handler Handler) ListAuthorBooks(w http.ResponseWriter, request *http.Request) {
+	id, err := parseID(request)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue parsing author id", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	cursor, limit, err := repository.ParsePaging(request.URL.Query())
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Error parsing paging parameters", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	var author models.Author
+	err = handler.Repository.Read(request.Context(), &author, idFilter(id))
+	if handler.Repository.IsNotFoundError(err) {
+		response.NotFound(w, fmt.Sprintf("author %s not found", id.Hex()))
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue retrieving author", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	filter, err := withQueryFilter(authorBooksFilter(id), request.URL.Query(), bookFilterFields)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Error building book filter", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	page, err := repository.ListPage[models.Book](request.Context(), handler.Repository, filter, repository.Sort{repository.Asc("title")}, cursor, limit)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		response.Error(w, err)
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue retriving books", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	response.Page(w, request, page)
+	return
+}
+
+// resolveAuthors links the authors of a book to their author documents. Authors sent with an id
+// must already exist, and authors sent by name are matched by name or created. The returned slice
+// holds the embedded copies to store on the book.
+func (handler Handler) resolveAuthors(ctx context.Context, authors []models.Author) ([]models.Author, error) {
+	resolved := make([]models.Author, 0, len(authors))
+
+	for _, author := range authors {
+		if !author.ID.IsZero() {
+			var stored models.Author
+			err := handler.Repository.Read(ctx, &stored, idFilter(author.ID))
+			if handler.Repository.IsNotFoundError(err) {
+				return nil, fmt.Errorf("%w %s", errUnknownAuthor, author.ID.Hex())
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("issue finding author: %w", err)
+			}
+
+			resolved = append(resolved, stored.Embedded())
+			continue
+		}
+
+		err := handler.Repository.Read(ctx, &author, authorNameFilter(author))
+		if err != nil && !handler.Repository.IsNotFoundError(err) {
+			return nil, fmt.Errorf("issue finding author: %w", err)
+		}
+
+		if author.ID.IsZero() {
+			if err = handler.Repository.Create(ctx, &author); err != nil {
+				return nil, fmt.Errorf("issue creating author: %w", err)
+			}
+		}
+
+		resolved = append(resolved, author.Embedded())
+	}
+
+	return resolved, nil
+}
+
+// replaceAuthorInBooks swaps the embedded copy of an author in every book that references it for
+// the given replacement, returning how many books were changed. A book that already lists the
+// replacement keeps a single copy of it.
+func (handler Handler) replaceAuthorInBooks(ctx context.Context, id primitive.ObjectID, replacement models.Author) (int, error) {
+	books, err := repository.List[models.Book](ctx, handler.Repository, authorBooksFilter(id), repository.Sort{}, 0, 0)
+	if err != nil {
+		return 0, fmt.Errorf("issue retrieving books: %w", err)
+	}
+
+	changed := 0
+	for _, book := range books {
+		authors := make([]models.Author, 0, len(book.Authors))
+		seen := map[primitive.ObjectID]bool{}
+
+		for _, author := range book.Authors {
+			if author.ID == id {
+				author = replacement.Embedded()
+			}
+
+			if !author.ID.IsZero() && seen[author.ID] {
+				continue
+			}
+
+			seen[author.ID] = true
+			authors = append(authors, author)
+		}
+
+		// Only the authors are set, and only while the book still lists the author, so a checkout or
+		// edit made since the books were listed isn't reverted
+		var updated models.Book
+		err = handler.Repository.UpdateFields(ctx, &updated, bson.D{
+			{Key: "_id", Value: book.ID},
+			{Key: "authors._id", Value: id},
+		}, bson.D{
+			{Key: "authors", Value: authors},
+		})
+		if handler.Repository.IsNotFoundError(err) {
+			continue
+		}
+
+		if err != nil {
+			return 0, fmt.Errorf("issue updating book %s: %w", book.ID.Hex(), err)
+		}
+
+		changed++
+	}
+
+	return changed, nil
+}
+
+// authorNameFilter builds the filter for finding an author with exactly the same name
+func authorNameFilter(author models.Author) bson.D {
+	return bson.D{
+		{Key: "first_name", Value: author.FirstName},
+		{Key: "middle_name", Value: author.MiddleName},
+		{Key: "last_name", Value: author.LastName},
+		{Key: "suffix", Value: author.Suffix},
+	}
+}
+
+// authorBooksFilter builds the filter for finding the books that reference an author
+func authorBooksFilter(id primitive.ObjectID) bson.D {
+	return bson.D{{Key: "authors._id", Value: id}}
+}
@@ -0,0 +1,209 @@
+package library
+
+import (
+	"Home-Intranet-v2-Backend/cmd/handlers/handlertest"
+	"Home-Intranet-v2-Backend/internal/library/models"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestHandler_CreateAndListAuthors(t *testing.T) {
+	handler := newTestHandler()
+
+	steps := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{name: "Create", body: `{"first_name": "Ursula", "middle_name": "K.", "last_name": "Le Guin"}`, wantCode: http.StatusOK},
+		{name: "Create another", body: `{"first_name": "Terry", "last_name": "Pratchett"}`, wantCode: http.StatusOK},
+		{name: "Create with the same name", body: `{"first_name": "Terry", "last_name": "Pratchett"}`, wantCode: http.StatusConflict},
+		{name: "Create without a name", body: `{"first_name": ""}`, wantCode: http.StatusUnprocessableEntity},
+		{name: "Malformed json", body: `{"first_name": `, wantCode: http.StatusBadRequest},
+	}
+
+	for _, step := range steps {
+		rec := handlertest.Serve(t, handler.CreateAuthor, http.MethodPost, "/v1/authors", "/v1/authors", step.body)
+
+		if rec.Code != step.wantCode {
+			t.Fatalf("%s status code = %v, want: %v, body: %s", step.name, rec.Code, step.wantCode, rec.Body.String())
+		}
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+		wantLast []string
+	}{
+		{
+			name:     "Sorted by last name",
+			query:    "",
+			wantCode: http.StatusOK,
+			wantLast: []string{"Le Guin", "Pratchett"},
+		},
+		{
+			name:     "Every name term has to match",
+			query:    "name=ursula+guin",
+			wantCode: http.StatusOK,
+			wantLast: []string{"Le Guin"},
+		},
+		{
+			name:     "Terms matching different authors",
+			query:    "name=ursula+pratchett",
+			wantCode: http.StatusOK,
+			wantLast: []string{},
+		},
+		{
+			name:     "Invalid filter",
+			query:    "filter[created_at][gte]=soon",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := handlertest.Serve(t, handler.ListAuthors, http.MethodGet, "/v1/authors", "/v1/authors?"+tt.query, "")
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("ListAuthors status code = %v, want: %v", rec.Code, tt.wantCode)
+			}
+
+			if tt.wantLast == nil {
+				return
+			}
+
+			var page repository.Page[models.Author]
+			handlertest.DecodeData(t, rec, &page)
+
+			names := []string{}
+			for _, author := range page.Items {
+				names = append(names, author.LastName)
+			}
+
+			if !reflect.DeepEqual(names, tt.wantLast) {
+				t.Errorf("ListAuthors last names = %v, want: %v", names, tt.wantLast)
+			}
+		})
+	}
+}
+
+func TestHandler_UpdateAuthor(t *testing.T) {
+	handler := newTestHandler()
+
+	rec := handlertest.Serve(t, handler.CreateBook, http.MethodPost, "/v1/books", "/v1/books", `{"title": "A Wizard of Earthsea", "authors": [{"first_name": "Ursula", "last_name": "LeGuin"}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("CreateBook status code = %v, want: %v", rec.Code, http.StatusOK)
+	}
+
+	var book models.Book
+	handlertest.DecodeData(t, rec, &book)
+	authorID := book.Authors[0].ID.Hex()
+
+	rec = handlertest.Serve(t, handler.PatchAuthor, http.MethodPatch, "/v1/authors/{id}", "/v1/authors/"+authorID, `{"middle_name": "K.", "last_name": "Le Guin"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PatchAuthor status code = %v, want: %v, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	rec = handlertest.Serve(t, handler.UpdateAuthor, http.MethodPut, "/v1/authors/{id}", "/v1/authors/"+authorID, `{"first_name": "Ursula", "middle_name": "K.", "last_name": "Le Guin"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("UpdateAuthor keeping its own name status code = %v, want: %v, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	rec = handlertest.Serve(t, handler.CreateAuthor, http.MethodPost, "/v1/authors", "/v1/authors", `{"first_name": "Terry", "last_name": "Pratchett"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("CreateAuthor status code = %v, want: %v", rec.Code, http.StatusOK)
+	}
+
+	rec = handlertest.Serve(t, handler.PatchAuthor, http.MethodPatch, "/v1/authors/{id}", "/v1/authors/"+authorID, `{"first_name": "Terry", "middle_name": "", "last_name": "Pratchett"}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("PatchAuthor to another author's name status code = %v, want: %v", rec.Code, http.StatusConflict)
+	}
+
+	rec = handlertest.Serve(t, handler.PatchAuthor, http.MethodPatch, "/v1/authors/{id}", "/v1/authors/"+authorID, `{"first_name": "", "last_name": ""}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("PatchAuthor without a name status code = %v, want: %v", rec.Code, http.StatusUnprocessableEntity)
+	}
+
+	rec = handlertest.Serve(t, handler.ListAuthorBooks, http.MethodGet, "/v1/authors/{id}/books", "/v1/authors/"+authorID+"/books", "")
+
+	var page repository.Page[models.Book]
+	handlertest.DecodeData(t, rec, &page)
+	books := page.Items
+
+	if len(books) != 1 {
+		t.Fatalf("ListAuthorBooks returned %d books, want: 1", len(books))
+	}
+
+	if got := books[0].Authors[0]; got.MiddleName != "K." || got.LastName != "Le Guin" || got.FirstName != "Ursula" {
+		t.Errorf("Renamed author in book = %+v, want Ursula K. Le Guin", got)
+	}
+
+	if strings.Contains(rec.Body.String(), "created_at\":\"0001") {
+		t.Errorf("ListAuthorBooks body = %s, want no zero timestamps on embedded authors", rec.Body.String())
+	}
+
+	if rec = handlertest.Serve(t, handler.DeleteAuthor, http.MethodDelete, "/v1/authors/{id}", "/v1/authors/"+authorID, ""); rec.Code != http.StatusConflict {
+		t.Errorf("DeleteAuthor with books status code = %v, want: %v", rec.Code, http.StatusConflict)
+	}
+}
+
+// checkoutAfterList checks out every book as soon as the books have been listed, standing in for a
+// checkout that lands while an author is being renamed
+type checkoutAfterList struct {
+	repository.Repository
+}
+
+func (repo checkoutAfterList) List(ctx context.Context, results interface{}, filter interface{}, sort repository.Sort, offset int64, limit int64) error {
+	if err := repo.Repository.List(ctx, results, filter, sort, offset, limit); err != nil {
+		return err
+	}
+
+	books, ok := results.(*[]models.Book)
+	if !ok {
+		return nil
+	}
+
+	for _, book := range *books {
+		var updated models.Book
+		if err := repo.Repository.UpdateFields(ctx, &updated, idFilter(book.ID), bson.D{{Key: "checked_out", Value: true}, {Key: "checked_out_by", Value: "Sam"}}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestHandler_UpdateAuthor_concurrentCheckout(t *testing.T) {
+	handler := newTestHandler()
+
+	rec := handlertest.Serve(t, handler.CreateBook, http.MethodPost, "/v1/books", "/v1/books", `{"title": "A Wizard of Earthsea", "authors": [{"first_name": "Ursula", "last_name": "LeGuin"}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("CreateBook status code = %v, want: %v", rec.Code, http.StatusOK)
+	}
+
+	var book models.Book
+	handlertest.DecodeData(t, rec, &book)
+
+	handler.Repository = checkoutAfterList{Repository: handler.Repository}
+
+	rec = handlertest.Serve(t, handler.PatchAuthor, http.MethodPatch, "/v1/authors/{id}", "/v1/authors/"+book.Authors[0].ID.Hex(), `{"last_name": "Le Guin"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PatchAuthor status code = %v, want: %v, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	rec = handlertest.Serve(t, handler.GetBook, http.MethodGet, "/v1/books/{id}", "/v1/books/"+book.ID.Hex(), "")
+
+	var got models.Book
+	handlertest.DecodeData(t, rec, &got)
+
+	if got.Authors[0].LastName != "Le Guin" || !got.CheckedOut || got.CheckedOutBy != "Sam" {
+		t.Errorf("Book after renaming its author = %+v, want the new name and the checkout kept", got)
+	}
+}
@@ -0,0 +1,267 @@
+// Package library contains all the controllers for the library functionality
+package library
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth"
+	authmodels "Home-Intranet-v2-Backend/internal/auth/models"
+	"Home-Intranet-v2-Backend/internal/library/models"
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// checkoutRequest is the body accepted when checking out a book. The borrower defaults to the
+// logged in user, and the due date defaults to the configured loan period. Checking out to anyone
+// else, either a user account by id or someone without an account by name, needs the
+// PermissionManageLoans permission.
+type checkoutRequest struct {
+	CheckedOutBy   string             `json:"checked_out_by"`
+	CheckedOutByID primitive.ObjectID `json:"checked_out_by_id"`
+	DueDate        time.Time          `json:"due_date"`
+}
+
+// CheckoutBook is the handler for checking a book out of the library
+func (handler Handler) CheckoutBook(w http.ResponseWriter, request *http.Request) {
+	id, err := parseID(request)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue parsing book id", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	byteData, err := io.ReadAll(request.Body)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue reading request body", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	var checkout checkoutRequest
+	err = json.Unmarshal(byteData, &checkout)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue unmarshalling json", zap.Error(err))
+		response.InvalidJSON(w, err)
+		return
+	}
+
+	// The route requires PermissionBorrow, so there is always a logged in user
+	user, _ := auth.UserFromContext(request.Context())
+
+	checkout.CheckedOutBy = strings.TrimSpace(checkout.CheckedOutBy)
+
+	toSelf := checkout.CheckedOutByID.IsZero() && checkout.CheckedOutBy == ""
+	if !checkout.CheckedOutByID.IsZero() && checkout.CheckedOutByID == user.ID {
+		toSelf = true
+	}
+	if !toSelf && !auth.Can(request.Context(), auth.PermissionManageLoans) {
+		response.Forbidden(w, fmt.Sprintf("checking a book out to someone else needs the %s permission", auth.PermissionManageLoans))
+		return
+	}
+
+	switch {
+	case toSelf:
+		checkout.CheckedOutBy = user.Name()
+		checkout.CheckedOutByID = user.ID
+	case !checkout.CheckedOutByID.IsZero():
+		borrower, err := repository.Get[authmodels.User](request.Context(), handler.Repository, idFilter(checkout.CheckedOutByID))
+		if handler.Repository.IsNotFoundError(err) {
+			response.BadRequest(w, fmt.Sprintf("user %s not found", checkout.CheckedOutByID.Hex()))
+			return
+		}
+
+		if err != nil {
+			logger.FromContext(request.Context()).Error("Issue retrieving borrower", zap.Error(err))
+			response.InternalServerError(w, err)
+			return
+		}
+
+		checkout.CheckedOutBy = borrower.Name()
+	}
+
+	checkedOutTime := time.Now().UTC()
+
+	if checkout.DueDate.IsZero() {
+		checkout.DueDate = checkedOutTime.Add(handler.LoanPeriod)
+	}
+
+	if !checkout.DueDate.After(checkedOutTime) {
+		response.BadRequest(w, "due_date must be in the future")
+		return
+	}
+
+	// Only a book that is currently on the shelf matches, so two concurrent checkouts can't both succeed
+	var book models.Book
+	err = handler.Repository.UpdateFields(request.Context(), &book, bson.D{
+		{Key: "_id", Value: id},
+		{Key: "checked_out", Value: false},
+	}, bson.D{
+		{Key: "checked_out", Value: true},
+		{Key: "checked_out_by", Value: checkout.CheckedOutBy},
+		{Key: "checked_out_by_id", Value: checkout.CheckedOutByID},
+		{Key: "checked_out_time", Value: checkedOutTime},
+		{Key: "due_date", Value: checkout.DueDate.UTC()},
+	})
+	if handler.Repository.IsNotFoundError(err) {
+		handler.explainCirculationConflict(w, request, id)
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue checking out book", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	if err = handler.openLoan(request.Context(), book); err != nil {
+		logger.FromContext(request.Context()).Error("Issue recording loan", zap.Error(err))
+		handler.undoCheckout(request.Context(), book)
+		response.InternalServerError(w, err)
+		return
+	}
+
+	response.SuccessResponse(w, &book)
+	return
+}
+
+// ReturnBook is the handler for returning a checked out book to the library
+func (handler Handler) ReturnBook(w http.ResponseWriter, request *http.Request) {
+	id, err := parseID(request)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue parsing book id", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	// Read the book first to know which loan is being closed, and only return it if it is still on that loan
+	var before models.Book
+	err = handler.Repository.Read(request.Context(), &before, idFilter(id))
+	if handler.Repository.IsNotFoundError(err) {
+		response.NotFound(w, fmt.Sprintf("book %s not found", id.Hex()))
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue retrieving book", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	// Only the user a book is checked out to can return it themselves. Books checked out to someone
+	// without an account have no user, so they are returned by someone who manages loans.
+	user, _ := auth.UserFromContext(request.Context())
+	if before.CheckedOut && (before.CheckedOutByID.IsZero() || before.CheckedOutByID != user.ID) && !auth.Can(request.Context(), auth.PermissionManageLoans) {
+		response.Forbidden(w, fmt.Sprintf("returning a book checked out by someone else needs the %s permission", auth.PermissionManageLoans))
+		return
+	}
+
+	returnedTime := time.Now().UTC()
+
+	var book models.Book
+	err = handler.Repository.UpdateFields(request.Context(), &book, bson.D{
+		{Key: "_id", Value: id},
+		{Key: "checked_out", Value: true},
+		{Key: "checked_out_by", Value: before.CheckedOutBy},
+		{Key: "checked_out_time", Value: before.CheckedOutTime},
+	}, bson.D{
+		{Key: "checked_out", Value: false},
+		{Key: "checked_out_by", Value: ""},
+		{Key: "checked_out_by_id", Value: primitive.NilObjectID},
+		{Key: "checked_out_time", Value: time.Time{}},
+		{Key: "due_date", Value: time.Time{}},
+	})
+	if handler.Repository.IsNotFoundError(err) {
+		handler.explainCirculationConflict(w, request, id)
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue returning book", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	if err = handler.closeLoan(request.Context(), before, returnedTime); err != nil {
+		logger.FromContext(request.Context()).Error("Issue recording loan", zap.Error(err))
+		handler.undoReturn(request.Context(), before)
+		response.InternalServerError(w, err)
+		return
+	}
+
+	response.SuccessResponse(w, &book)
+	return
+}
+
+// undoCheckout puts a book back on the shelf when its loan couldn't be recorded, so a book is never
+// left checked out without a loan. Only the checkout that was just made is undone.
+func (handler Handler) undoCheckout(ctx context.Context, book models.Book) {
+	var undone models.Book
+	err := handler.Repository.UpdateFields(context.WithoutCancel(ctx), &undone, bson.D{
+		{Key: "_id", Value: book.ID},
+		{Key: "checked_out", Value: true},
+		{Key: "checked_out_by", Value: book.CheckedOutBy},
+		{Key: "checked_out_time", Value: book.CheckedOutTime},
+	}, bson.D{
+		{Key: "checked_out", Value: false},
+		{Key: "checked_out_by", Value: ""},
+		{Key: "checked_out_by_id", Value: primitive.NilObjectID},
+		{Key: "checked_out_time", Value: time.Time{}},
+		{Key: "due_date", Value: time.Time{}},
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("Issue undoing checkout", zap.Error(err))
+	}
+}
+
+// undoReturn checks a book back out to its borrower when the end of its loan couldn't be recorded.
+// The book is passed as it was before the return.
+func (handler Handler) undoReturn(ctx context.Context, before models.Book) {
+	var undone models.Book
+	err := handler.Repository.UpdateFields(context.WithoutCancel(ctx), &undone, bson.D{
+		{Key: "_id", Value: before.ID},
+		{Key: "checked_out", Value: false},
+	}, bson.D{
+		{Key: "checked_out", Value: true},
+		{Key: "checked_out_by", Value: before.CheckedOutBy},
+		{Key: "checked_out_by_id", Value: before.CheckedOutByID},
+		{Key: "checked_out_time", Value: before.CheckedOutTime},
+		{Key: "due_date", Value: before.DueDate},
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("Issue undoing return", zap.Error(err))
+	}
+}
+
+// explainCirculationConflict is used when a checkout or return guard didn't match. It works out
+// whether the book doesn't exist or is just in the wrong state for the requested action.
+func (handler Handler) explainCirculationConflict(w http.ResponseWriter, request *http.Request, id primitive.ObjectID) {
+	var book models.Book
+	err := handler.Repository.Read(request.Context(), &book, idFilter(id))
+	if handler.Repository.IsNotFoundError(err) {
+		response.NotFound(w, fmt.Sprintf("book %s not found", id.Hex()))
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue retrieving book", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	if book.CheckedOut {
+		response.Conflict(w, fmt.Sprintf("book %s is already checked out by %s", id.Hex(), book.CheckedOutBy))
+		return
+	}
+
+	response.Conflict(w, fmt.Sprintf("book %s is not checked out", id.Hex()))
+}
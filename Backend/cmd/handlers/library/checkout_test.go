@@ -0,0 +1,316 @@
+package library
+
+import (
+	"Home-Intranet-v2-Backend/cmd/handlers/handlertest"
+	"Home-Intranet-v2-Backend/internal/auth"
+	authmodels "Home-Intranet-v2-Backend/internal/auth/models"
+	"Home-Intranet-v2-Backend/internal/library/models"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// asUser runs a handler as if the request had been made by a logged in user
+func asUser(user authmodels.User, action http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, request *http.Request) {
+		action(w, request.WithContext(auth.WithUser(request.Context(), user)))
+	}
+}
+
+func TestHandler_CheckoutAndReturnBook(t *testing.T) {
+	handler := newTestHandler()
+	book := createTestBook(t, handler, models.Book{Title: "The Hobbit"})
+	admin := authmodels.User{Model: repository.Model{ID: primitive.NewObjectID()}, Username: "ada", Role: authmodels.RoleAdmin}
+
+	checkoutTarget := "/v1/books/" + book.ID.Hex() + "/checkout"
+	returnTarget := "/v1/books/" + book.ID.Hex() + "/return"
+
+	steps := []struct {
+		name     string
+		action   http.HandlerFunc
+		pattern  string
+		target   string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "Return a book that is on the shelf",
+			action:   handler.ReturnBook,
+			pattern:  "/v1/books/{id}/return",
+			target:   returnTarget,
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "Checkout",
+			action:   handler.CheckoutBook,
+			pattern:  "/v1/books/{id}/checkout",
+			target:   checkoutTarget,
+			body:     `{"checked_out_by": "Sam"}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Checkout a book that is already out",
+			action:   handler.CheckoutBook,
+			pattern:  "/v1/books/{id}/checkout",
+			target:   checkoutTarget,
+			body:     `{"checked_out_by": "Alex"}`,
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "Return",
+			action:   handler.ReturnBook,
+			pattern:  "/v1/books/{id}/return",
+			target:   returnTarget,
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, step := range steps {
+		rec := handlertest.Serve(t, asUser(admin, step.action), http.MethodPost, step.pattern, step.target, step.body)
+
+		if rec.Code != step.wantCode {
+			t.Fatalf("%s status code = %v, want: %v, body: %s", step.name, rec.Code, step.wantCode, rec.Body.String())
+		}
+	}
+
+	rec := handlertest.Serve(t, handler.GetBook, http.MethodGet, "/v1/books/{id}", "/v1/books/"+book.ID.Hex(), "")
+
+	var got models.Book
+	handlertest.DecodeData(t, rec, &got)
+
+	if got.CheckedOut || got.CheckedOutBy != "" || !got.CheckedOutTime.IsZero() || !got.DueDate.IsZero() {
+		t.Errorf("ReturnBook left circulation fields set: %+v", got)
+	}
+
+	rec = handlertest.Serve(t, handler.ListBookLoans, http.MethodGet, "/v1/books/{id}/loans", "/v1/books/"+book.ID.Hex()+"/loans", "")
+
+	var page repository.Page[models.Loan]
+	handlertest.DecodeData(t, rec, &page)
+	loans := page.Items
+
+	if len(loans) != 1 || loans[0].Borrower != "Sam" || !loans[0].BorrowerID.IsZero() || !loans[0].Returned || loans[0].DueDate.IsZero() {
+		t.Errorf("ListBookLoans loans = %+v, want one returned loan by Sam", loans)
+	}
+}
+
+func TestHandler_CheckoutAndReturnBook_loggedInUser(t *testing.T) {
+	member := authmodels.User{Model: repository.Model{ID: primitive.NewObjectID()}, Username: "sam", DisplayName: "Sam", Role: authmodels.RoleMember}
+	admin := authmodels.User{Model: repository.Model{ID: primitive.NewObjectID()}, Username: "ada", Role: authmodels.RoleAdmin}
+	alex := authmodels.User{Model: repository.Model{ID: primitive.NewObjectID()}, Username: "alex", DisplayName: "Alex", Role: authmodels.RoleMember}
+
+	tests := []struct {
+		name           string
+		borrower       string
+		borrowerID     primitive.ObjectID
+		user           authmodels.User
+		body           string
+		wantCode       int
+		wantBorrower   string
+		wantBorrowerID primitive.ObjectID
+	}{
+		{
+			name:           "Borrower defaults to the user",
+			user:           member,
+			body:           `{}`,
+			wantCode:       http.StatusOK,
+			wantBorrower:   "Sam",
+			wantBorrowerID: member.ID,
+		},
+		{
+			name:           "Checkout to yourself by id",
+			user:           member,
+			body:           `{"checked_out_by_id": "` + member.ID.Hex() + `"}`,
+			wantCode:       http.StatusOK,
+			wantBorrower:   "Sam",
+			wantBorrowerID: member.ID,
+		},
+		{
+			name:     "Member can't name a borrower, even with their own name",
+			user:     member,
+			body:     `{"checked_out_by": "Sam"}`,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "Member can't check out to another user",
+			user:     member,
+			body:     `{"checked_out_by_id": "` + alex.ID.Hex() + `"}`,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:         "Admin can check out to someone without an account",
+			user:         admin,
+			body:         `{"checked_out_by": "Robin"}`,
+			wantCode:     http.StatusOK,
+			wantBorrower: "Robin",
+		},
+		{
+			name:           "Admin can check out to another user",
+			user:           admin,
+			body:           `{"checked_out_by_id": "` + alex.ID.Hex() + `"}`,
+			wantCode:       http.StatusOK,
+			wantBorrower:   "Alex",
+			wantBorrowerID: alex.ID,
+		},
+		{
+			name:     "Admin can't check out to an unknown user",
+			user:     admin,
+			body:     `{"checked_out_by_id": "` + primitive.NewObjectID().Hex() + `"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Member can't return another user's book",
+			borrower:   "Alex",
+			borrowerID: alex.ID,
+			user:       member,
+			wantCode:   http.StatusForbidden,
+		},
+		{
+			name:       "Member can't return another user's book checked out under their name",
+			borrower:   "Sam",
+			borrowerID: alex.ID,
+			user:       member,
+			wantCode:   http.StatusForbidden,
+		},
+		{
+			name:     "Member can't return a book checked out to someone without an account",
+			borrower: "Sam",
+			user:     member,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:       "Member can return their own book",
+			borrower:   "Sam",
+			borrowerID: member.ID,
+			user:       member,
+			wantCode:   http.StatusOK,
+		},
+		{
+			name:       "Admin can return another user's book",
+			borrower:   "Alex",
+			borrowerID: alex.ID,
+			user:       admin,
+			wantCode:   http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandler()
+			if err := handler.Repository.Create(context.Background(), &alex); err != nil {
+				t.Fatalf("Failed to create user: %v", err)
+			}
+
+			book := models.Book{Title: "The Hobbit"}
+			if tt.borrower != "" {
+				book.CheckedOut = true
+				book.CheckedOutBy = tt.borrower
+				book.CheckedOutByID = tt.borrowerID
+				book.CheckedOutTime = time.Now().UTC().Add(-time.Hour)
+			}
+			book = createTestBook(t, handler, book)
+
+			action, pattern := asUser(tt.user, handler.CheckoutBook), "/v1/books/{id}/checkout"
+			if tt.borrower != "" {
+				action, pattern = asUser(tt.user, handler.ReturnBook), "/v1/books/{id}/return"
+			}
+
+			target := strings.Replace(pattern, "{id}", book.ID.Hex(), 1)
+			rec := handlertest.Serve(t, action, http.MethodPost, pattern, target, tt.body)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("%s status code = %v, want: %v, body: %s", target, rec.Code, tt.wantCode, rec.Body.String())
+			}
+
+			if tt.wantBorrower == "" {
+				return
+			}
+
+			var got models.Book
+			handlertest.DecodeData(t, rec, &got)
+
+			if got.CheckedOutBy != tt.wantBorrower || got.CheckedOutByID != tt.wantBorrowerID {
+				t.Errorf("CheckoutBook borrower = %q %v, want: %q %v", got.CheckedOutBy, got.CheckedOutByID.Hex(), tt.wantBorrower, tt.wantBorrowerID.Hex())
+			}
+
+			loans, err := repository.List[models.Loan](context.Background(), handler.Repository, bson.D{{Key: "book_id", Value: book.ID}}, repository.Sort{}, 0, 0)
+			if err != nil {
+				t.Fatalf("Failed to list loans: %v", err)
+			}
+
+			if len(loans) != 1 || loans[0].Borrower != tt.wantBorrower || loans[0].BorrowerID != tt.wantBorrowerID {
+				t.Errorf("CheckoutBook loans = %+v, want one by %q %v", loans, tt.wantBorrower, tt.wantBorrowerID.Hex())
+			}
+		})
+	}
+}
+
+// failingLoans fails every write of a loan, standing in for the loan history being unavailable
+type failingLoans struct {
+	repository.Repository
+}
+
+func (repo failingLoans) Create(ctx context.Context, model interface{}) error {
+	if _, ok := model.(*models.Loan); ok {
+		return errors.New("loans unavailable")
+	}
+
+	return repo.Repository.Create(ctx, model)
+}
+
+func (repo failingLoans) UpdateFields(ctx context.Context, model interface{}, filter interface{}, fields bson.D) error {
+	if _, ok := model.(*models.Loan); ok {
+		return errors.New("loans unavailable")
+	}
+
+	return repo.Repository.UpdateFields(ctx, model, filter, fields)
+}
+
+func TestHandler_CheckoutAndReturnBook_loanFails(t *testing.T) {
+	handler := newTestHandler()
+	handler.Repository = failingLoans{Repository: handler.Repository}
+	admin := authmodels.User{Model: repository.Model{ID: primitive.NewObjectID()}, Username: "ada", Role: authmodels.RoleAdmin}
+
+	onShelf := createTestBook(t, handler, models.Book{Title: "The Hobbit"})
+	checkedOut := createTestBook(t, handler, models.Book{
+		Title:          "Mort",
+		CheckedOut:     true,
+		CheckedOutBy:   "Sam",
+		CheckedOutTime: time.Now().UTC().Add(-time.Hour).Truncate(time.Millisecond),
+		DueDate:        time.Now().UTC().Add(time.Hour).Truncate(time.Millisecond),
+	})
+
+	rec := handlertest.Serve(t, asUser(admin, handler.CheckoutBook), http.MethodPost, "/v1/books/{id}/checkout", "/v1/books/"+onShelf.ID.Hex()+"/checkout", `{"checked_out_by": "Alex"}`)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("CheckoutBook status code = %v, want: %v", rec.Code, http.StatusInternalServerError)
+	}
+
+	rec = handlertest.Serve(t, asUser(admin, handler.ReturnBook), http.MethodPost, "/v1/books/{id}/return", "/v1/books/"+checkedOut.ID.Hex()+"/return", "")
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("ReturnBook status code = %v, want: %v", rec.Code, http.StatusInternalServerError)
+	}
+
+	got, err := repository.Get[models.Book](context.Background(), handler.Repository, idFilter(onShelf.ID))
+	if err != nil {
+		t.Fatalf("Failed to read book: %v", err)
+	}
+
+	if got.CheckedOut || got.CheckedOutBy != "" || !got.CheckedOutTime.IsZero() || !got.DueDate.IsZero() {
+		t.Errorf("CheckoutBook without a loan left the book = %+v, want it on the shelf", got)
+	}
+
+	got, err = repository.Get[models.Book](context.Background(), handler.Repository, idFilter(checkedOut.ID))
+	if err != nil {
+		t.Fatalf("Failed to read book: %v", err)
+	}
+
+	if !got.CheckedOut || got.CheckedOutBy != "Sam" || !got.CheckedOutTime.Equal(checkedOut.CheckedOutTime) || !got.DueDate.Equal(checkedOut.DueDate) {
+		t.Errorf("ReturnBook without a loan left the book = %+v, want it still checked out to Sam", got)
+	}
+}
@@ -5,13 +5,14 @@ import (
 	"Home-Intranet-v2-Backend/internal/library/models"
 	"Home-Intranet-v2-Backend/internal/platform/logger"
 	"Home-Intranet-v2-Backend/internal/platform/response"
+	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
 	"net/http"
 	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
 )
 
 // CreateBook is the handler for adding a new book to the library
@@ -20,47 +21,63 @@ func (handler Handler) CreateBook(w http.ResponseWriter, request *http.Request)
 
 	byteData, err := io.ReadAll(request.Body)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Issue reading request body. \nError: %+v", err.Error()))
+		logger.FromContext(request.Context()).Error("Issue reading request body", zap.Error(err))
 		response.InternalServerError(w, err)
 		return
 	}
 
 	err = json.Unmarshal(byteData, &book)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Issue unmarshalling json. \nError: %+v", err.Error()))
-		response.InternalServerError(w, err)
+		logger.FromContext(request.Context()).Error("Issue unmarshalling json", zap.Error(err))
+		response.InvalidJSON(w, err)
+		return
+	}
+
+	if err = book.Validate(); err != nil {
+		response.Error(w, err)
 		return
 	}
 
 	if book.CheckedOut {
-		book.CheckedOutTime = time.Now()
+		book.CheckedOutTime = time.Now().UTC()
+
+		if book.DueDate.IsZero() {
+			book.DueDate = book.CheckedOutTime.Add(handler.LoanPeriod)
+		}
+	} else {
+		book.DueDate = time.Time{}
+	}
+
+	book.Authors, err = handler.resolveAuthors(request.Context(), book.Authors)
+	if errors.Is(err, errUnknownAuthor) {
+		response.BadRequest(w, err.Error())
+		return
 	}
 
-	err = handler.Repository.Create(request.Context(), &book)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Issue creating book. \nError: %+v", err.Error()))
+		logger.FromContext(request.Context()).Error("Issue saving authors", zap.Error(err))
 		response.InternalServerError(w, err)
 		return
 	}
 
-	for _, author := range book.Authors {
-		if err = handler.Repository.Read(request.Context(), &author, bson.D{
-			{Key: "first_name", Value: author.FirstName},
-			{Key: "middle_name", Value: author.MiddleName},
-			{Key: "last_name", Value: author.LastName},
-			{Key: "suffix", Value: author.Suffix},
-		}); err != nil && !handler.Repository.IsNotFoundError(err) {
-			logger.Error(fmt.Sprintf("Issue Finding Document. \nError: %+v", err))
-			response.InternalServerError(w, err)
-			return
-		}
+	err = handler.Repository.Create(request.Context(), &book)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue creating book", zap.Error(err))
+		response.Error(w, err)
+		return
+	}
 
-		if author.Model.ID.IsZero() {
-			if err = handler.Repository.Create(request.Context(), &author); err != nil {
-				logger.Error(fmt.Sprintf("Issue creating author. \nError: %+v", err.Error()))
-				response.InternalServerError(w, err)
-				return
+	if book.CheckedOut {
+		if err = handler.openLoan(request.Context(), book); err != nil {
+			logger.FromContext(request.Context()).Error("Issue recording loan", zap.Error(err))
+
+			// Without its loan the book would be checked out to nobody on record, so it isn't kept
+			if deleteErr := handler.Repository.Delete(context.WithoutCancel(request.Context()), &models.Book{}, idFilter(book.ID)); deleteErr != nil {
+				logger.FromContext(request.Context()).Error("Issue removing book without a loan", zap.Error(deleteErr))
 			}
+
+			response.InternalServerError(w, err)
+			return
 		}
 	}
 
@@ -0,0 +1,150 @@
+package library
+
+import (
+	"Home-Intranet-v2-Backend/cmd/handlers/handlertest"
+	"Home-Intranet-v2-Backend/internal/library/models"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHandler_CreateBook(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantCode    int
+		wantProblem string
+		wantFields  []string
+		inspectBook func(t *testing.T, book models.Book)
+	}{
+		{
+			name:     "Book with new authors",
+			body:     `{"title": "The Hobbit", "shelf": "12", "authors": [{"first_name": "J.R.R.", "last_name": "Tolkien"}]}`,
+			wantCode: http.StatusOK,
+			inspectBook: func(t *testing.T, book models.Book) {
+				if book.ID.IsZero() {
+					t.Error("CreateBook did not return the book id")
+				}
+
+				if len(book.Authors) != 1 || book.Authors[0].ID.IsZero() {
+					t.Errorf("CreateBook authors = %+v, want one linked author", book.Authors)
+				}
+			},
+		},
+		{
+			name:     "Checked out book gets a due date",
+			body:     `{"title": "The Hobbit", "authors": [{"last_name": "Tolkien"}], "checked_out": true, "checked_out_by": "Sam"}`,
+			wantCode: http.StatusOK,
+			inspectBook: func(t *testing.T, book models.Book) {
+				if book.CheckedOutTime.IsZero() || book.DueDate.IsZero() {
+					t.Errorf("CreateBook checkout = %v due %v, want both set", book.CheckedOutTime, book.DueDate)
+				}
+			},
+		},
+		{
+			name:     "Unknown author id",
+			body:     `{"title": "The Hobbit", "authors": [{"_id": "` + primitive.NewObjectID().Hex() + `"}]}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:        "Every invalid field is reported",
+			body:        `{"title": "", "authors": [{"middle_name": "R."}], "checked_out": true}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantProblem: response.CodeValidationFailed,
+			wantFields:  []string{"title", "checked_out_by", "authors.0.first_name", "authors.0.last_name"},
+		},
+		{
+			name:        "Empty authors list",
+			body:        `{"title": "The Hobbit", "authors": []}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantProblem: response.CodeValidationFailed,
+			wantFields:  []string{"authors"},
+		},
+		{
+			name:        "Malformed json",
+			body:        `{"title": "The Hobbit"`,
+			wantCode:    http.StatusBadRequest,
+			wantProblem: response.CodeInvalidJSON,
+		},
+		{
+			name:        "Value of the wrong type",
+			body:        `{"title": "The Hobbit", "shelf": 12}`,
+			wantCode:    http.StatusBadRequest,
+			wantProblem: response.CodeInvalidJSON,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandler()
+
+			rec := handlertest.Serve(t, handler.CreateBook, http.MethodPost, "/v1/books", "/v1/books", tt.body)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("CreateBook status code = %v, want: %v, body: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+
+			if tt.wantProblem != "" {
+				problem := handlertest.DecodeProblem(t, rec)
+				if problem.Code != tt.wantProblem {
+					t.Errorf("CreateBook problem code = %v, want: %v", problem.Code, tt.wantProblem)
+				}
+
+				fields := []string{}
+				for _, fieldError := range problem.Errors {
+					fields = append(fields, fieldError.Field)
+				}
+
+				if tt.wantFields != nil && !reflect.DeepEqual(fields, tt.wantFields) {
+					t.Errorf("CreateBook problem fields = %v, want: %v", fields, tt.wantFields)
+				}
+			}
+
+			if tt.inspectBook != nil {
+				var book models.Book
+				handlertest.DecodeData(t, rec, &book)
+				tt.inspectBook(t, book)
+			}
+		})
+	}
+}
+
+func TestHandler_CreateBook_duplicateID(t *testing.T) {
+	handler := newTestHandler()
+	book := createTestBook(t, handler, models.Book{Title: "The Hobbit"})
+
+	rec := handlertest.Serve(t, handler.CreateBook, http.MethodPost, "/v1/books", "/v1/books", `{"_id": "`+book.ID.Hex()+`", "title": "Mort", "authors": [{"last_name": "Pratchett"}]}`)
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("CreateBook status code = %v, want: %v, body: %s", rec.Code, http.StatusConflict, rec.Body.String())
+	}
+
+	if problem := handlertest.DecodeProblem(t, rec); problem.Code != response.CodeDuplicateKey {
+		t.Errorf("CreateBook problem code = %v, want: %v", problem.Code, response.CodeDuplicateKey)
+	}
+}
+
+func TestHandler_CreateBook_loanFails(t *testing.T) {
+	handler := newTestHandler()
+	handler.Repository = failingLoans{Repository: handler.Repository}
+
+	rec := handlertest.Serve(t, handler.CreateBook, http.MethodPost, "/v1/books", "/v1/books", `{"title": "The Hobbit", "authors": [{"last_name": "Tolkien"}], "checked_out": true, "checked_out_by": "Sam"}`)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("CreateBook status code = %v, want: %v", rec.Code, http.StatusInternalServerError)
+	}
+
+	books, err := repository.List[models.Book](context.Background(), handler.Repository, bson.D{}, repository.Sort{}, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to list books: %v", err)
+	}
+
+	if len(books) != 0 {
+		t.Errorf("CreateBook without a loan kept books = %+v, want none", books)
+	}
+}
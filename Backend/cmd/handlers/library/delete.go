@@ -0,0 +1,37 @@
+// Package library contains all the controllers for the library functionality
+package library
+
+import (
+	"Home-Intranet-v2-Backend/internal/library/models"
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// DeleteBook is the handler for removing a book from the library
+func (handler Handler) DeleteBook(w http.ResponseWriter, request *http.Request) {
+	id, err := parseID(request)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue parsing book id", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	err = handler.Repository.Delete(request.Context(), &models.Book{}, idFilter(id))
+	if handler.Repository.IsNotFoundError(err) {
+		response.NotFound(w, fmt.Sprintf("book %s not found", id.Hex()))
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue deleting book", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	response.SuccessResponse(w, id.Hex())
+	return
+}
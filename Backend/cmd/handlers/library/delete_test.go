@@ -0,0 +1,27 @@
+package library
+
+import (
+	"Home-Intranet-v2-Backend/cmd/handlers/handlertest"
+	"Home-Intranet-v2-Backend/internal/library/models"
+	"net/http"
+	"testing"
+)
+
+func TestHandler_DeleteBook(t *testing.T) {
+	handler := newTestHandler()
+	book := createTestBook(t, handler, models.Book{Title: "The Hobbit"})
+
+	target := "/v1/books/" + book.ID.Hex()
+
+	if rec := handlertest.Serve(t, handler.DeleteBook, http.MethodDelete, "/v1/books/{id}", target, ""); rec.Code != http.StatusOK {
+		t.Fatalf("DeleteBook status code = %v, want: %v", rec.Code, http.StatusOK)
+	}
+
+	if rec := handlertest.Serve(t, handler.GetBook, http.MethodGet, "/v1/books/{id}", target, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GetBook after delete status code = %v, want: %v", rec.Code, http.StatusNotFound)
+	}
+
+	if rec := handlertest.Serve(t, handler.DeleteBook, http.MethodDelete, "/v1/books/{id}", target, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("DeleteBook twice status code = %v, want: %v", rec.Code, http.StatusNotFound)
+	}
+}
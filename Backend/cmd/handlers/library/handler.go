@@ -2,9 +2,54 @@ package library
 
 import (
 	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // Handler is used to allow us to pass our data persistance objects as mocks for better testing
 type Handler struct {
-	Repository *repository.Repository
+	Repository repository.Repository
+
+	// LoanPeriod is the default length of a loan
+	LoanPeriod time.Duration
+}
+
+// parseID converts the {id} url parameter into an ObjectID
+func parseID(request *http.Request) (primitive.ObjectID, error) {
+	id, err := primitive.ObjectIDFromHex(chi.URLParam(request, "id"))
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("invalid id %q", chi.URLParam(request, "id"))
+	}
+
+	return id, nil
+}
+
+// idFilter builds the filter for finding a single document by its ObjectID
+func idFilter(id primitive.ObjectID) bson.D {
+	return bson.D{{Key: "_id", Value: id}}
+}
+
+// withQueryFilter combines a handler's own filter with the filter[...] query parameters of the
+// request, so that both have to be met
+func withQueryFilter(filter bson.D, values url.Values, fields repository.FilterFields) (bson.D, error) {
+	queryFilter, err := repository.ParseFilter(values, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	if queryFilter.IsEmpty() {
+		return filter, nil
+	}
+
+	if len(filter) == 0 {
+		return queryFilter.D(), nil
+	}
+
+	return bson.D{{Key: "$and", Value: bson.A{filter, queryFilter.D()}}}, nil
 }
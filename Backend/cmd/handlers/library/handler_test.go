@@ -0,0 +1,77 @@
+package library
+
+import (
+	"Home-Intranet-v2-Backend/internal/library/models"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_parseID(t *testing.T) {
+	validID := primitive.NewObjectID()
+
+	tests := []struct {
+		name    string
+		id      string
+		want1   primitive.ObjectID
+		wantErr bool
+	}{
+		{
+			name:  "Valid ObjectID",
+			id:    validID.Hex(),
+			want1: validID,
+		},
+		{
+			name:    "Invalid ObjectID",
+			id:      "not-an-id",
+			want1:   primitive.NilObjectID,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routeContext := chi.NewRouteContext()
+			routeContext.URLParams.Add("id", tt.id)
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/books/"+tt.id, nil)
+			request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, routeContext))
+
+			got1, err := parseID(request)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseID error = %v, wantErr: %t", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("parseID got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+// newTestHandler returns a handler backed by an empty in memory repository
+func newTestHandler() Handler {
+	return Handler{
+		Repository: repository.NewMemoryRepository(),
+		LoanPeriod: 14 * 24 * time.Hour,
+	}
+}
+
+// createTestBook stores a book directly in the repository
+func createTestBook(t *testing.T, handler Handler, book models.Book) models.Book {
+	t.Helper()
+
+	if err := handler.Repository.Create(context.Background(), &book); err != nil {
+		t.Fatalf("Failed to create book: %v", err)
+	}
+
+	return book
+}
@@ -4,81 +4,168 @@ package library
 import (
 	"Home-Intranet-v2-Backend/internal/library/models"
 	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
 	"Home-Intranet-v2-Backend/internal/platform/response"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
 )
 
+// bookFilterFields are the book fields that can be used in filter[...] query parameters
+var bookFilterFields = repository.FilterFields{
+	"_id":                 repository.ObjectIDField,
+	"title":               repository.StringField,
+	"shelf":               repository.StringField,
+	"authors._id":         repository.ObjectIDField,
+	"authors.first_name":  repository.StringField,
+	"authors.middle_name": repository.StringField,
+	"authors.last_name":   repository.StringField,
+	"checked_out":         repository.BoolField,
+	"checked_out_by":      repository.StringField,
+	"checked_out_by_id":   repository.ObjectIDField,
+	"checked_out_time":    repository.TimeField,
+	"due_date":            repository.TimeField,
+	"created_at":          repository.TimeField,
+	"updated_at":          repository.TimeField,
+}
+
+// bookSortFields are the book fields that books can be sorted by. Only fields with a single value
+// per book can be used, as the cursor of a page holds the value of its last book, which has no one
+// value for the fields of the authors list.
+var bookSortFields = map[string]bool{
+	"_id":               true,
+	"title":             true,
+	"shelf":             true,
+	"checked_out":       true,
+	"checked_out_by":    true,
+	"checked_out_by_id": true,
+	"checked_out_time":  true,
+	"due_date":          true,
+	"created_at":        true,
+	"updated_at":        true,
+}
+
 // ListBooks returns a list of books based on the parameters the user enter
 func (handler Handler) ListBooks(w http.ResponseWriter, request *http.Request) {
 	values := request.URL.Query()
 
 	sortColumn := strings.ToLower(values.Get("sort-col"))
 	sortDirectionString := strings.ToLower(values.Get("sort-dir"))
-	offsetString := values.Get("offset")
-	limitString := values.Get("limit")
 
-	// TODO: Handle Seach and Filter
+	// Build filter document
+	filter, err := buildBookFilter(values)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Error building book filter", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
 
-	// Build sort map
+	// Build sort. Books are grouped by shelf first, unless the shelf is the column being sorted on.
 	if sortColumn == "" {
 		sortColumn = "title"
 	}
 
-	sortDirection := 1
-	if sortDirectionString == "desc" {
-		sortDirection = -1
-	}
-
-	sort := map[string]string{
-		"shelf":    "1",
-		sortColumn: strconv.Itoa(sortDirection),
-	}
-
-	// Get default offset value
-	if offsetString == "" {
-		offsetString = "0"
+	if !bookSortFields[sortColumn] {
+		response.BadRequest(w, fmt.Sprintf("books can't be sorted by %q", sortColumn))
+		return
 	}
 
-	// Get default limit value
-	if limitString == "" {
-		limitString = "20"
+	sort := repository.Sort{}
+	if sortColumn != "shelf" {
+		sort = append(sort, repository.Asc("shelf"))
 	}
+	sort = append(sort, repository.SortField{Field: sortColumn, Descending: sortDirectionString == "desc"})
 
-	// Convert to ints
-	offset, err := strconv.ParseInt(offsetString, 10, 64)
+	cursor, limit, err := repository.ParsePaging(values)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Error converting offset to int: %v", err))
-		response.BadRequest(w, err)
+		logger.FromContext(request.Context()).Error("Error parsing paging parameters", zap.Error(err))
+		response.BadRequest(w, err.Error())
 		return
 	}
 
-	limit, err := strconv.ParseInt(limitString, 10, 64)
-	if err != nil {
-		logger.Error(fmt.Sprintf("Error converting offset to int: %v", err))
-		response.BadRequest(w, err)
+	// Query
+	page, err := repository.ListPage[models.Book](request.Context(), handler.Repository, filter, sort, cursor, limit)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		response.Error(w, err)
 		return
 	}
 
-	// Query
-	data, err := handler.Repository.List(request.Context(), &models.Book{}, map[string]string{}, sort, offset, limit)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Issue retriving books. \nError: %s", err.Error()))
+		logger.FromContext(request.Context()).Error("Issue retriving books", zap.Error(err))
 		response.InternalServerError(w, err)
 		return
 	}
 
-	var books []models.Book
-	err = json.Unmarshal(data, &books)
-	if err != nil {
-		logger.Error(fmt.Sprintf("Error unmarshaling data: %s", err.Error()))
-		response.BadRequest(w, err)
-		return
+	response.Page(w, request, page)
+	return
+}
+
+// buildBookFilter converts the search query parameters into a filter document. Every parameter
+// supplied narrows the result set, so the conditions are combined with AND. The filter[...]
+// parameters described by repository.ParseFilter can be used alongside the search parameters.
+func buildBookFilter(values url.Values) (bson.D, error) {
+	filter := bson.D{}
+
+	if title := strings.TrimSpace(values.Get("title")); title != "" {
+		filter = append(filter, bson.E{Key: "title", Value: containsPattern(title)})
 	}
 
-	response.SuccessResponse(w, books)
-	return
+	// Each word of the author search has to appear in one of the author's names, so that
+	// "tolkien" and "j tolkien" both find J.R.R. Tolkien.
+	if terms := strings.Fields(values.Get("author")); len(terms) > 0 {
+		filter = append(filter, everyTerm(terms, "authors.first_name", "authors.middle_name", "authors.last_name"))
+	}
+
+	if shelf := strings.TrimSpace(values.Get("shelf")); shelf != "" {
+		filter = append(filter, bson.E{Key: "shelf", Value: shelf})
+	}
+
+	if checkedOutString := strings.TrimSpace(values.Get("checked-out")); checkedOutString != "" {
+		checkedOut, err := strconv.ParseBool(checkedOutString)
+		if err != nil {
+			return nil, fmt.Errorf("checked-out must be true or false")
+		}
+
+		filter = append(filter, bson.E{Key: "checked_out", Value: checkedOut})
+	}
+
+	if checkedOutBy := strings.TrimSpace(values.Get("checked-out-by")); checkedOutBy != "" {
+		filter = append(filter, bson.E{Key: "checked_out_by", Value: bson.D{
+			{Key: "$regex", Value: "^" + regexp.QuoteMeta(checkedOutBy) + "$"},
+			{Key: "$options", Value: "i"},
+		}})
+	}
+
+	return withQueryFilter(filter, values, bookFilterFields)
+}
+
+// everyTerm builds the condition that each search term appears in at least one of the fields. The
+// $or of each term is gathered under a single $and, as a filter can't hold the same key twice.
+func everyTerm(terms []string, fields ...string) bson.E {
+	conditions := bson.A{}
+	for _, term := range terms {
+		alternatives := bson.A{}
+		for _, field := range fields {
+			alternatives = append(alternatives, bson.D{{Key: field, Value: containsPattern(term)}})
+		}
+
+		conditions = append(conditions, bson.D{{Key: "$or", Value: alternatives}})
+	}
+
+	return bson.E{Key: "$and", Value: conditions}
+}
+
+// containsPattern builds a case insensitive substring match for the user's search text
+func containsPattern(text string) bson.D {
+	return bson.D{
+		{Key: "$regex", Value: regexp.QuoteMeta(text)},
+		{Key: "$options", Value: "i"},
+	}
 }
@@ -0,0 +1,315 @@
+package library
+
+import (
+	"Home-Intranet-v2-Backend/cmd/handlers/handlertest"
+	"Home-Intranet-v2-Backend/internal/library/models"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func Test_buildBookFilter(t *testing.T) {
+	type args struct {
+		values url.Values
+	}
+	tests := []struct {
+		name    string
+		args    func(t *testing.T) args
+		want1   bson.D
+		wantErr bool
+	}{
+		{
+			name: "No search parameters",
+			args: func(_ *testing.T) args {
+				return args{values: url.Values{}}
+			},
+			want1: bson.D{},
+		},
+		{
+			name: "Title substring is escaped and case insensitive",
+			args: func(_ *testing.T) args {
+				return args{values: url.Values{"title": {"Hobbit?"}}}
+			},
+			want1: bson.D{
+				{Key: "title", Value: bson.D{{Key: "$regex", Value: `Hobbit\?`}, {Key: "$options", Value: "i"}}},
+			},
+		},
+		{
+			name: "Author terms are each matched against every name",
+			args: func(_ *testing.T) args {
+				return args{values: url.Values{"author": {"j tolkien"}}}
+			},
+			want1: bson.D{
+				{Key: "$and", Value: bson.A{
+					bson.D{{Key: "$or", Value: bson.A{
+						bson.D{{Key: "authors.first_name", Value: bson.D{{Key: "$regex", Value: "j"}, {Key: "$options", Value: "i"}}}},
+						bson.D{{Key: "authors.middle_name", Value: bson.D{{Key: "$regex", Value: "j"}, {Key: "$options", Value: "i"}}}},
+						bson.D{{Key: "authors.last_name", Value: bson.D{{Key: "$regex", Value: "j"}, {Key: "$options", Value: "i"}}}},
+					}}},
+					bson.D{{Key: "$or", Value: bson.A{
+						bson.D{{Key: "authors.first_name", Value: bson.D{{Key: "$regex", Value: "tolkien"}, {Key: "$options", Value: "i"}}}},
+						bson.D{{Key: "authors.middle_name", Value: bson.D{{Key: "$regex", Value: "tolkien"}, {Key: "$options", Value: "i"}}}},
+						bson.D{{Key: "authors.last_name", Value: bson.D{{Key: "$regex", Value: "tolkien"}, {Key: "$options", Value: "i"}}}},
+					}}},
+				}},
+			},
+		},
+		{
+			name: "Shelf, checked out and checked out by combined",
+			args: func(_ *testing.T) args {
+				return args{values: url.Values{
+					"shelf":          {"12"},
+					"checked-out":    {"true"},
+					"checked-out-by": {"Sam"},
+				}}
+			},
+			want1: bson.D{
+				{Key: "shelf", Value: "12"},
+				{Key: "checked_out", Value: true},
+				{Key: "checked_out_by", Value: bson.D{{Key: "$regex", Value: "^Sam$"}, {Key: "$options", Value: "i"}}},
+			},
+		},
+		{
+			name: "Filter parameters are combined with the search parameters",
+			args: func(_ *testing.T) args {
+				return args{values: url.Values{
+					"shelf":                         {"12"},
+					"filter[checked_out_time][gte]": {"2026-01-01"},
+				}}
+			},
+			want1: bson.D{{Key: "$and", Value: bson.A{
+				bson.D{{Key: "shelf", Value: "12"}},
+				bson.D{{Key: "checked_out_time", Value: bson.D{{Key: "$gte", Value: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)}}}},
+			}}},
+		},
+		{
+			name: "Filter on an unsupported field",
+			args: func(_ *testing.T) args {
+				return args{values: url.Values{"filter[isbn]": {"123"}}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid checked out value",
+			args: func(_ *testing.T) args {
+				return args{values: url.Values{"checked-out": {"maybe"}}}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tArgs := tt.args(t)
+
+			got1, err := buildBookFilter(tArgs.values)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildBookFilter error = %v, wantErr: %t", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("buildBookFilter got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestHandler_ListBooks(t *testing.T) {
+	handler := newTestHandler()
+
+	createTestBook(t, handler, models.Book{Title: "The Hobbit", Shelf: "12", Authors: []models.Author{{FirstName: "J.R.R.", LastName: "Tolkien"}}})
+	createTestBook(t, handler, models.Book{Title: "The Two Towers", Shelf: "12", CheckedOut: true, CheckedOutBy: "Sam", CheckedOutTime: time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC), Authors: []models.Author{{FirstName: "J.R.R.", LastName: "Tolkien"}}})
+	createTestBook(t, handler, models.Book{Title: "Mort", Shelf: "12", Authors: []models.Author{{FirstName: "Terry", LastName: "Pratchett"}}})
+
+	tests := []struct {
+		name      string
+		query     string
+		wantCode  int
+		wantTitle []string
+	}{
+		{
+			name:      "Default sort by title",
+			query:     "sort-col=title",
+			wantCode:  http.StatusOK,
+			wantTitle: []string{"Mort", "The Hobbit", "The Two Towers"},
+		},
+		{
+			name:      "Author and checked out filters combined",
+			query:     "author=tolkien&checked-out=false",
+			wantCode:  http.StatusOK,
+			wantTitle: []string{"The Hobbit"},
+		},
+		{
+			name:      "Title search with paging",
+			query:     "title=the&sort-col=title&sort-dir=desc&limit=1",
+			wantCode:  http.StatusOK,
+			wantTitle: []string{"The Two Towers"},
+		},
+		{
+			name:      "Checked out date range filter",
+			query:     "filter[checked_out_time][gte]=2026-03-01&filter[checked_out_time][lt]=2026-04-01",
+			wantCode:  http.StatusOK,
+			wantTitle: []string{"The Two Towers"},
+		},
+		{
+			name:      "Shelf filter keeps numeric looking text as text",
+			query:     "filter[shelf][in]=12,4&filter[or][a][title][prefix]=M&filter[or][b][checked_out]=true&sort-col=title",
+			wantCode:  http.StatusOK,
+			wantTitle: []string{"Mort", "The Two Towers"},
+		},
+		{
+			name:     "Unknown sort column",
+			query:    "sort-col=password",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Sort column with a value per author",
+			query:    "sort-col=authors.last_name",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid filter",
+			query:    "filter[checked_out_time][gte]=soon",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid cursor",
+			query:    "cursor=nonsense",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid limit",
+			query:    "limit=ten",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := handlertest.Serve(t, handler.ListBooks, http.MethodGet, "/v1/books", "/v1/books?"+tt.query, "")
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("ListBooks status code = %v, want: %v", rec.Code, tt.wantCode)
+			}
+
+			if tt.wantTitle == nil {
+				return
+			}
+
+			var page repository.Page[models.Book]
+			handlertest.DecodeData(t, rec, &page)
+
+			titles := []string{}
+			for _, book := range page.Items {
+				titles = append(titles, book.Title)
+			}
+
+			if !reflect.DeepEqual(titles, tt.wantTitle) {
+				t.Errorf("ListBooks titles = %v, want: %v", titles, tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestHandler_ListBooks_sort(t *testing.T) {
+	handler := newTestHandler()
+
+	createTestBook(t, handler, models.Book{Title: "Mort", Shelf: "1"})
+	createTestBook(t, handler, models.Book{Title: "Eric", Shelf: "2"})
+	createTestBook(t, handler, models.Book{Title: "Sourcery", Shelf: "2"})
+	createTestBook(t, handler, models.Book{Title: "Pyramids", Shelf: "3"})
+
+	tests := []struct {
+		name      string
+		query     string
+		wantTitle []string
+	}{
+		{
+			name:      "Grouped by shelf, then sorted by title",
+			query:     "sort-col=title&sort-dir=desc",
+			wantTitle: []string{"Mort", "Sourcery", "Eric", "Pyramids"},
+		},
+		{
+			name:      "Shelf sorted descending",
+			query:     "sort-col=shelf&sort-dir=desc",
+			wantTitle: []string{"Pyramids", "Eric", "Sourcery", "Mort"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := handlertest.Serve(t, handler.ListBooks, http.MethodGet, "/v1/books", "/v1/books?"+tt.query, "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("ListBooks status code = %v, want: %v", rec.Code, http.StatusOK)
+			}
+
+			var page repository.Page[models.Book]
+			handlertest.DecodeData(t, rec, &page)
+
+			titles := []string{}
+			for _, book := range page.Items {
+				titles = append(titles, book.Title)
+			}
+
+			if !reflect.DeepEqual(titles, tt.wantTitle) {
+				t.Errorf("ListBooks titles = %v, want: %v", titles, tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestHandler_ListBooks_paging(t *testing.T) {
+	handler := newTestHandler()
+
+	for _, title := range []string{"Eric", "Mort", "Sourcery", "Wyrd Sisters", "Pyramids"} {
+		createTestBook(t, handler, models.Book{Title: title, Shelf: "7"})
+	}
+
+	linkPattern := regexp.MustCompile(`<([^>]+)>; rel="(next|prev)"`)
+
+	target := "/v1/books?sort-col=title&limit=2"
+	var pages [][]string
+
+	for target != "" && len(pages) < 5 {
+		rec := handlertest.Serve(t, handler.ListBooks, http.MethodGet, "/v1/books", target, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("ListBooks status code = %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		var page repository.Page[models.Book]
+		handlertest.DecodeData(t, rec, &page)
+
+		if page.Total != 5 {
+			t.Errorf("ListBooks total = %v, want: %v", page.Total, 5)
+		}
+
+		titles := []string{}
+		for _, book := range page.Items {
+			titles = append(titles, book.Title)
+		}
+		pages = append(pages, titles)
+
+		links := map[string]string{}
+		for _, match := range linkPattern.FindAllStringSubmatch(rec.Header().Get("Link"), -1) {
+			links[match[2]] = match[1]
+		}
+
+		if len(pages) > 1 && links["prev"] == "" {
+			t.Errorf("ListBooks page %d has no prev link", len(pages))
+		}
+
+		target = links["next"]
+	}
+
+	want := [][]string{{"Eric", "Mort"}, {"Pyramids", "Sourcery"}, {"Wyrd Sisters"}}
+	if !reflect.DeepEqual(pages, want) {
+		t.Errorf("ListBooks pages = %v, want: %v", pages, want)
+	}
+}
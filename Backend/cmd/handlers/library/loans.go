@@ -0,0 +1,173 @@
+// Package library contains all the controllers for the library functionality
+package library
+
+import (
+	"Home-Intranet-v2-Backend/internal/library/models"
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// loanFilterFields are the loan fields that can be used in filter[...] query parameters
+var loanFilterFields = repository.FilterFields{
+	"_id":              repository.ObjectIDField,
+	"book_id":          repository.ObjectIDField,
+	"book_title":       repository.StringField,
+	"borrower":         repository.StringField,
+	"borrower_id":      repository.ObjectIDField,
+	"checked_out_time": repository.TimeField,
+	"due_date":         repository.TimeField,
+	"returned":         repository.BoolField,
+	"returned_time":    repository.TimeField,
+}
+
+// ListLoans returns the circulation history of the whole library, newest first. The borrower_id
+// and borrower query parameters narrow it down to the loans of one user or of someone without an
+// account, and returned to open or closed loans.
+func (handler Handler) ListLoans(w http.ResponseWriter, request *http.Request) {
+	values := request.URL.Query()
+
+	filter := bson.D{}
+
+	if borrowerIDString := strings.TrimSpace(values.Get("borrower_id")); borrowerIDString != "" {
+		borrowerID, err := primitive.ObjectIDFromHex(borrowerIDString)
+		if err != nil {
+			response.BadRequest(w, fmt.Sprintf("invalid borrower_id %q", borrowerIDString))
+			return
+		}
+
+		filter = append(filter, bson.E{Key: "borrower_id", Value: borrowerID})
+	}
+
+	if borrower := strings.TrimSpace(values.Get("borrower")); borrower != "" {
+		filter = append(filter, bson.E{Key: "borrower", Value: borrower})
+	}
+
+	if returnedString := strings.TrimSpace(values.Get("returned")); returnedString != "" {
+		returned, err := strconv.ParseBool(returnedString)
+		if err != nil {
+			response.BadRequest(w, "returned must be true or false")
+			return
+		}
+
+		filter = append(filter, bson.E{Key: "returned", Value: returned})
+	}
+
+	handler.listLoans(w, request, filter)
+	return
+}
+
+// ListBookLoans returns the circulation history of a single book, newest first
+func (handler Handler) ListBookLoans(w http.ResponseWriter, request *http.Request) {
+	id, err := parseID(request)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue parsing book id", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	handler.listLoans(w, request, bson.D{{Key: "book_id", Value: id}})
+	return
+}
+
+// listLoans responds with the loans matching a filter narrowed down by the filter[...] query
+// parameters, newest first
+func (handler Handler) listLoans(w http.ResponseWriter, request *http.Request, filter bson.D) {
+	cursor, limit, err := repository.ParsePaging(request.URL.Query())
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Error parsing paging parameters", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	filter, err = withQueryFilter(filter, request.URL.Query(), loanFilterFields)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Error building loan filter", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	sort := repository.Sort{
+		repository.Desc("checked_out_time"),
+	}
+
+	page, err := repository.ListPage[models.Loan](request.Context(), handler.Repository, filter, sort, cursor, limit)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		response.Error(w, err)
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue retriving loans", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	response.Page(w, request, page)
+}
+
+// openLoan records the start of a loan for a book that has just been checked out
+func (handler Handler) openLoan(ctx context.Context, book models.Book) error {
+	loan := models.Loan{
+		BookID:         book.ID,
+		BookTitle:      book.Title,
+		Borrower:       book.CheckedOutBy,
+		BorrowerID:     book.CheckedOutByID,
+		CheckedOutTime: book.CheckedOutTime,
+		DueDate:        book.DueDate,
+	}
+
+	if err := handler.Repository.Create(ctx, &loan); err != nil {
+		return fmt.Errorf("issue creating loan: %w", err)
+	}
+
+	return nil
+}
+
+// closeLoan marks the open loan of a book as returned. The book is passed as it was before the
+// return, so a loan can still be recorded for books that were checked out before loans were kept.
+func (handler Handler) closeLoan(ctx context.Context, book models.Book, returnedTime time.Time) error {
+	var loan models.Loan
+	err := handler.Repository.UpdateFields(ctx, &loan, bson.D{
+		{Key: "book_id", Value: book.ID},
+		{Key: "returned", Value: false},
+	}, bson.D{
+		{Key: "returned", Value: true},
+		{Key: "returned_time", Value: returnedTime},
+	})
+	if err == nil {
+		return nil
+	}
+
+	if !handler.Repository.IsNotFoundError(err) {
+		return fmt.Errorf("issue closing loan: %w", err)
+	}
+
+	loan = models.Loan{
+		BookID:         book.ID,
+		BookTitle:      book.Title,
+		Borrower:       book.CheckedOutBy,
+		BorrowerID:     book.CheckedOutByID,
+		CheckedOutTime: book.CheckedOutTime,
+		DueDate:        book.DueDate,
+		Returned:       true,
+		ReturnedTime:   returnedTime,
+	}
+
+	if err = handler.Repository.Create(ctx, &loan); err != nil {
+		return fmt.Errorf("issue creating loan: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package library
+
+import (
+	"Home-Intranet-v2-Backend/cmd/handlers/handlertest"
+	"Home-Intranet-v2-Backend/internal/library/models"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHandler_ListLoans(t *testing.T) {
+	handler := newTestHandler()
+	samID := primitive.NewObjectID()
+
+	for _, loan := range []models.Loan{
+		{BookID: primitive.NewObjectID(), BookTitle: "The Hobbit", Borrower: "Sam", BorrowerID: samID, CheckedOutTime: time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC), Returned: true},
+		{BookID: primitive.NewObjectID(), BookTitle: "Mort", Borrower: "Alex", CheckedOutTime: time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)},
+		{BookID: primitive.NewObjectID(), BookTitle: "Eric", Borrower: "Sam", CheckedOutTime: time.Date(2026, time.March, 3, 9, 0, 0, 0, time.UTC)},
+	} {
+		if err := handler.Repository.Create(context.Background(), &loan); err != nil {
+			t.Fatalf("Failed to create loan: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		wantCode  int
+		wantTitle []string
+	}{
+		{
+			name:      "Newest first",
+			query:     "",
+			wantCode:  http.StatusOK,
+			wantTitle: []string{"Eric", "Mort", "The Hobbit"},
+		},
+		{
+			name:      "By borrower",
+			query:     "borrower=Sam",
+			wantCode:  http.StatusOK,
+			wantTitle: []string{"Eric", "The Hobbit"},
+		},
+		{
+			name:      "By borrower id",
+			query:     "borrower_id=" + samID.Hex(),
+			wantCode:  http.StatusOK,
+			wantTitle: []string{"The Hobbit"},
+		},
+		{
+			name:     "Invalid borrower id",
+			query:    "borrower_id=sam",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:      "Open loans",
+			query:     "returned=false",
+			wantCode:  http.StatusOK,
+			wantTitle: []string{"Eric", "Mort"},
+		},
+		{
+			name:      "Borrower and returned combined",
+			query:     "borrower=Sam&returned=true",
+			wantCode:  http.StatusOK,
+			wantTitle: []string{"The Hobbit"},
+		},
+		{
+			name:      "Filter parameters",
+			query:     "filter[checked_out_time][lt]=2026-03-02",
+			wantCode:  http.StatusOK,
+			wantTitle: []string{"The Hobbit"},
+		},
+		{
+			name:     "Invalid returned",
+			query:    "returned=sometimes",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid filter",
+			query:    "filter[due_date][gte]=soon",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Invalid limit",
+			query:    "limit=ten",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := handlertest.Serve(t, handler.ListLoans, http.MethodGet, "/v1/loans", "/v1/loans?"+tt.query, "")
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("ListLoans status code = %v, want: %v", rec.Code, tt.wantCode)
+			}
+
+			if tt.wantTitle == nil {
+				return
+			}
+
+			var page repository.Page[models.Loan]
+			handlertest.DecodeData(t, rec, &page)
+
+			titles := []string{}
+			for _, loan := range page.Items {
+				titles = append(titles, loan.BookTitle)
+			}
+
+			if !reflect.DeepEqual(titles, tt.wantTitle) {
+				t.Errorf("ListLoans titles = %v, want: %v", titles, tt.wantTitle)
+			}
+		})
+	}
+}
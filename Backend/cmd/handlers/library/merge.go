@@ -0,0 +1,133 @@
+// Package library contains all the controllers for the library functionality
+package library
+
+import (
+	"Home-Intranet-v2-Backend/internal/library/models"
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// mergeRequest is the body accepted when merging duplicate authors into the author in the url
+type mergeRequest struct {
+	DuplicateIDs []primitive.ObjectID `json:"duplicate_ids"`
+}
+
+// mergeResult reports what a merge changed
+type mergeResult struct {
+	Survivor     models.Author        `json:"survivor"`
+	Merged       []primitive.ObjectID `json:"merged"`
+	BooksUpdated int                  `json:"books_updated"`
+}
+
+// ListDuplicateAuthors suggests groups of authors that are likely the same person
+func (handler Handler) ListDuplicateAuthors(w http.ResponseWriter, request *http.Request) {
+	authors, err := repository.List[models.Author](request.Context(), handler.Repository, bson.D{}, repository.Sort{repository.Asc("last_name")}, 0, 0)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue retriving authors", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	response.SuccessResponse(w, models.FindDuplicateAuthors(authors))
+	return
+}
+
+// MergeAuthors merges duplicate authors into the author in the url. Not every storage backend has
+// transactions, so the merge is made safe to retry instead: every book that references a duplicate
+// is rewritten to reference the surviving author before the duplicate is deleted, so no book is
+// left pointing at a deleted author, and a duplicate that is already gone counts as merged. A merge
+// that fails partway can then be sent again to finish it.
+func (handler Handler) MergeAuthors(w http.ResponseWriter, request *http.Request) {
+	id, err := parseID(request)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue parsing author id", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	byteData, err := io.ReadAll(request.Body)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue reading request body", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	var merge mergeRequest
+	err = json.Unmarshal(byteData, &merge)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue unmarshalling json", zap.Error(err))
+		response.InvalidJSON(w, err)
+		return
+	}
+
+	if len(merge.DuplicateIDs) == 0 {
+		response.BadRequest(w, "duplicate_ids is required")
+		return
+	}
+
+	var survivor models.Author
+	err = handler.Repository.Read(request.Context(), &survivor, idFilter(id))
+	if handler.Repository.IsNotFoundError(err) {
+		response.NotFound(w, fmt.Sprintf("author %s not found", id.Hex()))
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue retrieving author", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	// Check every duplicate before changing anything. One that doesn't exist was deleted by an
+	// earlier attempt at the merge, so only books it may have left behind are repointed.
+	for _, duplicateID := range merge.DuplicateIDs {
+		if duplicateID == id {
+			response.BadRequest(w, "an author can't be merged into itself")
+			return
+		}
+
+		var duplicate models.Author
+		err = handler.Repository.Read(request.Context(), &duplicate, idFilter(duplicateID))
+		if err != nil && !handler.Repository.IsNotFoundError(err) {
+			logger.FromContext(request.Context()).Error("Issue retrieving author", zap.Error(err))
+			response.InternalServerError(w, err)
+			return
+		}
+	}
+
+	result := mergeResult{
+		Survivor: survivor,
+		Merged:   []primitive.ObjectID{},
+	}
+
+	for _, duplicateID := range merge.DuplicateIDs {
+		updated, err := handler.replaceAuthorInBooks(request.Context(), duplicateID, survivor)
+		if err != nil {
+			logger.FromContext(request.Context()).Error("Issue updating author's books", zap.Error(err))
+			response.InternalServerError(w, err)
+			return
+		}
+
+		err = handler.Repository.Delete(request.Context(), &models.Author{}, idFilter(duplicateID))
+		if err != nil && !handler.Repository.IsNotFoundError(err) {
+			logger.FromContext(request.Context()).Error("Issue deleting author", zap.Error(err))
+			response.InternalServerError(w, err)
+			return
+		}
+
+		result.BooksUpdated += updated
+		result.Merged = append(result.Merged, duplicateID)
+	}
+
+	response.SuccessResponse(w, &result)
+	return
+}
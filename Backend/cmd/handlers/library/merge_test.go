@@ -0,0 +1,127 @@
+package library
+
+import (
+	"Home-Intranet-v2-Backend/cmd/handlers/handlertest"
+	"Home-Intranet-v2-Backend/internal/library/models"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestHandler_MergeAuthors(t *testing.T) {
+	handler := newTestHandler()
+
+	bodies := []string{
+		`{"title": "The Hobbit", "authors": [{"first_name": "J.R.R.", "last_name": "Tolkien"}]}`,
+		`{"title": "The Silmarillion", "authors": [{"first_name": "J. R. R.", "last_name": "Tolkien"}]}`,
+	}
+
+	var books []models.Book
+	for _, body := range bodies {
+		rec := handlertest.Serve(t, handler.CreateBook, http.MethodPost, "/v1/books", "/v1/books", body)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("CreateBook status code = %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		var book models.Book
+		handlertest.DecodeData(t, rec, &book)
+		books = append(books, book)
+	}
+
+	rec := handlertest.Serve(t, handler.ListDuplicateAuthors, http.MethodGet, "/v1/authors/duplicates", "/v1/authors/duplicates", "")
+
+	var duplicates []models.DuplicateAuthors
+	handlertest.DecodeData(t, rec, &duplicates)
+
+	if len(duplicates) != 1 || len(duplicates[0].Duplicates) != 1 {
+		t.Fatalf("ListDuplicateAuthors = %+v, want one pair", duplicates)
+	}
+
+	survivor := books[0].Authors[0].ID.Hex()
+	loser := books[1].Authors[0].ID.Hex()
+
+	rec = handlertest.Serve(t, handler.MergeAuthors, http.MethodPost, "/v1/authors/{id}/merge", "/v1/authors/"+survivor+"/merge", `{"duplicate_ids": ["`+loser+`"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("MergeAuthors status code = %v, want: %v, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	rec = handlertest.Serve(t, handler.GetBook, http.MethodGet, "/v1/books/{id}", "/v1/books/"+books[1].ID.Hex(), "")
+
+	var book models.Book
+	handlertest.DecodeData(t, rec, &book)
+
+	if book.Authors[0].ID.Hex() != survivor || book.Authors[0].FirstName != "J.R.R." {
+		t.Errorf("Merged book author = %+v, want the survivor", book.Authors[0])
+	}
+
+	if rec = handlertest.Serve(t, handler.GetAuthor, http.MethodGet, "/v1/authors/{id}", "/v1/authors/"+loser, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GetAuthor for merged author status code = %v, want: %v", rec.Code, http.StatusNotFound)
+	}
+}
+
+// failingAuthorDeletes fails every delete of an author, standing in for a merge that stops partway
+type failingAuthorDeletes struct {
+	repository.Repository
+}
+
+func (repo failingAuthorDeletes) Delete(ctx context.Context, model interface{}, filter interface{}) error {
+	if _, ok := model.(*models.Author); ok {
+		return errors.New("storage unavailable")
+	}
+
+	return repo.Repository.Delete(ctx, model, filter)
+}
+
+func TestHandler_MergeAuthors_retry(t *testing.T) {
+	handler := newTestHandler()
+
+	var books []models.Book
+	for _, body := range []string{
+		`{"title": "Mort", "authors": [{"first_name": "Terry", "last_name": "Pratchett"}]}`,
+		`{"title": "Eric", "authors": [{"first_name": "T.", "last_name": "Pratchett"}]}`,
+	} {
+		rec := handlertest.Serve(t, handler.CreateBook, http.MethodPost, "/v1/books", "/v1/books", body)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("CreateBook status code = %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		var book models.Book
+		handlertest.DecodeData(t, rec, &book)
+		books = append(books, book)
+	}
+
+	survivor := books[0].Authors[0].ID.Hex()
+	loser := books[1].Authors[0].ID.Hex()
+	target := "/v1/authors/" + survivor + "/merge"
+	body := `{"duplicate_ids": ["` + loser + `"]}`
+
+	failing := handler
+	failing.Repository = failingAuthorDeletes{Repository: handler.Repository}
+
+	rec := handlertest.Serve(t, failing.MergeAuthors, http.MethodPost, "/v1/authors/{id}/merge", target, body)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("MergeAuthors failing to delete status code = %v, want: %v", rec.Code, http.StatusInternalServerError)
+	}
+
+	book, err := repository.Get[models.Book](context.Background(), handler.Repository, idFilter(books[1].ID))
+	if err != nil {
+		t.Fatalf("Failed to read book: %v", err)
+	}
+
+	if book.Authors[0].ID.Hex() != survivor {
+		t.Errorf("Book after a failed merge author = %+v, want the survivor", book.Authors[0])
+	}
+
+	for i := 0; i < 2; i++ {
+		rec = handlertest.Serve(t, handler.MergeAuthors, http.MethodPost, "/v1/authors/{id}/merge", target, body)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("MergeAuthors retry %d status code = %v, want: %v, body: %s", i+1, rec.Code, http.StatusOK, rec.Body.String())
+		}
+	}
+
+	if rec = handlertest.Serve(t, handler.GetAuthor, http.MethodGet, "/v1/authors/{id}", "/v1/authors/"+loser, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GetAuthor for merged author status code = %v, want: %v", rec.Code, http.StatusNotFound)
+	}
+}
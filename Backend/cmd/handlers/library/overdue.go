@@ -0,0 +1,65 @@
+// Package library contains all the controllers for the library functionality
+package library
+
+import (
+	"Home-Intranet-v2-Backend/internal/library/models"
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"net/http"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+// overdueBook is a checked out book that is past its due date
+type overdueBook struct {
+	Book        models.Book `json:"book"`
+	Borrower    string      `json:"borrower"`
+	DueDate     time.Time   `json:"due_date"`
+	DaysOverdue int         `json:"days_overdue"`
+}
+
+// ListOverdueBooks returns every checked out book that is past its due date, most overdue first
+func (handler Handler) ListOverdueBooks(w http.ResponseWriter, request *http.Request) {
+	// Books checked out before due dates were recorded have no due date to query on, so every
+	// checked out book is loaded and checked against its effective due date
+	books, err := repository.List[models.Book](request.Context(), handler.Repository, bson.D{
+		{Key: "checked_out", Value: true},
+	}, repository.Sort{}, 0, 0)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue retriving books", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	response.SuccessResponse(w, findOverdue(books, handler.LoanPeriod, time.Now().UTC()))
+	return
+}
+
+// findOverdue picks the overdue books out of a list of checked out books, most overdue first
+func findOverdue(books []models.Book, loanPeriod time.Duration, now time.Time) []overdueBook {
+	overdue := []overdueBook{}
+
+	for _, book := range books {
+		daysOverdue := book.DaysOverdue(loanPeriod, now)
+		if daysOverdue == 0 {
+			continue
+		}
+
+		overdue = append(overdue, overdueBook{
+			Book:        book,
+			Borrower:    book.CheckedOutBy,
+			DueDate:     book.DueBy(loanPeriod),
+			DaysOverdue: daysOverdue,
+		})
+	}
+
+	sort.SliceStable(overdue, func(i, j int) bool {
+		return overdue[i].DueDate.Before(overdue[j].DueDate)
+	})
+
+	return overdue
+}
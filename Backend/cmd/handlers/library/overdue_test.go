@@ -0,0 +1,97 @@
+package library
+
+import (
+	"Home-Intranet-v2-Backend/cmd/handlers/handlertest"
+	"Home-Intranet-v2-Backend/internal/library/models"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_findOverdue(t *testing.T) {
+	now := time.Date(2026, time.March, 20, 12, 0, 0, 0, time.UTC)
+	loanPeriod := 14 * 24 * time.Hour
+
+	tests := []struct {
+		name      string
+		books     []models.Book
+		wantTitle []string
+		wantDays  []int
+	}{
+		{
+			name:      "No books",
+			books:     []models.Book{},
+			wantTitle: []string{},
+			wantDays:  []int{},
+		},
+		{
+			name: "Only overdue books, most overdue first",
+			books: []models.Book{
+				{Title: "Slightly late", CheckedOut: true, CheckedOutBy: "Sam", DueDate: now.Add(-2 * time.Hour)},
+				{Title: "Not due", CheckedOut: true, CheckedOutBy: "Sam", DueDate: now.Add(48 * time.Hour)},
+				{Title: "Very late", CheckedOut: true, CheckedOutBy: "Alex", DueDate: now.Add(-5 * 24 * time.Hour)},
+				{Title: "Legacy loan", CheckedOut: true, CheckedOutBy: "Jo", CheckedOutTime: now.Add(-17 * 24 * time.Hour)},
+			},
+			wantTitle: []string{"Very late", "Legacy loan", "Slightly late"},
+			wantDays:  []int{5, 3, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1 := findOverdue(tt.books, loanPeriod, now)
+
+			if len(got1) != len(tt.wantTitle) {
+				t.Fatalf("findOverdue returned %d books, want: %d", len(got1), len(tt.wantTitle))
+			}
+
+			for i, overdue := range got1 {
+				if overdue.Book.Title != tt.wantTitle[i] {
+					t.Errorf("findOverdue[%d] title = %v, want: %v", i, overdue.Book.Title, tt.wantTitle[i])
+				}
+
+				if overdue.DaysOverdue != tt.wantDays[i] {
+					t.Errorf("findOverdue[%d] days overdue = %v, want: %v", i, overdue.DaysOverdue, tt.wantDays[i])
+				}
+
+				if overdue.Borrower != overdue.Book.CheckedOutBy {
+					t.Errorf("findOverdue[%d] borrower = %v, want: %v", i, overdue.Borrower, overdue.Book.CheckedOutBy)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_ListOverdueBooks(t *testing.T) {
+	handler := newTestHandler()
+	now := time.Now().UTC()
+
+	createTestBook(t, handler, models.Book{Title: "On time", CheckedOut: true, CheckedOutBy: "Sam", CheckedOutTime: now.Add(-24 * time.Hour), DueDate: now.Add(24 * time.Hour)})
+	createTestBook(t, handler, models.Book{Title: "Late", CheckedOut: true, CheckedOutBy: "Sam", CheckedOutTime: now.Add(-72 * time.Hour), DueDate: now.Add(-36 * time.Hour)})
+	createTestBook(t, handler, models.Book{Title: "No due date", CheckedOut: true, CheckedOutBy: "Alex", CheckedOutTime: now.Add(-handler.LoanPeriod - 12*time.Hour)})
+	createTestBook(t, handler, models.Book{Title: "On the shelf", DueDate: now.Add(-72 * time.Hour)})
+
+	rec := handlertest.Serve(t, handler.ListOverdueBooks, http.MethodGet, "/v1/books/overdue", "/v1/books/overdue", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ListOverdueBooks status code = %v, want: %v", rec.Code, http.StatusOK)
+	}
+
+	var got []overdueBook
+	handlertest.DecodeData(t, rec, &got)
+
+	titles := []string{}
+	days := []int{}
+	for _, overdue := range got {
+		titles = append(titles, overdue.Book.Title)
+		days = append(days, overdue.DaysOverdue)
+	}
+
+	if want := []string{"Late", "No due date"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("ListOverdueBooks titles = %v, want: %v", titles, want)
+	}
+
+	if want := []int{2, 1}; !reflect.DeepEqual(days, want) {
+		t.Errorf("ListOverdueBooks days overdue = %v, want: %v", days, want)
+	}
+}
@@ -0,0 +1,38 @@
+// Package library contains all the controllers for the library functionality
+package library
+
+import (
+	"Home-Intranet-v2-Backend/internal/library/models"
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// GetBook returns a single book by its id
+func (handler Handler) GetBook(w http.ResponseWriter, request *http.Request) {
+	id, err := parseID(request)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue parsing book id", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	book, err := repository.Get[models.Book](request.Context(), handler.Repository, idFilter(id))
+	if handler.Repository.IsNotFoundError(err) {
+		response.NotFound(w, fmt.Sprintf("book %s not found", id.Hex()))
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue retrieving book", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	response.SuccessResponse(w, &book)
+	return
+}
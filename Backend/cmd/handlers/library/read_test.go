@@ -0,0 +1,58 @@
+package library
+
+import (
+	"Home-Intranet-v2-Backend/cmd/handlers/handlertest"
+	"Home-Intranet-v2-Backend/internal/library/models"
+	"net/http"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHandler_GetBook(t *testing.T) {
+	handler := newTestHandler()
+	book := createTestBook(t, handler, models.Book{Title: "The Hobbit", Shelf: "12"})
+
+	tests := []struct {
+		name      string
+		id        string
+		wantCode  int
+		wantTitle string
+	}{
+		{
+			name:      "Existing book",
+			id:        book.ID.Hex(),
+			wantCode:  http.StatusOK,
+			wantTitle: "The Hobbit",
+		},
+		{
+			name:     "Missing book",
+			id:       primitive.NewObjectID().Hex(),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Invalid id",
+			id:       "not-an-id",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := handlertest.Serve(t, handler.GetBook, http.MethodGet, "/v1/books/{id}", "/v1/books/"+tt.id, "")
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("GetBook status code = %v, want: %v", rec.Code, tt.wantCode)
+			}
+
+			if tt.wantTitle != "" {
+				var got models.Book
+				handlertest.DecodeData(t, rec, &got)
+
+				if got.Title != tt.wantTitle {
+					t.Errorf("GetBook title = %v, want: %v", got.Title, tt.wantTitle)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Package library contains all the controllers for the library functionality
+package library
+
+import (
+	"Home-Intranet-v2-Backend/internal/library/models"
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+// UpdateBook is the handler for replacing every field of a book
+func (handler Handler) UpdateBook(w http.ResponseWriter, request *http.Request) {
+	handler.updateBook(w, request, false)
+}
+
+// PatchBook is the handler for changing only the fields of a book that are sent in the request
+func (handler Handler) PatchBook(w http.ResponseWriter, request *http.Request) {
+	handler.updateBook(w, request, true)
+}
+
+// updateBook applies the request body to a stored book. A partial update decodes the body on top
+// of the stored book, so any field left out of the request keeps its current value. Only the title,
+// authors and shelf are saved.
+func (handler Handler) updateBook(w http.ResponseWriter, request *http.Request, partial bool) {
+	id, err := parseID(request)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue parsing book id", zap.Error(err))
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	var existing models.Book
+	err = handler.Repository.Read(request.Context(), &existing, idFilter(id))
+	if handler.Repository.IsNotFoundError(err) {
+		response.NotFound(w, fmt.Sprintf("book %s not found", id.Hex()))
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue retrieving book", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	byteData, err := io.ReadAll(request.Body)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue reading request body", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	var book models.Book
+	if partial {
+		book = existing
+	}
+
+	err = json.Unmarshal(byteData, &book)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue unmarshalling json", zap.Error(err))
+		response.InvalidJSON(w, err)
+		return
+	}
+
+	// Circulation is only changed through the checkout and return actions, so the stored values are
+	// validated alongside the request
+	book.CheckedOut = existing.CheckedOut
+	book.CheckedOutBy = existing.CheckedOutBy
+
+	if err = book.Validate(); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	book.Authors, err = handler.resolveAuthors(request.Context(), book.Authors)
+	if errors.Is(err, errUnknownAuthor) {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue saving authors", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	// Only the fields people edit are set, so a checkout or return made since the book was read
+	// isn't reverted
+	var saved models.Book
+	err = handler.Repository.UpdateFields(request.Context(), &saved, idFilter(id), bson.D{
+		{Key: "title", Value: book.Title},
+		{Key: "authors", Value: book.Authors},
+		{Key: "shelf", Value: book.Shelf},
+	})
+	if handler.Repository.IsNotFoundError(err) {
+		response.NotFound(w, fmt.Sprintf("book %s not found", id.Hex()))
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue updating book", zap.Error(err))
+		response.Error(w, err)
+		return
+	}
+
+	response.SuccessResponse(w, &saved)
+	return
+}
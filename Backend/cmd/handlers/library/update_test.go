@@ -0,0 +1,151 @@
+package library
+
+import (
+	"Home-Intranet-v2-Backend/cmd/handlers/handlertest"
+	"Home-Intranet-v2-Backend/internal/library/models"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHandler_UpdateBook(t *testing.T) {
+	checkedOutTime := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		method      string
+		body        string
+		missing     bool
+		wantCode    int
+		inspectBook func(t *testing.T, book models.Book)
+	}{
+		{
+			name:     "Put replaces the book but not its circulation",
+			method:   http.MethodPut,
+			body:     `{"title": "The Hobbit", "authors": [{"last_name": "Tolkien"}], "checked_out": false, "checked_out_by": ""}`,
+			wantCode: http.StatusOK,
+			inspectBook: func(t *testing.T, book models.Book) {
+				if book.Title != "The Hobbit" || book.Shelf != "" {
+					t.Errorf("UpdateBook book = %+v, want title replaced and shelf cleared", book)
+				}
+
+				if !book.CheckedOut || book.CheckedOutBy != "Sam" {
+					t.Errorf("UpdateBook changed circulation to %v %v", book.CheckedOut, book.CheckedOutBy)
+				}
+			},
+		},
+		{
+			name:     "Patch only changes the fields sent",
+			method:   http.MethodPatch,
+			body:     `{"title": "The Hobbit"}`,
+			wantCode: http.StatusOK,
+			inspectBook: func(t *testing.T, book models.Book) {
+				if book.Title != "The Hobbit" || book.Shelf != "12" {
+					t.Errorf("PatchBook book = %+v, want title changed and shelf kept", book)
+				}
+			},
+		},
+		{
+			name:     "Patch is validated with the stored fields",
+			method:   http.MethodPatch,
+			body:     `{"title": "", "shelf": "the top shelf of the hall bookcase"}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "Malformed json",
+			method:   http.MethodPatch,
+			body:     `{"title": `,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Missing book",
+			method:   http.MethodPut,
+			body:     `{"title": "The Hobbit"}`,
+			missing:  true,
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandler()
+			book := createTestBook(t, handler, models.Book{
+				Title:          "The Hobit",
+				Authors:        []models.Author{{LastName: "Tolkien"}},
+				Shelf:          "12",
+				CheckedOut:     true,
+				CheckedOutBy:   "Sam",
+				CheckedOutTime: checkedOutTime,
+			})
+
+			id := book.ID.Hex()
+			if tt.missing {
+				id = primitive.NewObjectID().Hex()
+			}
+
+			handlerFunc := handler.UpdateBook
+			if tt.method == http.MethodPatch {
+				handlerFunc = handler.PatchBook
+			}
+
+			rec := handlertest.Serve(t, handlerFunc, tt.method, "/v1/books/{id}", "/v1/books/"+id, tt.body)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("UpdateBook status code = %v, want: %v, body: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+
+			if tt.inspectBook != nil {
+				var got models.Book
+				handlertest.DecodeData(t, rec, &got)
+
+				if got.ID != book.ID || !got.CreatedAt.Equal(book.CreatedAt.Truncate(time.Millisecond)) {
+					t.Errorf("UpdateBook changed id or created_at: %+v", got)
+				}
+
+				tt.inspectBook(t, got)
+			}
+		})
+	}
+}
+
+// checkoutAfterRead checks the book out as soon as it has been read, standing in for a checkout
+// that lands while an update is in progress
+type checkoutAfterRead struct {
+	repository.Repository
+}
+
+func (repo checkoutAfterRead) Read(ctx context.Context, model interface{}, filter interface{}) error {
+	if err := repo.Repository.Read(ctx, model, filter); err != nil {
+		return err
+	}
+
+	var book models.Book
+	return repo.Repository.UpdateFields(ctx, &book, filter, bson.D{
+		{Key: "checked_out", Value: true},
+		{Key: "checked_out_by", Value: "Sam"},
+	})
+}
+
+func TestHandler_UpdateBook_concurrentCheckout(t *testing.T) {
+	handler := newTestHandler()
+	book := createTestBook(t, handler, models.Book{Title: "The Hobit", Authors: []models.Author{{LastName: "Tolkien"}}})
+
+	handler.Repository = checkoutAfterRead{Repository: handler.Repository}
+
+	rec := handlertest.Serve(t, handler.UpdateBook, http.MethodPut, "/v1/books/{id}", "/v1/books/"+book.ID.Hex(), `{"title": "The Hobbit", "authors": [{"last_name": "Tolkien"}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("UpdateBook status code = %v, want: %v, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var got models.Book
+	handlertest.DecodeData(t, rec, &got)
+
+	if got.Title != "The Hobbit" || !got.CheckedOut || got.CheckedOutBy != "Sam" {
+		t.Errorf("UpdateBook book = %+v, want the new title and the checkout kept", got)
+	}
+}
@@ -0,0 +1,137 @@
+// Package users contains the controllers for user accounts and logging in and out
+package users
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"Home-Intranet-v2-Backend/internal/platform/validation"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// createAPIKeyRequest is the body accepted when creating an API key
+type createAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// createdAPIKey is sent back when an API key is created. The key itself is only stored as a hash,
+// so this is the only time it can be read.
+type createdAPIKey struct {
+	models.APIKey
+	Key string `json:"key"`
+}
+
+// ListAPIKeys returns every API key, ordered by name
+func (handler Handler) ListAPIKeys(w http.ResponseWriter, request *http.Request) {
+	keys, err := repository.List[models.APIKey](request.Context(), handler.Repository, bson.D{}, repository.Sort{repository.Asc("name")}, 0, 0)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue retrieving api keys", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	response.SuccessResponse(w, keys)
+	return
+}
+
+// CreateAPIKey is the handler for creating an API key, which acts as the user creating it. A key
+// can only be given scopes its creator has, and a key made with another key only scopes that key
+// has, so no key can be used to make a broader one.
+func (handler Handler) CreateAPIKey(w http.ResponseWriter, request *http.Request) {
+	user, ok := auth.UserFromContext(request.Context())
+	if !ok {
+		response.Unauthorized(w, "log in to continue")
+		return
+	}
+
+	byteData, err := io.ReadAll(request.Body)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue reading request body", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	var create createAPIKeyRequest
+	err = json.Unmarshal(byteData, &create)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue unmarshalling json", zap.Error(err))
+		response.InvalidJSON(w, err)
+		return
+	}
+
+	for _, scope := range create.Scopes {
+		permission := auth.Permission(scope)
+
+		// Scopes that aren't permissions are reported along with the other invalid fields
+		if !slices.Contains(auth.Permissions, permission) {
+			continue
+		}
+
+		if !auth.HasPermission(user, permission) {
+			logger.FromContext(request.Context()).Warn("Scope denied",
+				zap.String("role", user.Role), zap.String("scope", scope))
+			response.Forbidden(w, fmt.Sprintf("the api key can't have the %s scope, which the %s role doesn't have", scope, user.Role))
+			return
+		}
+
+		if current, ok := auth.APIKeyFromContext(request.Context()); ok && !current.HasScope(scope) {
+			logger.FromContext(request.Context()).Warn("Scope denied",
+				zap.String("api_key", current.Prefix), zap.String("scope", scope))
+			response.Forbidden(w, fmt.Sprintf("the api key can't have the %s scope, which the %s API key doesn't have", scope, current.Name))
+			return
+		}
+	}
+
+	token, key, err := handler.APIKeys.Create(request.Context(), user, create.Name, create.Scopes)
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		response.Error(w, err)
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue creating api key", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	response.SuccessResponse(w, &createdAPIKey{APIKey: key, Key: token})
+	return
+}
+
+// RevokeAPIKey is the handler for deleting an API key, which stops it working straight away
+func (handler Handler) RevokeAPIKey(w http.ResponseWriter, request *http.Request) {
+	id, err := primitive.ObjectIDFromHex(chi.URLParam(request, "id"))
+	if err != nil {
+		response.BadRequest(w, fmt.Sprintf("invalid id %q", chi.URLParam(request, "id")))
+		return
+	}
+
+	err = handler.Repository.Delete(request.Context(), &models.APIKey{}, bson.D{{Key: "_id", Value: id}})
+	if handler.Repository.IsNotFoundError(err) {
+		response.NotFound(w, fmt.Sprintf("api key %s not found", id.Hex()))
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue revoking api key", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	response.SuccessResponse(w, id.Hex())
+	return
+}
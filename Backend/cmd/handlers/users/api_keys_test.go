@@ -0,0 +1,234 @@
+package users
+
+import (
+	"Home-Intranet-v2-Backend/cmd/handlers/handlertest"
+	"Home-Intranet-v2-Backend/cmd/routers/middlewares"
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHandler_CreateAPIKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantCode   int
+		wantFields []string
+	}{
+		{
+			name:     "New key",
+			body:     `{"name": "Shelf scanner", "scopes": ["library:read", "library:edit"]}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:       "Every invalid field is reported",
+			body:       `{"name": "", "scopes": ["library:read", "lights:on"]}`,
+			wantCode:   http.StatusUnprocessableEntity,
+			wantFields: []string{"name", "scopes.1"},
+		},
+		{
+			name:     "Invalid json",
+			body:     `{"name": `,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandler(t)
+			token := login(t, handler)
+
+			rec := serve(t, handler, handler.CreateAPIKey, http.MethodPost, tt.body, token)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("CreateAPIKey status code = %v, want: %v, body: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+
+			if tt.wantFields != nil {
+				var problem response.Problem
+				if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+					t.Fatalf("Failed to parse problem details: %v", err)
+				}
+
+				gotFields := []string{}
+				for _, field := range problem.Errors {
+					gotFields = append(gotFields, field.Field)
+				}
+
+				if !reflect.DeepEqual(gotFields, tt.wantFields) {
+					t.Errorf("CreateAPIKey fields = %v, want: %v", gotFields, tt.wantFields)
+				}
+			}
+
+			if tt.wantCode == http.StatusOK {
+				var created struct {
+					models.APIKey
+					Key string `json:"key"`
+				}
+				handlertest.DecodeData(t, rec, &created)
+
+				if created.ID.IsZero() || !strings.HasPrefix(created.Key, created.Prefix) || created.Name != "Shelf scanner" {
+					t.Errorf("CreateAPIKey got = %+v, want a stored key named Shelf scanner", created)
+				}
+
+				if strings.Contains(rec.Body.String(), auth.HashToken(created.Key)) {
+					t.Error("CreateAPIKey response contains the key's hash")
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_CreateAPIKey_scopes(t *testing.T) {
+	handler := newTestHandler(t)
+	ctx := context.Background()
+
+	grace := models.User{Username: "grace", Role: models.RoleMember}
+	if err := handler.Repository.Create(ctx, &grace); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	member, _, err := handler.Sessions.Start(ctx, grace)
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+
+	rec := serve(t, handler, handler.CreateAPIKey, http.MethodPost, `{"name": "Key manager", "scopes": ["apikeys:manage"]}`, login(t, handler))
+
+	var manager struct {
+		models.APIKey
+		Key string `json:"key"`
+	}
+	handlertest.DecodeData(t, rec, &manager)
+
+	tests := []struct {
+		name     string
+		body     string
+		token    string
+		key      string
+		wantCode int
+	}{
+		{
+			name:     "Key with the scopes of the key it is made with",
+			body:     `{"name": "Another manager", "scopes": ["apikeys:manage"]}`,
+			key:      manager.Key,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Key broader than the key it is made with",
+			body:     `{"name": "Escalated", "scopes": ["apikeys:manage", "users:manage"]}`,
+			key:      manager.Key,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "Key with the permissions of the role",
+			body:     `{"name": "Reader", "scopes": ["library:read", "library:borrow"]}`,
+			token:    member,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Key broader than the role",
+			body:     `{"name": "Editor", "scopes": ["library:read", "library:edit"]}`,
+			token:    member,
+			wantCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.token != "" {
+				request.AddCookie(&http.Cookie{Name: auth.CookieName, Value: tt.token})
+			}
+
+			if tt.key != "" {
+				request.Header.Set("Authorization", "Bearer "+tt.key)
+			}
+
+			rec := httptest.NewRecorder()
+			middlewares.Authenticate(handler.Sessions, handler.APIKeys)(http.HandlerFunc(handler.CreateAPIKey)).ServeHTTP(rec, request)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("CreateAPIKey status code = %v, want: %v, body: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_ListAPIKeys(t *testing.T) {
+	handler := newTestHandler(t)
+	token := login(t, handler)
+
+	serve(t, handler, handler.CreateAPIKey, http.MethodPost, `{"name": "Shelf scanner", "scopes": ["library:edit"]}`, token)
+	serve(t, handler, handler.CreateAPIKey, http.MethodPost, `{"name": "Home Assistant", "scopes": ["library:read"]}`, token)
+
+	rec := serve(t, handler, handler.ListAPIKeys, http.MethodGet, "", token)
+
+	var keys []models.APIKey
+	handlertest.DecodeData(t, rec, &keys)
+
+	var names []string
+	for _, key := range keys {
+		names = append(names, key.Name)
+	}
+
+	if want := []string{"Home Assistant", "Shelf scanner"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ListAPIKeys names = %v, want: %v", names, want)
+	}
+}
+
+func TestHandler_RevokeAPIKey(t *testing.T) {
+	handler := newTestHandler(t)
+	token := login(t, handler)
+
+	rec := serve(t, handler, handler.CreateAPIKey, http.MethodPost, `{"name": "Shelf scanner", "scopes": ["library:read"]}`, token)
+
+	var created struct {
+		models.APIKey
+		Key string `json:"key"`
+	}
+	handlertest.DecodeData(t, rec, &created)
+
+	tests := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{name: "Revoke a key", id: created.ID.Hex(), wantCode: http.StatusOK},
+		{name: "Already revoked", id: created.ID.Hex(), wantCode: http.StatusNotFound},
+		{name: "Unknown key", id: primitive.NewObjectID().Hex(), wantCode: http.StatusNotFound},
+		{name: "Invalid id", id: "not-an-id", wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := chi.NewRouter()
+			router.Use(middlewares.Authenticate(handler.Sessions, handler.APIKeys))
+			router.Delete("/v1/api-keys/{id}", handler.RevokeAPIKey)
+
+			request := httptest.NewRequest(http.MethodDelete, "/v1/api-keys/"+tt.id, nil)
+			request.AddCookie(&http.Cookie{Name: auth.CookieName, Value: token})
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, request)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("RevokeAPIKey status code = %v, want: %v, body: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+		})
+	}
+
+	if _, _, err := handler.APIKeys.Lookup(context.Background(), created.Key); err == nil {
+		t.Error("Lookup of a revoked key succeeded")
+	}
+}
@@ -0,0 +1,61 @@
+// Package users contains the controllers for user accounts and logging in and out
+package users
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/auth/oidc"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"net/http"
+	"time"
+)
+
+// Handler is used to allow us to pass our data persistance objects as mocks for better testing
+type Handler struct {
+	Repository repository.Repository
+	Sessions   auth.Sessions
+	APIKeys    auth.APIKeys
+
+	// OIDC is the identity provider users can log in through, which is nil when it isn't set up
+	OIDC *oidc.Provider
+
+	// SecureCookie marks the session cookie as only to be sent over HTTPS, which should be set in
+	// production
+	SecureCookie bool
+}
+
+// setSessionCookie sends the session token to the browser. The cookie can't be read by scripts,
+// and isn't sent with requests from other sites, so a page elsewhere can't act as the user.
+func (handler Handler) setSessionCookie(w http.ResponseWriter, token string, expires time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     auth.CookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   handler.SecureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// clearSessionCookie tells the browser to forget the session token
+func (handler Handler) clearSessionCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     auth.CookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   handler.SecureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// sessionToken returns the session token the request was sent with, or an empty string
+func sessionToken(request *http.Request) string {
+	cookie, err := request.Cookie(auth.CookieName)
+	if err != nil {
+		return ""
+	}
+
+	return cookie.Value
+}
@@ -0,0 +1,72 @@
+package users
+
+import (
+	"Home-Intranet-v2-Backend/cmd/routers/middlewares"
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testPassword is the password of the user newTestHandler creates
+const testPassword = "correct horse"
+
+// newTestHandler returns a handler backed by an in memory repository holding one user, ada
+func newTestHandler(t *testing.T) Handler {
+	t.Helper()
+
+	repo := repository.NewMemoryRepository()
+
+	if err := auth.EnsureIndexes(context.Background(), repo); err != nil {
+		t.Fatalf("Failed to create indexes: %v", err)
+	}
+
+	if _, err := auth.CreateInitialUser(context.Background(), repo, "ada", testPassword); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	return Handler{
+		Repository: repo,
+		Sessions:   auth.Sessions{Repository: repo, Lifetime: time.Hour},
+		APIKeys:    auth.APIKeys{Repository: repo},
+	}
+}
+
+// serve sends a request to a handler behind the authentication middleware, with the session
+// cookie when a token is given
+func serve(t *testing.T, handler Handler, handlerFunc http.HandlerFunc, method string, body string, token string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	request := httptest.NewRequest(method, "/", strings.NewReader(body))
+	if token != "" {
+		request.AddCookie(&http.Cookie{Name: auth.CookieName, Value: token})
+	}
+
+	recorder := httptest.NewRecorder()
+	middlewares.Authenticate(handler.Sessions, handler.APIKeys)(handlerFunc).ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+// login logs in as ada, returning the session token
+func login(t *testing.T, handler Handler) string {
+	t.Helper()
+
+	rec := serve(t, handler, handler.Login, http.MethodPost, `{"username": "ada", "password": "`+testPassword+`"}`, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Login status code = %v, want: %v, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == auth.CookieName {
+			return cookie.Value
+		}
+	}
+
+	t.Fatal("Login did not set the session cookie")
+	return ""
+}
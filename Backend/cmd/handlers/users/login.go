@@ -0,0 +1,88 @@
+// Package users contains the controllers for user accounts and logging in and out
+package users
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// loginRequest is the body accepted when logging in
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// loginResponse is the user that logged in and when their session ends
+type loginResponse struct {
+	User      models.User `json:"user"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+// Login is the handler for logging in with a username and password. The session token is sent
+// back as a cookie.
+func (handler Handler) Login(w http.ResponseWriter, request *http.Request) {
+	byteData, err := io.ReadAll(request.Body)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue reading request body", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	var login loginRequest
+	err = json.Unmarshal(byteData, &login)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue unmarshalling json", zap.Error(err))
+		response.InvalidJSON(w, err)
+		return
+	}
+
+	user, err := handler.Sessions.Authenticate(request.Context(), login.Username, login.Password)
+	if errors.Is(err, auth.ErrInvalidCredentials) {
+		logger.FromContext(request.Context()).Warn("Failed login", zap.String("username", models.NormalizeUsername(login.Username)))
+		response.Unauthorized(w, err.Error())
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue checking credentials", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	token, session, err := handler.Sessions.Start(request.Context(), user)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue starting session", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	handler.setSessionCookie(w, token, session.ExpiresAt)
+
+	response.SuccessResponse(w, loginResponse{User: user, ExpiresAt: session.ExpiresAt})
+	return
+}
+
+// Logout is the handler for ending the session the request was sent with
+func (handler Handler) Logout(w http.ResponseWriter, request *http.Request) {
+	if token := sessionToken(request); token != "" {
+		if err := handler.Sessions.End(request.Context(), token); err != nil {
+			logger.FromContext(request.Context()).Error("Issue ending session", zap.Error(err))
+			response.InternalServerError(w, err)
+			return
+		}
+	}
+
+	handler.clearSessionCookie(w)
+
+	response.SuccessResponse(w, "logged out")
+	return
+}
@@ -0,0 +1,85 @@
+package users
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth"
+	"net/http"
+	"testing"
+)
+
+func TestHandler_Login(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantCode   int
+		wantCookie bool
+	}{
+		{
+			name:       "Valid credentials",
+			body:       `{"username": "Ada", "password": "correct horse"}`,
+			wantCode:   http.StatusOK,
+			wantCookie: true,
+		},
+		{
+			name:     "Wrong password",
+			body:     `{"username": "ada", "password": "battery staple"}`,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "Unknown user",
+			body:     `{"username": "grace", "password": "correct horse"}`,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "Malformed json",
+			body:     `{"username": "ada"`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandler(t)
+
+			rec := serve(t, handler, handler.Login, http.MethodPost, tt.body, "")
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("Login status code = %v, want: %v, body: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+
+			var cookie *http.Cookie
+			for _, c := range rec.Result().Cookies() {
+				if c.Name == auth.CookieName {
+					cookie = c
+				}
+			}
+
+			if (cookie != nil) != tt.wantCookie {
+				t.Fatalf("Login cookie = %v, want cookie: %t", cookie, tt.wantCookie)
+			}
+
+			if cookie != nil && (!cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode || cookie.Value == "") {
+				t.Errorf("Login cookie = %+v, want an http only, same site session token", cookie)
+			}
+		})
+	}
+}
+
+func TestHandler_Logout(t *testing.T) {
+	handler := newTestHandler(t)
+	token := login(t, handler)
+
+	rec := serve(t, handler, handler.Logout, http.MethodPost, "", token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Logout status code = %v, want: %v", rec.Code, http.StatusOK)
+	}
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != auth.CookieName || cookies[0].MaxAge >= 0 {
+		t.Errorf("Logout cookies = %v, want the session cookie cleared", cookies)
+	}
+
+	rec = serve(t, handler, handler.GetCurrentUser, http.MethodGet, "", token)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("GetCurrentUser after Logout status code = %v, want: %v", rec.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,182 @@
+// Package users contains the controllers for user accounts and logging in and out
+package users
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/auth/oidc"
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"Home-Intranet-v2-Backend/internal/platform/validation"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// oidcCookieName is the cookie a login through the identity provider is kept in while the browser
+// is at the provider
+const oidcCookieName = "oidc_login"
+
+// oidcLoginLifetime is how long the browser has to log in at the identity provider
+const oidcLoginLifetime = 10 * time.Minute
+
+// StartOIDCLogin sends the browser to the identity provider to log in
+func (handler Handler) StartOIDCLogin(w http.ResponseWriter, request *http.Request) {
+	handler.startOIDC(w, request, "")
+	return
+}
+
+// StartOIDCLink sends the browser of a logged in user to the identity provider, so the identity
+// they log in with there is linked to their account. It is the only way an existing account gets
+// linked, since the usernames and emails the provider sends can't be trusted to name it.
+func (handler Handler) StartOIDCLink(w http.ResponseWriter, request *http.Request) {
+	user, _ := auth.UserFromContext(request.Context())
+
+	handler.startOIDC(w, request, user.ID.Hex())
+	return
+}
+
+// startOIDC keeps a new login in a cookie and sends the browser to the identity provider
+func (handler Handler) startOIDC(w http.ResponseWriter, request *http.Request, linkUserID string) {
+	login, err := oidc.NewLogin()
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue starting login", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	login.LinkUserID = linkUserID
+
+	address, err := handler.OIDC.AuthCodeURL(request.Context(), login)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue reaching identity provider", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcCookieName,
+		Value:    login.Encode(),
+		Path:     "/",
+		MaxAge:   int(oidcLoginLifetime.Seconds()),
+		HttpOnly: true,
+		Secure:   handler.SecureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, request, address, http.StatusFound)
+}
+
+// FinishOIDCLogin is where the identity provider sends the browser back to. The code it brings is
+// exchanged for an ID token, and the user it names is logged in with a session like any other. When
+// the login was started to link an account, the identity is linked to the logged in user instead.
+func (handler Handler) FinishOIDCLogin(w http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+
+	var login oidc.Login
+	cookie, err := request.Cookie(oidcCookieName)
+	if err == nil {
+		login, err = oidc.ParseLogin(cookie.Value)
+	}
+
+	// The login can only be finished once
+	handler.clearOIDCCookie(w)
+
+	if providerError := query.Get("error"); providerError != "" {
+		response.Unauthorized(w, fmt.Sprintf("the identity provider refused the login: %s", providerError))
+		return
+	}
+
+	if err != nil || subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(login.State)) != 1 {
+		response.BadRequest(w, "the login has expired or was started in another browser, try logging in again")
+		return
+	}
+
+	claims, err := handler.OIDC.Exchange(request.Context(), query.Get("code"), login)
+	if errors.Is(err, oidc.ErrLoginRejected) || errors.Is(err, oidc.ErrInvalidToken) {
+		logger.FromContext(request.Context()).Warn("Identity provider login failed", zap.Error(err))
+		response.Unauthorized(w, "the login through the identity provider could not be verified")
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue reaching identity provider", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	if login.LinkUserID != "" {
+		handler.finishOIDCLink(w, request, login, claims)
+		return
+	}
+
+	user, err := handler.OIDC.User(request.Context(), handler.Repository, claims)
+	var fields validation.Errors
+	switch {
+	case errors.Is(err, oidc.ErrNoRole):
+		logger.FromContext(request.Context()).Warn("Identity provider user has no role", zap.String("subject", claims.Subject))
+		response.Forbidden(w, "your account at the identity provider isn't allowed to use the intranet")
+		return
+	case errors.Is(err, oidc.ErrUsernameTaken):
+		response.Conflict(w, fmt.Sprintf("%v, log in with your password to link the identity provider to your account", err))
+		return
+	case errors.As(err, &fields):
+		response.Error(w, err)
+		return
+	case err != nil:
+		logger.FromContext(request.Context()).Error("Issue finding identity provider user", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	token, session, err := handler.Sessions.Start(request.Context(), user)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue starting session", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	handler.setSessionCookie(w, token, session.ExpiresAt)
+	http.Redirect(w, request, handler.OIDC.LoginRedirect(), http.StatusFound)
+	return
+}
+
+// finishOIDCLink links the identity in the claims to the user who started the link, who has to
+// still be the one logged in
+func (handler Handler) finishOIDCLink(w http.ResponseWriter, request *http.Request, login oidc.Login, claims oidc.Claims) {
+	user, ok := auth.UserFromContext(request.Context())
+	if !ok || user.ID.Hex() != login.LinkUserID {
+		response.BadRequest(w, "the link was started by another user, log in and try linking again")
+		return
+	}
+
+	_, err := handler.OIDC.Link(request.Context(), handler.Repository, user, claims)
+	if errors.Is(err, oidc.ErrSubjectLinked) {
+		response.Conflict(w, err.Error())
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue linking identity provider user", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	http.Redirect(w, request, handler.OIDC.LoginRedirect(), http.StatusFound)
+}
+
+// clearOIDCCookie tells the browser to forget a login through the identity provider
+func (handler Handler) clearOIDCCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   handler.SecureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,260 @@
+package users
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"Home-Intranet-v2-Backend/internal/auth/oidc"
+	"Home-Intranet-v2-Backend/internal/auth/oidc/oidctest"
+	"Home-Intranet-v2-Backend/internal/platform/config"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newOIDCTestHandler returns a test handler that logs in through a stand-in identity provider
+func newOIDCTestHandler(t *testing.T) (Handler, *oidctest.Provider) {
+	t.Helper()
+
+	stand := oidctest.NewProvider(t, "trove", "s3cret")
+
+	cfg := config.Default().Auth.OIDC
+	cfg.Issuer = stand.Issuer()
+	cfg.ClientID = "trove"
+	cfg.ClientSecret = "s3cret"
+	cfg.RedirectURL = "https://api-trove.intranet.dev/v1/auth/oidc/callback"
+	cfg.MemberValues = "family"
+	cfg.LoginRedirect = "https://trove.intranet.dev/"
+
+	handler := newTestHandler(t)
+	handler.OIDC = oidc.New(cfg, stand.Client())
+
+	return handler, stand
+}
+
+// startOIDCLogin starts a login, returning the login cookie and where the provider sends the
+// browser back to
+func startOIDCLogin(t *testing.T, handler Handler, stand *oidctest.Provider) (*http.Cookie, *url.URL) {
+	t.Helper()
+
+	return startOIDC(t, handler.StartOIDCLogin, httptest.NewRequest(http.MethodGet, "/v1/auth/oidc/login", nil), stand)
+}
+
+// startOIDC runs the handler starting a login, returning the login cookie and where the provider
+// sends the browser back to
+func startOIDC(t *testing.T, start http.HandlerFunc, request *http.Request, stand *oidctest.Provider) (*http.Cookie, *url.URL) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	start(rec, request)
+
+	if rec.Code != http.StatusFound {
+		t.Fatalf("StartOIDCLogin status code = %v, want: %v, body: %s", rec.Code, http.StatusFound, rec.Body.String())
+	}
+
+	var cookie *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == oidcCookieName {
+			cookie = c
+		}
+	}
+
+	if cookie == nil || !cookie.HttpOnly {
+		t.Fatalf("StartOIDCLogin cookie = %v, want an HttpOnly login cookie", cookie)
+	}
+
+	return cookie, stand.Authorize(t, rec.Header().Get("Location"))
+}
+
+func TestHandler_OIDCLogin(t *testing.T) {
+	tests := []struct {
+		name         string
+		groups       []string
+		callback     func(callback *url.URL) string
+		noCookie     bool
+		wantCode     int
+		wantLocation string
+	}{
+		{
+			name:         "Member logs in",
+			groups:       []string{"family"},
+			callback:     func(callback *url.URL) string { return callback.RawQuery },
+			wantCode:     http.StatusFound,
+			wantLocation: "https://trove.intranet.dev/",
+		},
+		{
+			name:     "No role",
+			groups:   []string{"neighbours"},
+			callback: func(callback *url.URL) string { return callback.RawQuery },
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:   "State from another login",
+			groups: []string{"family"},
+			callback: func(callback *url.URL) string {
+				return "code=" + callback.Query().Get("code") + "&state=another"
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Started in another browser",
+			groups:   []string{"family"},
+			callback: func(callback *url.URL) string { return callback.RawQuery },
+			noCookie: true,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Refused by the provider",
+			groups:   []string{"family"},
+			callback: func(callback *url.URL) string { return "error=access_denied&state=" + callback.Query().Get("state") },
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, stand := newOIDCTestHandler(t)
+			stand.Subject = "subject-grace"
+			stand.Claims["preferred_username"] = "Grace"
+			stand.Claims["name"] = "Grace Hopper"
+			stand.Claims["groups"] = tt.groups
+
+			cookie, callback := startOIDCLogin(t, handler, stand)
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/auth/oidc/callback?"+tt.callback(callback), nil)
+			if !tt.noCookie {
+				request.AddCookie(cookie)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.FinishOIDCLogin(rec, request)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("FinishOIDCLogin status code = %v, want: %v, body: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+
+			if tt.wantCode != http.StatusFound {
+				return
+			}
+
+			if location := rec.Header().Get("Location"); location != tt.wantLocation {
+				t.Errorf("FinishOIDCLogin location = %v, want: %v", location, tt.wantLocation)
+			}
+
+			var token string
+			for _, c := range rec.Result().Cookies() {
+				if c.Name == auth.CookieName {
+					token = c.Value
+				}
+			}
+
+			user, err := handler.Sessions.Lookup(context.Background(), token)
+			if err != nil {
+				t.Fatalf("Lookup of the new session error = %v", err)
+			}
+
+			if user.Username != "grace" || user.DisplayName != "Grace Hopper" || user.Role != models.RoleMember {
+				t.Errorf("FinishOIDCLogin user = %+v, want grace as a member", user)
+			}
+		})
+	}
+}
+
+func TestHandler_FinishOIDCLogin_codeUsedTwice(t *testing.T) {
+	handler, stand := newOIDCTestHandler(t)
+	stand.Claims["groups"] = []string{"family"}
+
+	cookie, callback := startOIDCLogin(t, handler, stand)
+
+	for i, wantCode := range []int{http.StatusFound, http.StatusUnauthorized} {
+		request := httptest.NewRequest(http.MethodGet, "/v1/auth/oidc/callback?"+callback.RawQuery, nil)
+		request.AddCookie(cookie)
+
+		rec := httptest.NewRecorder()
+		handler.FinishOIDCLogin(rec, request)
+
+		if rec.Code != wantCode {
+			t.Errorf("FinishOIDCLogin attempt %d status code = %v, want: %v, body: %s", i+1, rec.Code, wantCode, strings.TrimSpace(rec.Body.String()))
+		}
+	}
+}
+
+func TestHandler_OIDCLink(t *testing.T) {
+	handler, stand := newOIDCTestHandler(t)
+	stand.Subject = "subject-grace"
+	stand.Claims["preferred_username"] = "Grace"
+	stand.Claims["groups"] = []string{"family"}
+
+	grace := models.User{Username: "grace", Role: models.RoleAdmin}
+	if err := grace.SetPassword("correct horse"); err != nil {
+		t.Fatalf("Failed to set password: %v", err)
+	}
+
+	grace, err := repository.Create(context.Background(), handler.Repository, grace)
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	other := models.User{Model: repository.Model{ID: primitive.NewObjectID()}, Username: "ada", Role: models.RoleMember}
+
+	// finish sends the browser back from the provider, logged in as user when there is one
+	finish := func(cookie *http.Cookie, callback *url.URL, user *models.User) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/v1/auth/oidc/callback?"+callback.RawQuery, nil)
+		request.AddCookie(cookie)
+		if user != nil {
+			request = request.WithContext(auth.WithUser(request.Context(), *user))
+		}
+
+		rec := httptest.NewRecorder()
+		handler.FinishOIDCLogin(rec, request)
+
+		return rec
+	}
+
+	link := func(user models.User) (*http.Cookie, *url.URL) {
+		request := httptest.NewRequest(http.MethodGet, "/v1/auth/oidc/link", nil)
+		return startOIDC(t, handler.StartOIDCLink, request.WithContext(auth.WithUser(request.Context(), user)), stand)
+	}
+
+	cookie, callback := startOIDCLogin(t, handler, stand)
+	if rec := finish(cookie, callback, nil); rec.Code != http.StatusConflict {
+		t.Fatalf("FinishOIDCLogin with a taken username status code = %v, want: %v", rec.Code, http.StatusConflict)
+	}
+
+	cookie, callback = link(grace)
+	if rec := finish(cookie, callback, &other); rec.Code != http.StatusBadRequest {
+		t.Fatalf("FinishOIDCLogin of a link by another user status code = %v, want: %v", rec.Code, http.StatusBadRequest)
+	}
+
+	cookie, callback = link(grace)
+	if rec := finish(cookie, callback, &grace); rec.Code != http.StatusFound {
+		t.Fatalf("FinishOIDCLogin of a link status code = %v, want: %v, body: %s", rec.Code, http.StatusFound, rec.Body.String())
+	}
+
+	cookie, callback = startOIDCLogin(t, handler, stand)
+	rec := finish(cookie, callback, nil)
+	if rec.Code != http.StatusFound {
+		t.Fatalf("FinishOIDCLogin after linking status code = %v, want: %v, body: %s", rec.Code, http.StatusFound, rec.Body.String())
+	}
+
+	var token string
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == auth.CookieName {
+			token = c.Value
+		}
+	}
+
+	user, err := handler.Sessions.Lookup(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Lookup of the new session error = %v", err)
+	}
+
+	if user.ID != grace.ID || user.Role != models.RoleAdmin {
+		t.Errorf("FinishOIDCLogin user = %+v, want grace, still an admin", user)
+	}
+}
@@ -0,0 +1,242 @@
+// Package users contains the controllers for user accounts and logging in and out
+package users
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"Home-Intranet-v2-Backend/internal/platform/validation"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// createUserRequest is the body accepted when adding a user. The role defaults to member.
+type createUserRequest struct {
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Password    string `json:"password"`
+	Role        string `json:"role"`
+}
+
+// roleRequest is the body accepted when changing a user's role
+type roleRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin member guest"`
+}
+
+// changePasswordRequest is the body accepted when a user changes their password
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// ListUsers returns every user, ordered by username
+func (handler Handler) ListUsers(w http.ResponseWriter, request *http.Request) {
+	users, err := repository.List[models.User](request.Context(), handler.Repository, bson.D{}, repository.Sort{repository.Asc("username")}, 0, 0)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue retrieving users", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	response.SuccessResponse(w, users)
+	return
+}
+
+// CreateUser is the handler for adding a user to the household
+func (handler Handler) CreateUser(w http.ResponseWriter, request *http.Request) {
+	byteData, err := io.ReadAll(request.Body)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue reading request body", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	var create createUserRequest
+	err = json.Unmarshal(byteData, &create)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue unmarshalling json", zap.Error(err))
+		response.InvalidJSON(w, err)
+		return
+	}
+
+	user := models.User{
+		Username:    models.NormalizeUsername(create.Username),
+		DisplayName: create.DisplayName,
+		Role:        create.Role,
+	}
+
+	if user.Role == "" {
+		user.Role = models.RoleMember
+	}
+
+	// Report the password along with the other fields, rather than one after the other
+	var fields validation.Errors
+	for _, err = range []error{user.Validate(), models.ValidatePassword("password", create.Password)} {
+		var errs validation.Errors
+		if errors.As(err, &errs) {
+			fields = append(fields, errs...)
+		}
+	}
+
+	if err = fields.Err(); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	if err = user.SetPassword(create.Password); err != nil {
+		logger.FromContext(request.Context()).Error("Issue setting password", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	// The unique index on usernames refuses a taken one, even when two requests race for it
+	err = handler.Repository.Create(request.Context(), &user)
+	if errors.Is(err, repository.ErrDuplicateKey) {
+		response.Conflict(w, fmt.Sprintf("username %s is taken", user.Username))
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue creating user", zap.Error(err))
+		response.Error(w, err)
+		return
+	}
+
+	response.SuccessResponse(w, &user)
+	return
+}
+
+// GetCurrentUser returns the user the request was made by
+func (handler Handler) GetCurrentUser(w http.ResponseWriter, request *http.Request) {
+	user, ok := auth.UserFromContext(request.Context())
+	if !ok {
+		response.Unauthorized(w, "log in to continue")
+		return
+	}
+
+	response.SuccessResponse(w, &user)
+	return
+}
+
+// ChangePassword is the handler for a user changing their own password. Every other session of
+// the user is logged out, so a password that was known to someone else stops working everywhere.
+func (handler Handler) ChangePassword(w http.ResponseWriter, request *http.Request) {
+	user, ok := auth.UserFromContext(request.Context())
+	if !ok {
+		response.Unauthorized(w, "log in to continue")
+		return
+	}
+
+	byteData, err := io.ReadAll(request.Body)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue reading request body", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	var change changePasswordRequest
+	err = json.Unmarshal(byteData, &change)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue unmarshalling json", zap.Error(err))
+		response.InvalidJSON(w, err)
+		return
+	}
+
+	if !user.CheckPassword(change.CurrentPassword) {
+		response.Error(w, validation.Errors{{Field: "current_password", Code: "incorrect", Message: "is incorrect"}})
+		return
+	}
+
+	if err = models.ValidatePassword("new_password", change.NewPassword); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	if err = user.SetPassword(change.NewPassword); err != nil {
+		logger.FromContext(request.Context()).Error("Issue setting password", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	var updated models.User
+	err = handler.Repository.UpdateFields(request.Context(), &updated, bson.D{{Key: "_id", Value: user.ID}}, bson.D{
+		{Key: "password_hash", Value: user.PasswordHash},
+	})
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue updating password", zap.Error(err))
+		response.Error(w, err)
+		return
+	}
+
+	if err = handler.Sessions.EndOthers(request.Context(), user.ID, sessionToken(request)); err != nil {
+		logger.FromContext(request.Context()).Error("Issue ending other sessions", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	response.SuccessResponse(w, &updated)
+	return
+}
+
+// UpdateUserRole is the handler for changing what a user is allowed to do. Admins can't change
+// their own role, so the last admin can't lock everyone out by mistake.
+func (handler Handler) UpdateUserRole(w http.ResponseWriter, request *http.Request) {
+	id, err := primitive.ObjectIDFromHex(chi.URLParam(request, "id"))
+	if err != nil {
+		response.BadRequest(w, fmt.Sprintf("invalid id %q", chi.URLParam(request, "id")))
+		return
+	}
+
+	if current, ok := auth.UserFromContext(request.Context()); ok && current.ID == id {
+		response.Conflict(w, "you can't change your own role")
+		return
+	}
+
+	byteData, err := io.ReadAll(request.Body)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue reading request body", zap.Error(err))
+		response.InternalServerError(w, err)
+		return
+	}
+
+	var change roleRequest
+	err = json.Unmarshal(byteData, &change)
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue unmarshalling json", zap.Error(err))
+		response.InvalidJSON(w, err)
+		return
+	}
+
+	if err = validation.Struct(change).Err(); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	var user models.User
+	err = handler.Repository.UpdateFields(request.Context(), &user, bson.D{{Key: "_id", Value: id}}, bson.D{
+		{Key: "role", Value: change.Role},
+	})
+	if handler.Repository.IsNotFoundError(err) {
+		response.NotFound(w, fmt.Sprintf("user %s not found", id.Hex()))
+		return
+	}
+
+	if err != nil {
+		logger.FromContext(request.Context()).Error("Issue updating role", zap.Error(err))
+		response.Error(w, err)
+		return
+	}
+
+	response.SuccessResponse(w, &user)
+	return
+}
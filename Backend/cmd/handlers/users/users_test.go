@@ -0,0 +1,304 @@
+package users
+
+import (
+	"Home-Intranet-v2-Backend/cmd/handlers/handlertest"
+	"Home-Intranet-v2-Backend/cmd/routers/middlewares"
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHandler_CreateUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantCode   int
+		wantFields []string
+	}{
+		{
+			name:     "New user",
+			body:     `{"username": "Grace", "display_name": "Grace Hopper", "password": "another password"}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:       "Unknown role",
+			body:       `{"username": "grace", "password": "another password", "role": "owner"}`,
+			wantCode:   http.StatusUnprocessableEntity,
+			wantFields: []string{"role"},
+		},
+		{
+			name:     "Username taken",
+			body:     `{"username": "ADA", "password": "another password"}`,
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:       "Every invalid field is reported",
+			body:       `{"username": " ", "password": "short"}`,
+			wantCode:   http.StatusUnprocessableEntity,
+			wantFields: []string{"username", "password"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandler(t)
+			token := login(t, handler)
+
+			rec := serve(t, handler, handler.CreateUser, http.MethodPost, tt.body, token)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("CreateUser status code = %v, want: %v, body: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+
+			if tt.wantFields != nil {
+				var problem response.Problem
+				if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+					t.Fatalf("Failed to parse problem details: %v", err)
+				}
+
+				gotFields := []string{}
+				for _, field := range problem.Errors {
+					gotFields = append(gotFields, field.Field)
+				}
+
+				if !reflect.DeepEqual(gotFields, tt.wantFields) {
+					t.Errorf("CreateUser fields = %v, want: %v", gotFields, tt.wantFields)
+				}
+			}
+
+			if tt.wantCode == http.StatusOK {
+				var user models.User
+				handlertest.DecodeData(t, rec, &user)
+
+				if user.ID.IsZero() || user.Username != "grace" || user.Role != models.RoleMember {
+					t.Errorf("CreateUser got = %+v, want a stored member named grace", user)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_CreateUser_concurrent(t *testing.T) {
+	handler := newTestHandler(t)
+	token := login(t, handler)
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- serve(t, handler, handler.CreateUser, http.MethodPost, `{"username": "grace", "password": "another password"}`, token).Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	created := 0
+	for code := range codes {
+		switch code {
+		case http.StatusOK:
+			created++
+		case http.StatusConflict:
+		default:
+			t.Errorf("CreateUser status code = %v, want: %v or %v", code, http.StatusOK, http.StatusConflict)
+		}
+	}
+
+	if created != 1 {
+		t.Errorf("CreateUser created grace %d times, want: 1", created)
+	}
+}
+
+func TestHandler_ListUsers(t *testing.T) {
+	handler := newTestHandler(t)
+	token := login(t, handler)
+
+	serve(t, handler, handler.CreateUser, http.MethodPost, `{"username": "grace", "password": "another password"}`, token)
+
+	rec := serve(t, handler, handler.ListUsers, http.MethodGet, "", token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ListUsers status code = %v, want: %v", rec.Code, http.StatusOK)
+	}
+
+	var users []models.User
+	handlertest.DecodeData(t, rec, &users)
+
+	if len(users) != 2 || users[0].Username != "ada" || users[1].Username != "grace" {
+		t.Errorf("ListUsers got = %+v, want ada and grace", users)
+	}
+}
+
+func TestHandler_GetCurrentUser(t *testing.T) {
+	handler := newTestHandler(t)
+	token := login(t, handler)
+
+	tests := []struct {
+		name     string
+		token    string
+		wantCode int
+	}{
+		{name: "Logged in", token: token, wantCode: http.StatusOK},
+		{name: "Anonymous", token: "", wantCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(t, handler, handler.GetCurrentUser, http.MethodGet, "", tt.token)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("GetCurrentUser status code = %v, want: %v", rec.Code, tt.wantCode)
+			}
+
+			if tt.wantCode == http.StatusOK {
+				var user models.User
+				handlertest.DecodeData(t, rec, &user)
+
+				if user.Username != "ada" {
+					t.Errorf("GetCurrentUser username = %v, want: ada", user.Username)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_ChangePassword(t *testing.T) {
+	handler := newTestHandler(t)
+	token := login(t, handler)
+	other := login(t, handler)
+
+	steps := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "Wrong current password",
+			body:     `{"current_password": "battery staple", "new_password": "a new password"}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "New password too short",
+			body:     `{"current_password": "correct horse", "new_password": "short"}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "Change",
+			body:     `{"current_password": "correct horse", "new_password": "a new password"}`,
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, step := range steps {
+		rec := serve(t, handler, handler.ChangePassword, http.MethodPut, step.body, token)
+
+		if rec.Code != step.wantCode {
+			t.Fatalf("%s status code = %v, want: %v, body: %s", step.name, rec.Code, step.wantCode, rec.Body.String())
+		}
+	}
+
+	if rec := serve(t, handler, handler.GetCurrentUser, http.MethodGet, "", token); rec.Code != http.StatusOK {
+		t.Errorf("ChangePassword logged out the session it was made from, status code = %v", rec.Code)
+	}
+
+	if rec := serve(t, handler, handler.GetCurrentUser, http.MethodGet, "", other); rec.Code != http.StatusUnauthorized {
+		t.Errorf("ChangePassword left another session logged in, status code = %v", rec.Code)
+	}
+
+	rec := serve(t, handler, handler.Login, http.MethodPost, `{"username": "ada", "password": "a new password"}`, "")
+	if rec.Code != http.StatusOK {
+		t.Errorf("Login with the new password status code = %v, want: %v", rec.Code, http.StatusOK)
+	}
+}
+
+func TestHandler_UpdateUserRole(t *testing.T) {
+	handler := newTestHandler(t)
+	token := login(t, handler)
+
+	rec := serve(t, handler, handler.CreateUser, http.MethodPost, `{"username": "grace", "password": "another password"}`, token)
+
+	var grace models.User
+	handlertest.DecodeData(t, rec, &grace)
+
+	rec = serve(t, handler, handler.GetCurrentUser, http.MethodGet, "", token)
+
+	var ada models.User
+	handlertest.DecodeData(t, rec, &ada)
+
+	tests := []struct {
+		name     string
+		id       string
+		body     string
+		wantCode int
+		wantRole string
+	}{
+		{
+			name:     "Change another user's role",
+			id:       grace.ID.Hex(),
+			body:     `{"role": "guest"}`,
+			wantCode: http.StatusOK,
+			wantRole: models.RoleGuest,
+		},
+		{
+			name:     "Own role",
+			id:       ada.ID.Hex(),
+			body:     `{"role": "guest"}`,
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "Unknown role",
+			id:       grace.ID.Hex(),
+			body:     `{"role": "owner"}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "Unknown user",
+			id:       primitive.NewObjectID().Hex(),
+			body:     `{"role": "guest"}`,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Invalid id",
+			id:       "not-an-id",
+			body:     `{"role": "guest"}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := chi.NewRouter()
+			router.Use(middlewares.Authenticate(handler.Sessions, handler.APIKeys))
+			router.Put("/v1/users/{id}/role", handler.UpdateUserRole)
+
+			request := httptest.NewRequest(http.MethodPut, "/v1/users/"+tt.id+"/role", strings.NewReader(tt.body))
+			request.AddCookie(&http.Cookie{Name: auth.CookieName, Value: token})
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, request)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("UpdateUserRole status code = %v, want: %v, body: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+
+			if tt.wantRole != "" {
+				var user models.User
+				handlertest.DecodeData(t, rec, &user)
+
+				if user.Role != tt.wantRole {
+					t.Errorf("UpdateUserRole role = %v, want: %v", user.Role, tt.wantRole)
+				}
+			}
+		})
+	}
+}
@@ -3,12 +3,126 @@ package main
 
 import (
 	"Home-Intranet-v2-Backend/cmd/routers"
+	"Home-Intranet-v2-Backend/internal/audit"
+	"Home-Intranet-v2-Backend/internal/auth"
 	"Home-Intranet-v2-Backend/internal/platform/config"
-
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
 )
 
 func main() {
-	router := routers.SetupRouter()
-	http.ListenAndServe(config.GetServerHost(), router)
+	// Every setting is checked before anything starts, so a bad deployment fails with the full
+	// list of problems rather than the first one hit
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Invalid configuration", zap.Error(err))
+	}
+
+	if err = logger.Configure(cfg.Log, cfg.Server.Production); err != nil {
+		logger.Fatal("Could not configure logging", zap.Error(err))
+	}
+
+	store, err := repository.Open(cfg.DB)
+	if err != nil {
+		logger.Fatal("Could not connect to database", zap.Error(err))
+	}
+
+	// Changes are audited outside the instrumentation, so writing their entries is measured too
+	repo := audit.Record(repository.Instrument(store))
+
+	if err = auth.EnsureIndexes(context.Background(), repo); err != nil {
+		repo.Close(context.Background())
+		logger.Fatal("Could not create the database indexes", zap.Error(err))
+	}
+
+	if cfg.Auth.InitialUsername != "" {
+		created, err := auth.CreateInitialUser(context.Background(), repo, cfg.Auth.InitialUsername, cfg.Auth.InitialPassword)
+		if err != nil {
+			repo.Close(context.Background())
+			logger.Fatal("Could not create the initial user", zap.Error(err))
+		}
+
+		if created {
+			logger.Info("Created the initial user", zap.String("username", cfg.Auth.InitialUsername))
+		}
+	}
+
+	server := &http.Server{
+		Addr:         cfg.Server.Host,
+		Handler:      routers.SetupRouter(repo, cfg),
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+
+	// Binding before serving means a taken or invalid address fails straight away
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		repo.Close(context.Background())
+		logger.Fatal("Could not listen on "+server.Addr, zap.Error(err))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err = run(ctx, server, listener, repo, cfg.Server.ShutdownTimeout); err != nil {
+		logger.Fatal("Server stopped with an error", zap.Error(err))
+	}
+
+	logger.Sync()
+}
+
+// run serves requests until ctx is done, then stops taking new connections, waits up to the
+// shutdown timeout for in-flight requests to finish and closes the database
+func run(ctx context.Context, server *http.Server, listener net.Listener, repo repository.Repository, shutdownTimeout time.Duration) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	logger.Info("Server listening", zap.String("address", listener.Addr().String()))
+
+	select {
+	case err := <-serveErr:
+		// Serve only returns before a shutdown when the listener fails
+		repo.Close(context.Background())
+		return fmt.Errorf("issue serving requests: %w", err)
+	case <-ctx.Done():
+	}
+
+	logger.Info("Shutting down, waiting for in-flight requests", zap.Duration("timeout", shutdownTimeout))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	var err error
+	if shutdownErr := server.Shutdown(shutdownCtx); shutdownErr != nil {
+		// Requests still running after the timeout are cut off rather than holding up the exit
+		server.Close()
+		err = fmt.Errorf("issue draining requests: %w", shutdownErr)
+	}
+
+	closeCtx, cancelClose := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelClose()
+
+	if closeErr := repo.Close(closeCtx); closeErr != nil {
+		err = errors.Join(err, fmt.Errorf("issue closing database: %w", closeErr))
+	}
+
+	if err == nil {
+		logger.Info("Server stopped")
+	}
+
+	return err
 }
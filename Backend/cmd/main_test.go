@@ -0,0 +1,125 @@
+package main
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// closeRecorder is a repository that remembers whether it was closed
+type closeRecorder struct {
+	repository.Repository
+	closed atomic.Bool
+}
+
+func (repo *closeRecorder) Close(ctx context.Context) error {
+	repo.closed.Store(true)
+	return nil
+}
+
+func Test_run(t *testing.T) {
+	tests := []struct {
+		name            string
+		handlerDelay    time.Duration
+		shutdownTimeout time.Duration
+		wantBody        string
+		wantErr         bool
+	}{
+		{
+			name:            "In-flight requests are drained",
+			handlerDelay:    100 * time.Millisecond,
+			shutdownTimeout: 5 * time.Second,
+			wantBody:        "done",
+			wantErr:         false,
+		},
+		{
+			name:            "Requests are cut off after the shutdown timeout",
+			handlerDelay:    5 * time.Second,
+			shutdownTimeout: 50 * time.Millisecond,
+			wantBody:        "",
+			wantErr:         true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{})
+			server := &http.Server{
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					close(started)
+
+					select {
+					case <-time.After(tt.handlerDelay):
+						io.WriteString(w, "done")
+					case <-r.Context().Done():
+					}
+				}),
+			}
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("Failed to listen: %v", err)
+			}
+
+			repo := &closeRecorder{Repository: repository.NewMemoryRepository()}
+			ctx, cancel := context.WithCancel(context.Background())
+
+			runErr := make(chan error, 1)
+			go func() {
+				runErr <- run(ctx, server, listener, repo, tt.shutdownTimeout)
+			}()
+
+			body := make(chan string, 1)
+			go func() {
+				res, err := http.Get("http://" + listener.Addr().String())
+				if err != nil {
+					body <- ""
+					return
+				}
+				defer res.Body.Close()
+
+				data, _ := io.ReadAll(res.Body)
+				body <- string(data)
+			}()
+
+			<-started
+			cancel()
+
+			if got := <-body; got != tt.wantBody {
+				t.Errorf("run response body = %q, want: %q", got, tt.wantBody)
+			}
+
+			if err = <-runErr; (err != nil) != tt.wantErr {
+				t.Errorf("run error = %v, wantErr: %v", err, tt.wantErr)
+			}
+
+			if !repo.closed.Load() {
+				t.Error("run did not close the repository")
+			}
+		})
+	}
+}
+
+func Test_run_listenerFails(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	listener.Close()
+
+	repo := &closeRecorder{Repository: repository.NewMemoryRepository()}
+
+	err = run(context.Background(), &http.Server{}, listener, repo, time.Second)
+	if err == nil {
+		t.Error("run error = nil, want the listener error")
+	}
+
+	if !repo.closed.Load() {
+		t.Error("run did not close the repository")
+	}
+}
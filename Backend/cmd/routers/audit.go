@@ -0,0 +1,25 @@
+// Package routers provides all the details of our chi router.
+package routers
+
+import (
+	"Home-Intranet-v2-Backend/cmd/handlers/audit"
+	"Home-Intranet-v2-Backend/cmd/routers/middlewares"
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// AuditRoutes is used to declare the routes for reading the audit log, which only admins can do
+func AuditRoutes(r chi.Router, repo repository.Repository) {
+
+	handler := audit.Handler{
+		Repository: repo,
+	}
+
+	r.Route("/audit", func(r chi.Router) {
+		r.Use(middlewares.Require(auth.PermissionReadAudit))
+
+		r.Get("/", handler.ListEntries)
+	})
+}
@@ -0,0 +1,58 @@
+package routers
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestAuditRoutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		wantCode int
+	}{
+		{
+			name:     "Anonymous users have to log in",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "Members can't read the audit log",
+			role:     models.RoleMember,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "Admins can read the audit log",
+			role:     models.RoleAdmin,
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			AuditRoutes(r, repository.NewMemoryRepository())
+
+			req, err := http.NewRequest("GET", "/audit", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.role != "" {
+				req = req.WithContext(auth.WithUser(req.Context(), models.User{Username: "ada", Role: tt.role}))
+			}
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.wantCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.wantCode)
+			}
+		})
+	}
+}
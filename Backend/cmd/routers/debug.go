@@ -0,0 +1,22 @@
+// Package routers provides all the details of our chi router.
+package routers
+
+import (
+	"Home-Intranet-v2-Backend/cmd/routers/middlewares"
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DebugRoutes is used to declare the routes for inspecting and tuning the running service, which
+// only admins may use
+func DebugRoutes(r *chi.Mux) {
+
+	r.Route("/debug", func(r chi.Router) {
+		r.Use(middlewares.Require(auth.PermissionOperate))
+
+		r.Method("GET", "/log-level", logger.LevelHandler())
+		r.Method("PUT", "/log-level", logger.LevelHandler())
+	})
+}
@@ -0,0 +1,121 @@
+package routers
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestDebugRoutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		role     string
+		wantCode int
+	}{
+		{
+			name:     "Admin reads the log level",
+			method:   http.MethodGet,
+			role:     models.RoleAdmin,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Member is forbidden",
+			method:   http.MethodGet,
+			role:     models.RoleMember,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "Anonymous is unauthorized",
+			method:   http.MethodGet,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "Unsupported method",
+			method:   http.MethodPost,
+			role:     models.RoleAdmin,
+			wantCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			DebugRoutes(r)
+
+			req := httptest.NewRequest(tt.method, "/debug/log-level", nil)
+			if tt.role != "" {
+				req = req.WithContext(auth.WithUser(req.Context(), models.User{Username: "ada", Role: tt.role}))
+			}
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.wantCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.wantCode)
+			}
+
+			if tt.wantCode == http.StatusOK && !strings.Contains(rr.Body.String(), `"level"`) {
+				t.Errorf("handler returned unexpected body: got %v", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestDebugRoutes_changeLevel(t *testing.T) {
+	r := chi.NewRouter()
+	DebugRoutes(r)
+
+	// serve sends a request to the log level route, as the given role when there is one
+	serve := func(method string, body string, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/debug/log-level", strings.NewReader(body))
+		if role != "" {
+			req = req.WithContext(auth.WithUser(req.Context(), models.User{Username: "ada", Role: role}))
+		}
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	before := serve(http.MethodGet, "", models.RoleAdmin).Body.String()
+
+	tests := []struct {
+		name     string
+		role     string
+		wantCode int
+	}{
+		{
+			name:     "Anonymous can't change the log level",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "Member can't change the log level",
+			role:     models.RoleMember,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "Guest can't change the log level",
+			role:     models.RoleGuest,
+			wantCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rr := serve(http.MethodPut, `{"level": "fatal"}`, tt.role); rr.Code != tt.wantCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, tt.wantCode)
+			}
+
+			if after := serve(http.MethodGet, "", models.RoleAdmin).Body.String(); after != before {
+				t.Errorf("log level changed to %v, want it left at %v", after, before)
+			}
+		})
+	}
+}
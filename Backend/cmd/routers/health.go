@@ -0,0 +1,24 @@
+// Package routers provides all the details of our chi router.
+package routers
+
+import (
+	"Home-Intranet-v2-Backend/cmd/handlers/health"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// HealthRoutes is used to declare the liveness and readiness routes used by docker and the proxy
+func HealthRoutes(r *chi.Mux, repo repository.Repository, readyTimeout time.Duration) {
+
+	handler := health.Handler{
+		Checks: []health.Check{
+			{Name: "database", Ping: repo.Ping},
+		},
+		Timeout: readyTimeout,
+	}
+
+	r.Get("/healthz", handler.Liveness)
+	r.Get("/readyz", handler.Readiness)
+}
@@ -0,0 +1,53 @@
+package routers
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestHealthRoutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		wantCode int
+	}{
+		{
+			name:     "Liveness route",
+			target:   "/healthz",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Readiness route",
+			target:   "/readyz",
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			HealthRoutes(r, repository.NewMemoryRepository(), time.Second)
+
+			req, err := http.NewRequest("GET", tt.target, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.wantCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.wantCode)
+			}
+
+			if ctype := rr.Header().Get("Content-Type"); ctype != "application/json" {
+				t.Errorf("content type header does not match: got %v want %v", ctype, "application/json")
+			}
+		})
+	}
+}
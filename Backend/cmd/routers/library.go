@@ -3,31 +3,63 @@ package routers
 
 import (
 	"Home-Intranet-v2-Backend/cmd/handlers/library"
-	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/cmd/routers/middlewares"
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/platform/config"
 	"Home-Intranet-v2-Backend/internal/platform/repository"
 
 	"github.com/go-chi/chi/v5"
 )
 
-// LibraryRoutes is used to declare routes related to the application root
-func LibraryRoutes(r *chi.Mux) {
-
-	mongo, err := repository.Connect()
-	if err != nil {
-		logger.Fatal("Could not connect to database")
-	}
+// LibraryRoutes is used to declare routes related to the application root. Each route declares
+// the permission it needs, and the roles that have each permission are listed in the auth package.
+func LibraryRoutes(r chi.Router, repo repository.Repository, cfg config.Library) {
 
 	handler := library.Handler{
-		Repository: &repository.Repository{
-			Mongo: mongo,
-		},
+		Repository: repo,
+		LoanPeriod: cfg.LoanPeriod(),
 	}
 
-	r.Route("/v1", func(r chi.Router) {
+	read := middlewares.Require(auth.PermissionReadLibrary)
+	borrow := middlewares.Require(auth.PermissionBorrow)
+	edit := middlewares.Require(auth.PermissionEditLibrary)
+	remove := middlewares.Require(auth.PermissionDeleteLibrary)
+
+	r.Route("/books", func(r chi.Router) {
+		r.With(read).Get("/", handler.ListBooks)
+		r.With(edit).Post("/", handler.CreateBook)
+		r.With(read).Get("/overdue", handler.ListOverdueBooks)
 
-		r.Route("/books", func(r chi.Router) {
-			r.Get("/", handler.ListBooks)
-			r.Post("/", handler.CreateBook)
+		r.Route("/{id}", func(r chi.Router) {
+			r.With(read).Get("/", handler.GetBook)
+			r.With(edit).Put("/", handler.UpdateBook)
+			r.With(edit).Patch("/", handler.PatchBook)
+			r.With(remove).Delete("/", handler.DeleteBook)
+
+			// Borrowers can only check out and return their own loans, which the handlers check
+			r.With(borrow).Post("/checkout", handler.CheckoutBook)
+			r.With(borrow).Post("/return", handler.ReturnBook)
+			r.With(read).Get("/loans", handler.ListBookLoans)
 		})
 	})
+
+	r.Route("/authors", func(r chi.Router) {
+		r.With(read).Get("/", handler.ListAuthors)
+		r.With(edit).Post("/", handler.CreateAuthor)
+		r.With(read).Get("/duplicates", handler.ListDuplicateAuthors)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.With(read).Get("/", handler.GetAuthor)
+			r.With(edit).Put("/", handler.UpdateAuthor)
+			r.With(edit).Patch("/", handler.PatchAuthor)
+			r.With(remove).Delete("/", handler.DeleteAuthor)
+
+			r.With(read).Get("/books", handler.ListAuthorBooks)
+			r.With(remove).Post("/merge", handler.MergeAuthors)
+		})
+	})
+
+	r.Route("/loans", func(r chi.Router) {
+		r.With(read).Get("/", handler.ListLoans)
+	})
 }
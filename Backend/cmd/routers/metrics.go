@@ -0,0 +1,14 @@
+// Package routers provides all the details of our chi router.
+package routers
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/metrics"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// MetricsRoutes is used to declare the route Prometheus scrapes
+func MetricsRoutes(r *chi.Mux) {
+
+	r.Method("GET", "/metrics", metrics.Handler())
+}
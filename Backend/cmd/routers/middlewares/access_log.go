@@ -0,0 +1,48 @@
+// Package middlewares contains all of our custom defined or configured middleware for the go-chi router
+package middlewares
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+)
+
+// AccessLog writes one structured entry for every request once it has been handled. It also
+// stores a logger carrying the request id in the context, so everything the handlers log through
+// logger.FromContext can be matched with the request. It has to run after RequestID.
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		start := time.Now()
+
+		log := logger.With(zap.String("request_id", middleware.GetReqID(request.Context())))
+		request = request.WithContext(logger.WithContext(request.Context(), log))
+
+		writer := middleware.NewWrapResponseWriter(w, request.ProtoMajor)
+
+		defer func() {
+			status := responseStatus(writer)
+
+			fields := []zap.Field{
+				zap.String("method", request.Method),
+				zap.String("route", routePattern(request)),
+				zap.String("path", request.URL.Path),
+				zap.Int("status", status),
+				zap.Int("bytes", writer.BytesWritten()),
+				zap.Duration("duration", time.Since(start)),
+				zap.String("remote_addr", request.RemoteAddr),
+			}
+
+			if status >= http.StatusInternalServerError {
+				log.Error("Request handled", fields...)
+				return
+			}
+
+			log.Info("Request handled", fields...)
+		}()
+
+		next.ServeHTTP(writer, request)
+	})
+}
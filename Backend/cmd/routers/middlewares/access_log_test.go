@@ -0,0 +1,109 @@
+package middlewares
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAccessLog(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		wantLevel  zapcore.Level
+		wantRoute  string
+		wantStatus int64
+		wantBytes  int64
+	}{
+		{
+			name:       "Handled request",
+			target:     "/v1/widgets/42",
+			wantLevel:  zapcore.InfoLevel,
+			wantRoute:  "/v1/widgets/{id}",
+			wantStatus: http.StatusOK,
+			wantBytes:  5,
+		},
+		{
+			name:       "Server error",
+			target:     "/v1/broken",
+			wantLevel:  zapcore.ErrorLevel,
+			wantRoute:  "/v1/broken",
+			wantStatus: http.StatusInternalServerError,
+			wantBytes:  0,
+		},
+		{
+			name:       "Unknown path",
+			target:     "/nowhere",
+			wantLevel:  zapcore.InfoLevel,
+			wantRoute:  "unmatched",
+			wantStatus: http.StatusNotFound,
+			wantBytes:  19,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			defer logger.Replace(zap.New(core))()
+
+			router := chi.NewRouter()
+			router.Use(RequestID)
+			router.Use(AccessLog)
+			router.Get("/v1/widgets/{id}", func(w http.ResponseWriter, request *http.Request) {
+				logger.FromContext(request.Context()).Info("Inside the handler")
+				w.Write([]byte("hello"))
+			})
+			router.Get("/v1/broken", func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			})
+
+			request := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			request.Header.Set("X-Request-Id", "req-1")
+			router.ServeHTTP(httptest.NewRecorder(), request)
+
+			entries := logs.FilterMessage("Request handled").All()
+			if len(entries) != 1 {
+				t.Fatalf("AccessLog wrote %d entries, want: 1", len(entries))
+			}
+
+			entry := entries[0]
+			fields := entry.ContextMap()
+
+			if entry.Level != tt.wantLevel {
+				t.Errorf("AccessLog level = %v, want: %v", entry.Level, tt.wantLevel)
+			}
+
+			if fields["request_id"] != "req-1" {
+				t.Errorf("AccessLog request_id = %v, want: req-1", fields["request_id"])
+			}
+
+			if fields["route"] != tt.wantRoute {
+				t.Errorf("AccessLog route = %v, want: %v", fields["route"], tt.wantRoute)
+			}
+
+			if fields["status"] != tt.wantStatus {
+				t.Errorf("AccessLog status = %v, want: %v", fields["status"], tt.wantStatus)
+			}
+
+			if fields["bytes"] != tt.wantBytes {
+				t.Errorf("AccessLog bytes = %v, want: %v", fields["bytes"], tt.wantBytes)
+			}
+
+			if fields["method"] != http.MethodGet || fields["path"] != tt.target {
+				t.Errorf("AccessLog method and path = %v %v, want: GET %v", fields["method"], fields["path"], tt.target)
+			}
+
+			for _, handlerEntry := range logs.FilterMessage("Inside the handler").All() {
+				if handlerEntry.ContextMap()["request_id"] != "req-1" {
+					t.Errorf("Handler log request_id = %v, want: req-1", handlerEntry.ContextMap()["request_id"])
+				}
+			}
+		})
+	}
+}
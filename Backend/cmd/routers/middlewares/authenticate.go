@@ -0,0 +1,98 @@
+// Package middlewares contains all of our custom defined or configured middleware for the go-chi router
+package middlewares
+
+import (
+	"Home-Intranet-v2-Backend/internal/audit"
+	auditmodels "Home-Intranet-v2-Backend/internal/audit/models"
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"errors"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Authenticate finds the user a request was made by and stores them in the context, where
+// auth.UserFromContext finds them. Scripts send an API key in the Authorization header as a bearer
+// token, which is also stored so its scopes are checked, and browsers send a session cookie.
+// The user, and key, are also recorded as the actor of any changes the request makes. Requests
+// without a valid key or session carry on without a user, so RequireUser has to be used
+// on the routes that need one.
+func Authenticate(sessions auth.Sessions, keys auth.APIKeys) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+			if token, ok := bearerToken(request); ok {
+				user, key, err := keys.Lookup(request.Context(), token)
+				if errors.Is(err, auth.ErrInvalidAPIKey) {
+					next.ServeHTTP(w, request)
+					return
+				}
+
+				if err != nil {
+					logger.FromContext(request.Context()).Error("Issue looking up api key", zap.Error(err))
+					response.InternalServerError(w, err)
+					return
+				}
+
+				ctx := auth.WithAPIKey(auth.WithUser(request.Context(), user), key)
+				ctx = audit.WithActor(ctx, auditmodels.Actor{UserID: user.ID, Username: user.Username, APIKeyID: key.ID})
+				ctx = logger.WithContext(ctx, logger.FromContext(ctx).With(
+					zap.String("user_id", user.ID.Hex()), zap.String("api_key_id", key.ID.Hex())))
+
+				next.ServeHTTP(w, request.WithContext(ctx))
+				return
+			}
+
+			cookie, err := request.Cookie(auth.CookieName)
+			if err != nil || cookie.Value == "" {
+				next.ServeHTTP(w, request)
+				return
+			}
+
+			user, err := sessions.Lookup(request.Context(), cookie.Value)
+			if errors.Is(err, auth.ErrInvalidSession) {
+				next.ServeHTTP(w, request)
+				return
+			}
+
+			if err != nil {
+				logger.FromContext(request.Context()).Error("Issue looking up session", zap.Error(err))
+				response.InternalServerError(w, err)
+				return
+			}
+
+			ctx := auth.WithUser(request.Context(), user)
+			ctx = audit.WithActor(ctx, auditmodels.Actor{UserID: user.ID, Username: user.Username})
+			ctx = logger.WithContext(ctx, logger.FromContext(ctx).With(zap.String("user_id", user.ID.Hex())))
+
+			next.ServeHTTP(w, request.WithContext(ctx))
+		})
+	}
+}
+
+// bearerToken returns the token of an "Authorization: Bearer" header
+func bearerToken(request *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(request.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+
+	return token, token != ""
+}
+
+// RequireUser sends a 401 for requests that weren't made by a logged in user. It has to run after
+// Authenticate.
+func RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		if _, ok := auth.UserFromContext(request.Context()); !ok {
+			response.Unauthorized(w, "log in to continue")
+			return
+		}
+
+		next.ServeHTTP(w, request)
+	})
+}
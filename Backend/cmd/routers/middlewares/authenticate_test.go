@@ -0,0 +1,179 @@
+package middlewares
+
+import (
+	"Home-Intranet-v2-Backend/internal/audit"
+	auditmodels "Home-Intranet-v2-Backend/internal/audit/models"
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+
+	user, err := repository.Create(ctx, repo, models.User{Username: "ada"})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	sessions := auth.Sessions{Repository: repo, Lifetime: time.Hour}
+
+	token, _, err := sessions.Start(ctx, user)
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+
+	expired, _, err := auth.Sessions{Repository: repo, Lifetime: -time.Hour}.Start(ctx, user)
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+
+	keys := auth.APIKeys{Repository: repo}
+
+	key, _, err := keys.Create(ctx, user, "Shelf scanner", []string{"library:read"})
+	if err != nil {
+		t.Fatalf("Failed to create api key: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		cookie        string
+		authorization string
+		wantUsername  string
+		wantKey       bool
+	}{
+		{
+			name:         "Valid session",
+			cookie:       token,
+			wantUsername: "ada",
+		},
+		{
+			name:   "No cookie",
+			cookie: "",
+		},
+		{
+			name:   "Unknown token",
+			cookie: "not-a-session",
+		},
+		{
+			name:   "Expired session",
+			cookie: expired,
+		},
+		{
+			name:          "Valid api key",
+			authorization: "Bearer " + key,
+			wantUsername:  "ada",
+			wantKey:       true,
+		},
+		{
+			name:          "Unknown api key",
+			authorization: "Bearer " + auth.KeyPrefix + "unknown",
+		},
+		{
+			name:          "Api key takes precedence over the session",
+			cookie:        token,
+			authorization: "bearer " + key,
+			wantUsername:  "ada",
+			wantKey:       true,
+		},
+		{
+			name:          "Other authorization scheme",
+			authorization: "Basic " + key,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUsername string
+			var gotKey bool
+			var gotActor auditmodels.Actor
+
+			handler := Authenticate(sessions, keys)(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+				if user, ok := auth.UserFromContext(request.Context()); ok {
+					gotUsername = user.Username
+				}
+
+				_, gotKey = auth.APIKeyFromContext(request.Context())
+				gotActor = audit.ActorFromContext(request.Context())
+			}))
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/books", nil)
+			if tt.cookie != "" {
+				request.AddCookie(&http.Cookie{Name: auth.CookieName, Value: tt.cookie})
+			}
+
+			if tt.authorization != "" {
+				request.Header.Set("Authorization", tt.authorization)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != http.StatusOK {
+				t.Errorf("Authenticate status code = %v, want: %v", recorder.Code, http.StatusOK)
+			}
+
+			if gotUsername != tt.wantUsername {
+				t.Errorf("Authenticate user = %q, want: %q", gotUsername, tt.wantUsername)
+			}
+
+			if gotKey != tt.wantKey {
+				t.Errorf("Authenticate api key = %v, want: %v", gotKey, tt.wantKey)
+			}
+
+			wantActor := auditmodels.System.Username
+			if tt.wantUsername != "" {
+				wantActor = tt.wantUsername
+			}
+
+			if gotActor.Username != wantActor || gotActor.APIKeyID.IsZero() == tt.wantKey {
+				t.Errorf("Authenticate audit actor = %+v, want: %q with api key %v", gotActor, wantActor, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestRequireUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      func(ctx context.Context) context.Context
+		wantCode int
+	}{
+		{
+			name: "Logged in",
+			ctx: func(ctx context.Context) context.Context {
+				return auth.WithUser(ctx, models.User{Username: "ada"})
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Anonymous",
+			ctx:      func(ctx context.Context) context.Context { return ctx },
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := RequireUser(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/books", nil)
+			request = request.WithContext(tt.ctx(request.Context()))
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantCode {
+				t.Errorf("RequireUser status code = %v, want: %v", recorder.Code, tt.wantCode)
+			}
+		})
+	}
+}
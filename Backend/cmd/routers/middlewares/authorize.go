@@ -0,0 +1,46 @@
+// Package middlewares contains all of our custom defined or configured middleware for the go-chi router
+package middlewares
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// Require only lets requests through when the logged in user's role has the permission, and when
+// the API key the request was made with, if any, has it as a scope. It sends a 401 when no one is
+// logged in and a 403 when the user or key isn't allowed. It has to run after Authenticate, and
+// is declared next to each route, such as
+//
+//	r.With(middlewares.Require(auth.PermissionDeleteLibrary)).Delete("/", handler.DeleteBook)
+func Require(permission auth.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+			user, ok := auth.UserFromContext(request.Context())
+			if !ok {
+				response.Unauthorized(w, "log in to continue")
+				return
+			}
+
+			if !auth.HasPermission(user, permission) {
+				logger.FromContext(request.Context()).Warn("Permission denied",
+					zap.String("role", user.Role), zap.String("permission", string(permission)))
+				response.Forbidden(w, fmt.Sprintf("this needs the %s permission, which the %s role doesn't have", permission, user.Role))
+				return
+			}
+
+			if key, ok := auth.APIKeyFromContext(request.Context()); ok && !key.HasScope(string(permission)) {
+				logger.FromContext(request.Context()).Warn("Permission denied",
+					zap.String("api_key", key.Prefix), zap.String("permission", string(permission)))
+				response.Forbidden(w, fmt.Sprintf("this needs the %s scope, which the %s API key doesn't have", permission, key.Name))
+				return
+			}
+
+			next.ServeHTTP(w, request)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package middlewares
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequire(t *testing.T) {
+	tests := []struct {
+		name        string
+		ctx         func(ctx context.Context) context.Context
+		permission  auth.Permission
+		wantCode    int
+		wantProblem string
+	}{
+		{
+			name: "Admin can do anything",
+			ctx: func(ctx context.Context) context.Context {
+				return auth.WithUser(ctx, models.User{Username: "ada", Role: models.RoleAdmin})
+			},
+			permission: auth.PermissionDeleteLibrary,
+			wantCode:   http.StatusOK,
+		},
+		{
+			name: "Member can borrow",
+			ctx: func(ctx context.Context) context.Context {
+				return auth.WithUser(ctx, models.User{Username: "sam", Role: models.RoleMember})
+			},
+			permission: auth.PermissionBorrow,
+			wantCode:   http.StatusOK,
+		},
+		{
+			name: "Member can't delete",
+			ctx: func(ctx context.Context) context.Context {
+				return auth.WithUser(ctx, models.User{Username: "sam", Role: models.RoleMember})
+			},
+			permission:  auth.PermissionDeleteLibrary,
+			wantCode:    http.StatusForbidden,
+			wantProblem: "forbidden",
+		},
+		{
+			name: "Guest can't borrow",
+			ctx: func(ctx context.Context) context.Context {
+				return auth.WithUser(ctx, models.User{Username: "pip", Role: models.RoleGuest})
+			},
+			permission:  auth.PermissionBorrow,
+			wantCode:    http.StatusForbidden,
+			wantProblem: "forbidden",
+		},
+		{
+			name: "API key with the scope",
+			ctx: func(ctx context.Context) context.Context {
+				ctx = auth.WithUser(ctx, models.User{Username: "ada", Role: models.RoleAdmin})
+				return auth.WithAPIKey(ctx, models.APIKey{Name: "Shelf scanner", Scopes: []string{"library:edit"}})
+			},
+			permission: auth.PermissionEditLibrary,
+			wantCode:   http.StatusOK,
+		},
+		{
+			name: "API key without the scope",
+			ctx: func(ctx context.Context) context.Context {
+				ctx = auth.WithUser(ctx, models.User{Username: "ada", Role: models.RoleAdmin})
+				return auth.WithAPIKey(ctx, models.APIKey{Name: "Shelf scanner", Scopes: []string{"library:edit"}})
+			},
+			permission:  auth.PermissionDeleteLibrary,
+			wantCode:    http.StatusForbidden,
+			wantProblem: "forbidden",
+		},
+		{
+			name: "API key of a member with an admin scope",
+			ctx: func(ctx context.Context) context.Context {
+				ctx = auth.WithUser(ctx, models.User{Username: "sam", Role: models.RoleMember})
+				return auth.WithAPIKey(ctx, models.APIKey{Name: "Old key", Scopes: []string{"library:delete"}})
+			},
+			permission:  auth.PermissionDeleteLibrary,
+			wantCode:    http.StatusForbidden,
+			wantProblem: "forbidden",
+		},
+		{
+			name:        "Anonymous",
+			ctx:         func(ctx context.Context) context.Context { return ctx },
+			permission:  auth.PermissionReadLibrary,
+			wantCode:    http.StatusUnauthorized,
+			wantProblem: "unauthorized",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Require(tt.permission)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			request := httptest.NewRequest(http.MethodDelete, "/v1/books/1", nil)
+			request = request.WithContext(tt.ctx(request.Context()))
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantCode {
+				t.Fatalf("Require status code = %v, want: %v", recorder.Code, tt.wantCode)
+			}
+
+			if tt.wantProblem == "" {
+				return
+			}
+
+			var problem struct {
+				Code string `json:"code"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("Failed to parse problem details: %v", err)
+			}
+
+			if problem.Code != tt.wantProblem {
+				t.Errorf("Require problem code = %v, want: %v", problem.Code, tt.wantProblem)
+			}
+		})
+	}
+}
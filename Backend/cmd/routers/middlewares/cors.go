@@ -2,21 +2,21 @@
 package middlewares
 
 import (
-	"Home-Intranet-v2-Backend/internal/platform/config"
 	"net/http"
 
 	"github.com/go-chi/cors"
 )
 
-// SetupCors is used to configure the go-chi CORS middleware
-func SetupCors() func(http.Handler) http.Handler {
-	allowedHosts := config.GetAllowedHosts()
+// SetupCors is used to configure the go-chi CORS middleware. Credentials are allowed as the
+// frontend sends the session cookie from its own origin, which is why the allowed hosts have to be
+// listed rather than a wildcard.
+func SetupCors(allowedHosts string) func(http.Handler) http.Handler {
 	return cors.Handler(cors.Options{
 		AllowedOrigins:   []string{allowedHosts},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: false,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Request-Id"},
+		ExposedHeaders:   []string{"Link", "X-Request-Id"},
+		AllowCredentials: true,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	})
 }
@@ -20,10 +20,10 @@ func TestSetupCors(t *testing.T) {
 			allowedHosts: "http://localhost:3000",
 			want1: cors.Handler(cors.Options{
 				AllowedOrigins:   []string{"http://localhost:3000"},
-				AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-				AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-				ExposedHeaders:   []string{"Link"},
-				AllowCredentials: false,
+				AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+				AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Request-Id"},
+				ExposedHeaders:   []string{"Link", "X-Request-Id"},
+				AllowCredentials: true,
 				MaxAge:           300,
 			}),
 		},
@@ -31,8 +31,7 @@ func TestSetupCors(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			t.Setenv("BACKEND_ALLOWED_HOSTS", tt.allowedHosts)
-			got1 := SetupCors()
+			got1 := SetupCors(tt.allowedHosts)
 
 			// We can't directly compare functions, so we'll test the behavior
 			testHandler := http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {})
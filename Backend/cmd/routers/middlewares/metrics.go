@@ -0,0 +1,49 @@
+// Package middlewares contains all of our custom defined or configured middleware for the go-chi router
+package middlewares
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/metrics"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// unmatchedRoute labels requests that didn't match any route, so unknown paths can't each add a
+// new series
+const unmatchedRoute = "unmatched"
+
+// Metrics records the count, latency and status of every request along with the number in flight.
+// Requests are labelled by their chi route pattern, such as /v1/books/{id}, rather than their
+// path, which keeps one series per route.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		finish := metrics.RequestStarted()
+		writer := middleware.NewWrapResponseWriter(w, request.ProtoMajor)
+
+		defer func() {
+			finish(request.Method, routePattern(request), responseStatus(writer))
+		}()
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// routePattern returns the chi pattern of the route that handled a request, which is only known
+// once routing is done
+func routePattern(request *http.Request) string {
+	if routeContext := chi.RouteContext(request.Context()); routeContext != nil && routeContext.RoutePattern() != "" {
+		return routeContext.RoutePattern()
+	}
+
+	return unmatchedRoute
+}
+
+// responseStatus returns the status sent, where a handler that never called WriteHeader sent a 200
+func responseStatus(writer middleware.WrapResponseWriter) int {
+	if writer.Status() == 0 {
+		return http.StatusOK
+	}
+
+	return writer.Status()
+}
@@ -0,0 +1,65 @@
+package middlewares
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/metrics"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestMetrics(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(Metrics)
+	router.Route("/v1/widgets", func(r chi.Router) {
+		r.Get("/{id}", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})
+		r.Get("/", func(w http.ResponseWriter, _ *http.Request) {
+			w.Write([]byte("[]"))
+		})
+	})
+
+	for _, target := range []string{"/v1/widgets/1", "/v1/widgets/2", "/v1/widgets/", "/nowhere"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	tests := []struct {
+		name   string
+		series string
+	}{
+		{
+			name:   "Requests are counted per route pattern",
+			series: `http_requests_total{method="GET",route="/v1/widgets/{id}",status="418"} 2`,
+		},
+		{
+			name:   "Handlers that don't write a status are a 200",
+			series: `http_requests_total{method="GET",route="/v1/widgets",status="200"} 1`,
+		},
+		{
+			name:   "Unknown paths share one series",
+			series: `http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		},
+		{
+			name:   "Latency is observed",
+			series: `http_request_duration_seconds_count{method="GET",route="/v1/widgets/{id}",status="418"} 2`,
+		},
+		{
+			name:   "Nothing is left in flight",
+			series: `http_requests_in_flight 0`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(recorder.Body.String(), tt.series) {
+				t.Errorf("Metrics output is missing %s", tt.series)
+			}
+		})
+	}
+}
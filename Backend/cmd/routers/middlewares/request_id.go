@@ -0,0 +1,42 @@
+// Package middlewares contains all of our custom defined or configured middleware for the go-chi router
+package middlewares
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/response"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// validRequestID limits the ids accepted from clients and proxies to ones that are safe to log and
+// echo back
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID gives every request an id. An X-Request-Id sent by the proxy in front of us is reused
+// so both logs line up, otherwise a random one is generated. The id is stored where chi's
+// middleware.GetReqID finds it and set on the response, which is where error responses read it.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		id := request.Header.Get(response.RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(response.RequestIDHeader, id)
+
+		ctx := context.WithValue(request.Context(), middleware.RequestIDKey, id)
+		next.ServeHTTP(w, request.WithContext(ctx))
+	})
+}
+
+// newRequestID returns a random 128 bit id in hex
+func newRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
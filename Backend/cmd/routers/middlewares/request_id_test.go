@@ -0,0 +1,61 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func TestRequestID(t *testing.T) {
+	generated := regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+	tests := []struct {
+		name     string
+		incoming string
+		want1    func(id string) bool
+	}{
+		{
+			name:  "Generated when missing",
+			want1: generated.MatchString,
+		},
+		{
+			name:     "Reused from the proxy",
+			incoming: "abc-123.def_4",
+			want1:    func(id string) bool { return id == "abc-123.def_4" },
+		},
+		{
+			name:     "Replaced when unsafe",
+			incoming: "abc\ninjected: header",
+			want1:    generated.MatchString,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var contextID string
+			handler := RequestID(http.HandlerFunc(func(_ http.ResponseWriter, request *http.Request) {
+				contextID = middleware.GetReqID(request.Context())
+			}))
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				request.Header.Set("X-Request-Id", tt.incoming)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, request)
+
+			got1 := rec.Header().Get("X-Request-Id")
+			if !tt.want1(got1) {
+				t.Errorf("RequestID got1 = %q", got1)
+			}
+
+			if contextID != got1 {
+				t.Errorf("RequestID context id = %q, want: %q", contextID, got1)
+			}
+		})
+	}
+}
@@ -3,28 +3,55 @@ package routers
 
 import (
 	"Home-Intranet-v2-Backend/cmd/routers/middlewares"
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/auth/oidc"
+	"Home-Intranet-v2-Backend/internal/platform/config"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
-// SetupRouter is called to instantiate and attach all middleware and routes to the router
-func SetupRouter() *chi.Mux {
+// SetupRouter is called to instantiate and attach all middleware and routes to the router. The
+// repository is owned by the caller, which closes it once the server has stopped.
+func SetupRouter(repo repository.Repository, cfg config.Config) *chi.Mux {
 	router := chi.NewRouter()
 
-	registerMiddleware(router)
-	registerRoutes(router)
+	sessions := auth.Sessions{
+		Repository: repo,
+		Lifetime:   cfg.Auth.SessionLifetime,
+	}
+	keys := auth.APIKeys{Repository: repo}
+
+	var provider *oidc.Provider
+	if cfg.Auth.OIDC.Enabled() {
+		provider = oidc.New(cfg.Auth.OIDC, nil)
+	}
+
+	registerMiddleware(router, cfg.Server, sessions, keys)
+	registerRoutes(router, repo, cfg, sessions, keys, provider)
 
 	return router
 }
 
-func registerMiddleware(router *chi.Mux) {
-	router.Use(middleware.Logger)
+func registerMiddleware(router *chi.Mux, cfg config.Server, sessions auth.Sessions, keys auth.APIKeys) {
+	router.Use(middlewares.RequestID)
+	router.Use(middlewares.Metrics)
+	router.Use(middlewares.AccessLog)
 	router.Use(middleware.Recoverer)
-	router.Use(middlewares.SetupCors())
+	router.Use(middlewares.SetupCors(cfg.AllowedHosts))
+	router.Use(middlewares.Authenticate(sessions, keys))
 }
 
-func registerRoutes(router *chi.Mux) {
+func registerRoutes(router *chi.Mux, repo repository.Repository, cfg config.Config, sessions auth.Sessions, keys auth.APIKeys, provider *oidc.Provider) {
 	RootRoutes(router)
-	LibraryRoutes(router)
+	HealthRoutes(router, repo, cfg.Server.ReadyTimeout)
+	MetricsRoutes(router)
+	DebugRoutes(router)
+
+	router.Route("/v1", func(r chi.Router) {
+		UserRoutes(r, repo, sessions, keys, provider, cfg.Server.Production)
+		LibraryRoutes(r, repo, cfg.Library)
+		AuditRoutes(r, repo)
+	})
 }
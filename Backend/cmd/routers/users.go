@@ -0,0 +1,57 @@
+// Package routers provides all the details of our chi router.
+package routers
+
+import (
+	"Home-Intranet-v2-Backend/cmd/handlers/users"
+	"Home-Intranet-v2-Backend/cmd/routers/middlewares"
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/auth/oidc"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// UserRoutes is used to declare the routes for logging in and out and managing user accounts and
+// API keys. The routes for logging in through the identity provider are only added when there is
+// one.
+func UserRoutes(r chi.Router, repo repository.Repository, sessions auth.Sessions, keys auth.APIKeys, provider *oidc.Provider, secureCookie bool) {
+
+	handler := users.Handler{
+		Repository:   repo,
+		Sessions:     sessions,
+		APIKeys:      keys,
+		OIDC:         provider,
+		SecureCookie: secureCookie,
+	}
+
+	manage := middlewares.Require(auth.PermissionManageUsers)
+
+	r.Route("/auth", func(r chi.Router) {
+		r.Post("/login", handler.Login)
+		r.Post("/logout", handler.Logout)
+
+		if provider != nil {
+			r.Get("/oidc/login", handler.StartOIDCLogin)
+			r.With(middlewares.RequireUser).Get("/oidc/link", handler.StartOIDCLink)
+			r.Get("/oidc/callback", handler.FinishOIDCLogin)
+		}
+	})
+
+	r.Route("/users", func(r chi.Router) {
+		r.Use(middlewares.RequireUser)
+
+		r.With(manage).Get("/", handler.ListUsers)
+		r.With(manage).Post("/", handler.CreateUser)
+		r.Get("/me", handler.GetCurrentUser)
+		r.Put("/me/password", handler.ChangePassword)
+		r.With(manage).Put("/{id}/role", handler.UpdateUserRole)
+	})
+
+	r.Route("/api-keys", func(r chi.Router) {
+		r.Use(middlewares.Require(auth.PermissionManageAPIKeys))
+
+		r.Get("/", handler.ListAPIKeys)
+		r.Post("/", handler.CreateAPIKey)
+		r.Delete("/{id}", handler.RevokeAPIKey)
+	})
+}
@@ -0,0 +1,83 @@
+package routers
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestUserRoutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "Login is open to everyone",
+			method:   "POST",
+			target:   "/auth/login",
+			body:     `{"username": "ada", "password": "correct horse"}`,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "Logout is open to everyone",
+			method:   "POST",
+			target:   "/auth/logout",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Identity provider login isn't there without a provider",
+			method:   "GET",
+			target:   "/auth/oidc/login",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Users need a logged in user",
+			method:   "GET",
+			target:   "/users",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "Current user needs a logged in user",
+			method:   "GET",
+			target:   "/users/me",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "API keys need a logged in user",
+			method:   "POST",
+			target:   "/api-keys",
+			body:     `{"name": "Shelf scanner", "scopes": ["library:read"]}`,
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewMemoryRepository()
+
+			r := chi.NewRouter()
+			UserRoutes(r, repo, auth.Sessions{Repository: repo, Lifetime: time.Hour}, auth.APIKeys{Repository: repo}, nil, false)
+
+			req, err := http.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.wantCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.wantCode)
+			}
+		})
+	}
+}
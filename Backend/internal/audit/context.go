@@ -0,0 +1,41 @@
+// Package audit records every change made through a repository in the audit log
+package audit
+
+import (
+	"Home-Intranet-v2-Backend/internal/audit/models"
+	"context"
+)
+
+// actorKey is the context key of who is making changes
+type actorKey struct{}
+
+// unauditedKey is the context key marking changes that aren't recorded
+type unauditedKey struct{}
+
+// WithActor returns a copy of ctx carrying who is making changes
+func WithActor(ctx context.Context, actor models.Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns who is making changes, which is the system when no one is
+func ActorFromContext(ctx context.Context) models.Actor {
+	if actor, ok := ctx.Value(actorKey{}).(models.Actor); ok {
+		return actor
+	}
+
+	return models.System
+}
+
+// WithoutAudit returns a copy of ctx whose changes aren't recorded. It is for bookkeeping the
+// service does on its own, such as noting when an API key was last used, which would otherwise
+// bury the changes people make.
+func WithoutAudit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unauditedKey{}, true)
+}
+
+// unaudited reports whether changes made with ctx aren't recorded
+func unaudited(ctx context.Context) bool {
+	skip, _ := ctx.Value(unauditedKey{}).(bool)
+
+	return skip
+}
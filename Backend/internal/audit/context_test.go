@@ -0,0 +1,29 @@
+package audit
+
+import (
+	"Home-Intranet-v2-Backend/internal/audit/models"
+	"context"
+	"testing"
+)
+
+func TestActorFromContext(t *testing.T) {
+	if got1 := ActorFromContext(context.Background()); got1 != models.System {
+		t.Errorf("ActorFromContext got1 = %+v, want1: %+v", got1, models.System)
+	}
+
+	actor := models.Actor{Username: "ada"}
+
+	if got1 := ActorFromContext(WithActor(context.Background(), actor)); got1 != actor {
+		t.Errorf("ActorFromContext got1 = %+v, want1: %+v", got1, actor)
+	}
+}
+
+func TestWithoutAudit(t *testing.T) {
+	if unaudited(context.Background()) {
+		t.Error("unaudited = true for a plain context")
+	}
+
+	if !unaudited(WithoutAudit(context.Background())) {
+		t.Error("unaudited = false for a context without audit")
+	}
+}
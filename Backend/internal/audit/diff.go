@@ -0,0 +1,149 @@
+// Package audit records every change made through a repository in the audit log
+package audit
+
+import (
+	"Home-Intranet-v2-Backend/internal/audit/models"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ignoredFields are kept by the repository rather than changed by anyone, so they aren't part of
+// a diff
+var ignoredFields = []string{"_id", "created_at", "updated_at"}
+
+// document returns a model as it is stored, or nil for no model
+func document(model interface{}) (bson.M, error) {
+	if model == nil || reflect.ValueOf(model).IsNil() {
+		return nil, nil
+	}
+
+	data, err := bson.Marshal(model)
+	if err != nil {
+		return nil, fmt.Errorf("issue marshalling document: %w", err)
+	}
+
+	var doc bson.M
+	if err = bson.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("issue unmarshalling document: %w", err)
+	}
+
+	return doc, nil
+}
+
+// diff returns the fields that differ between two versions of a document, in name order. Either
+// version can be nil, for documents that were created or deleted. The values of sensitive fields
+// are redacted.
+func diff(before bson.M, after bson.M, sensitive map[string]bool) []models.Change {
+	var fields []string
+	for _, doc := range []bson.M{before, after} {
+		for field := range doc {
+			if !slices.Contains(fields, field) && !slices.Contains(ignoredFields, field) {
+				fields = append(fields, field)
+			}
+		}
+	}
+	slices.Sort(fields)
+
+	changes := []models.Change{}
+	for _, field := range fields {
+		was, hadBefore := before[field]
+		is, hasAfter := after[field]
+
+		if hadBefore && hasAfter && reflect.DeepEqual(was, is) {
+			continue
+		}
+
+		change := models.Change{Field: field, Before: plain(was), After: plain(is)}
+		if sensitive[field] {
+			change.Before, change.After = redact(hadBefore), redact(hasAfter)
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// redact returns the placeholder for a sensitive value, or nil when there is no value
+func redact(present bool) interface{} {
+	if !present {
+		return nil
+	}
+
+	return models.Redacted
+}
+
+// plain converts a decoded BSON value to maps, slices and times, so it reads the same in JSON as
+// the document it came from
+func plain(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.M:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[key] = plain(item)
+		}
+
+		return m
+	case bson.D:
+		m := make(map[string]interface{}, len(v))
+		for _, item := range v {
+			m[item.Key] = plain(item.Value)
+		}
+
+		return m
+	case bson.A:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = plain(item)
+		}
+
+		return items
+	case primitive.DateTime:
+		return v.Time().UTC()
+	}
+
+	return value
+}
+
+// sensitiveFields returns the stored names of a model's fields that are never sent to users,
+// which are the ones with a json:"-" tag
+func sensitiveFields(t reflect.Type) map[string]bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	fields := map[string]bool{}
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("bson"), ",")
+
+		if field.Anonymous && strings.Contains(options, "inline") {
+			for embedded := range sensitiveFields(field.Type) {
+				fields[embedded] = true
+			}
+
+			continue
+		}
+
+		if field.Tag.Get("json") != "-" {
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		fields[name] = true
+	}
+
+	return fields
+}
@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"Home-Intranet-v2-Backend/internal/audit/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_diff(t *testing.T) {
+	due := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		before bson.M
+		after  bson.M
+		want1  []models.Change
+	}{
+		{
+			name:   "Changed, added and removed fields",
+			before: bson.M{"_id": 1, "title": "Dune", "shelf": "A1", "updated_at": 1},
+			after:  bson.M{"_id": 1, "title": "Dune", "due_date": primitive.NewDateTimeFromTime(due), "updated_at": 2},
+			want1: []models.Change{
+				{Field: "due_date", After: due},
+				{Field: "shelf", Before: "A1"},
+			},
+		},
+		{
+			name:   "Nested values",
+			before: bson.M{"authors": bson.A{bson.D{{Key: "last_name", Value: "Herbert"}}}},
+			after:  bson.M{"authors": bson.A{bson.M{"last_name": "Le Guin"}}},
+			want1: []models.Change{{
+				Field:  "authors",
+				Before: []interface{}{map[string]interface{}{"last_name": "Herbert"}},
+				After:  []interface{}{map[string]interface{}{"last_name": "Le Guin"}},
+			}},
+		},
+		{
+			name:   "Sensitive fields",
+			before: bson.M{"password_hash": "old"},
+			after:  bson.M{"password_hash": "new"},
+			want1:  []models.Change{{Field: "password_hash", Before: models.Redacted, After: models.Redacted}},
+		},
+		{
+			name:   "Nothing changed",
+			before: bson.M{"title": "Dune"},
+			after:  bson.M{"title": "Dune"},
+			want1:  []models.Change{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1 := diff(tt.before, tt.after, map[string]bool{"password_hash": true})
+
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("diff got1 = %#v, want1: %#v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func Test_sensitiveFields(t *testing.T) {
+	want1 := map[string]bool{"secret": true}
+
+	for _, model := range []interface{}{&gadget{}, &[]*gadget{}} {
+		if got1 := sensitiveFields(reflect.TypeOf(model)); !reflect.DeepEqual(got1, want1) {
+			t.Errorf("sensitiveFields got1 = %v, want1: %v", got1, want1)
+		}
+	}
+}
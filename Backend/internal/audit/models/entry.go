@@ -0,0 +1,50 @@
+// Package models stores all of our models for the audit log
+package models
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The actions an audit entry records
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Redacted is shown in place of the values of fields that are never sent to users, such as
+// password hashes, so the log shows they changed without holding them
+const Redacted = "[redacted]"
+
+// AuditEntry is a record of one change to a document: who made it, what it was and when
+type AuditEntry struct {
+	repository.Model `bson:",inline" json:",inline"`
+	Actor            Actor              `bson:"actor" json:"actor"`
+	Action           string             `bson:"action" json:"action"`
+	Collection       string             `bson:"collection" json:"collection"`
+	DocumentID       primitive.ObjectID `bson:"document_id" json:"document_id"`
+	Changes          []Change           `bson:"changes" json:"changes"`
+	Timestamp        time.Time          `bson:"timestamp" json:"timestamp"`
+}
+
+// Actor is who made a change. Changes made by the service itself, such as creating the initial
+// user at startup, have no user and are named system.
+type Actor struct {
+	UserID   primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty"`
+	Username string             `bson:"username" json:"username"`
+	APIKeyID primitive.ObjectID `bson:"api_key_id,omitempty" json:"api_key_id,omitempty"`
+}
+
+// System is the actor of changes that weren't made by a request
+var System = Actor{Username: "system"}
+
+// Change is the value of one field before and after a change. Before is empty for documents that
+// were created, and after for documents that were deleted.
+type Change struct {
+	Field  string      `bson:"field" json:"field"`
+	Before interface{} `bson:"before,omitempty" json:"before,omitempty"`
+	After  interface{} `bson:"after,omitempty" json:"after,omitempty"`
+}
@@ -0,0 +1,173 @@
+// Package audit records every change made through a repository in the audit log
+package audit
+
+import (
+	"Home-Intranet-v2-Backend/internal/audit/models"
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"context"
+	"reflect"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// skippedCollections aren't audited. Sessions are logins rather than data anyone changes, and
+// audit entries would otherwise record themselves.
+var skippedCollections = []string{"sessions", "auditentries"}
+
+// Repository wraps another repository, writing an audit entry for every document it creates,
+// updates or deletes. The document is read before it is changed, so the entry can hold what
+// changed. A change is kept even if its entry can't be written, as it has already been made, and
+// the failure is logged instead.
+type Repository struct {
+	repository.Repository
+}
+
+// Record is used to wrap a repository so its changes are audited
+func Record(repo repository.Repository) *Repository {
+	return &Repository{
+		Repository: repo,
+	}
+}
+
+// Create is used to insert a new document into a collection
+func (db *Repository) Create(ctx context.Context, model interface{}) error {
+	if err := db.Repository.Create(ctx, model); err != nil {
+		return err
+	}
+
+	db.record(ctx, models.ActionCreate, model, nil, model)
+
+	return nil
+}
+
+// Update is used to replace a document in specified collection
+func (db *Repository) Update(ctx context.Context, model interface{}, filter interface{}) error {
+	before, err := db.read(ctx, model, filter)
+	if err != nil {
+		return err
+	}
+
+	if err = db.Repository.Update(ctx, model, filter); err != nil {
+		return err
+	}
+
+	db.record(ctx, models.ActionUpdate, model, before, model)
+
+	return nil
+}
+
+// UpdateFields is used to atomically set fields on the first document matching a filter
+func (db *Repository) UpdateFields(ctx context.Context, model interface{}, filter interface{}, fields bson.D) error {
+	before, err := db.read(ctx, model, filter)
+	if err != nil {
+		return err
+	}
+
+	if err = db.Repository.UpdateFields(ctx, model, filter, fields); err != nil {
+		return err
+	}
+
+	db.record(ctx, models.ActionUpdate, model, before, model)
+
+	return nil
+}
+
+// Delete is used to delete a document in specified collection
+func (db *Repository) Delete(ctx context.Context, model interface{}, filter interface{}) error {
+	before, err := db.read(ctx, model, filter)
+	if err != nil {
+		return err
+	}
+
+	if err = db.Repository.Delete(ctx, model, filter); err != nil {
+		return err
+	}
+
+	db.record(ctx, models.ActionDelete, model, before, nil)
+
+	return nil
+}
+
+// read returns the document a change is about to be made to, or nil when there isn't one or the
+// change won't be recorded
+func (db *Repository) read(ctx context.Context, model interface{}, filter interface{}) (interface{}, error) {
+	if !db.audited(ctx, model) {
+		return nil, nil
+	}
+
+	before := reflect.New(reflect.TypeOf(model).Elem()).Interface()
+
+	err := db.Repository.Read(ctx, before, filter)
+	if db.Repository.IsNotFoundError(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return before, nil
+}
+
+// audited reports whether changes to a model are recorded
+func (db *Repository) audited(ctx context.Context, model interface{}) bool {
+	if unaudited(ctx) {
+		return false
+	}
+
+	collection, err := repository.CollectionName(model)
+
+	return err == nil && !slices.Contains(skippedCollections, collection)
+}
+
+// record writes the audit entry of a change. Updates that didn't change anything aren't recorded.
+func (db *Repository) record(ctx context.Context, action string, model interface{}, before interface{}, after interface{}) {
+	if !db.audited(ctx, model) {
+		return
+	}
+
+	log := logger.FromContext(ctx)
+
+	collection, _ := repository.CollectionName(model)
+
+	beforeDoc, err := document(before)
+	if err != nil {
+		log.Error("Issue writing audit entry", zap.String("collection", collection), zap.Error(err))
+		return
+	}
+
+	afterDoc, err := document(after)
+	if err != nil {
+		log.Error("Issue writing audit entry", zap.String("collection", collection), zap.Error(err))
+		return
+	}
+
+	changes := diff(beforeDoc, afterDoc, sensitiveFields(reflect.TypeOf(model)))
+	if action == models.ActionUpdate && len(changes) == 0 {
+		return
+	}
+
+	documentID, _ := afterDoc["_id"].(primitive.ObjectID)
+	if documentID.IsZero() {
+		documentID, _ = beforeDoc["_id"].(primitive.ObjectID)
+	}
+
+	entry := models.AuditEntry{
+		Actor:      ActorFromContext(ctx),
+		Action:     action,
+		Collection: collection,
+		DocumentID: documentID,
+		Changes:    changes,
+		Timestamp:  time.Now().UTC(),
+	}
+
+	// The change has already been made, so its entry is written even if the request is cancelled
+	if err = db.Repository.Create(context.WithoutCancel(ctx), &entry); err != nil {
+		log.Error("Issue writing audit entry", zap.String("collection", collection), zap.Error(err))
+	}
+}
@@ -0,0 +1,170 @@
+package audit
+
+import (
+	"Home-Intranet-v2-Backend/internal/audit/models"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"context"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// gadget is a model for testing the audit log
+type gadget struct {
+	repository.Model `bson:",inline" json:",inline"`
+	Name             string `bson:"name" json:"name"`
+	Color            string `bson:"color" json:"color"`
+	Secret           string `bson:"secret" json:"-"`
+}
+
+// session stands in for the sessions collection, which isn't audited
+type session struct {
+	repository.Model `bson:",inline" json:",inline"`
+	Token            string `bson:"token" json:"-"`
+}
+
+// entries returns every audit entry, oldest first
+func entries(t *testing.T, repo repository.Repository) []models.AuditEntry {
+	t.Helper()
+
+	list, err := repository.List[models.AuditEntry](context.Background(), repo, bson.D{}, repository.Sort{repository.Asc("timestamp")}, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to list audit entries: %v", err)
+	}
+
+	return list
+}
+
+func TestRepository(t *testing.T) {
+	store := repository.NewMemoryRepository()
+	repo := Record(store)
+
+	actor := models.Actor{UserID: primitive.NewObjectID(), Username: "ada"}
+	ctx := WithActor(context.Background(), actor)
+
+	created, err := repository.Create(ctx, repo, gadget{Name: "Lamp", Color: "red", Secret: "one"})
+	if err != nil {
+		t.Fatalf("Create error = %v", err)
+	}
+
+	byID := bson.D{{Key: "_id", Value: created.ID}}
+
+	updated := created
+	updated.Color = "blue"
+	if err = repo.Update(ctx, &updated, byID); err != nil {
+		t.Fatalf("Update error = %v", err)
+	}
+
+	// Setting a field to the value it already has isn't a change
+	var unchanged gadget
+	if err = repo.UpdateFields(ctx, &unchanged, byID, bson.D{{Key: "color", Value: "blue"}}); err != nil {
+		t.Fatalf("UpdateFields error = %v", err)
+	}
+
+	var secret gadget
+	if err = repo.UpdateFields(context.Background(), &secret, byID, bson.D{{Key: "secret", Value: "two"}}); err != nil {
+		t.Fatalf("UpdateFields error = %v", err)
+	}
+
+	if err = repo.UpdateFields(WithoutAudit(ctx), &secret, byID, bson.D{{Key: "name", Value: "Desk lamp"}}); err != nil {
+		t.Fatalf("UpdateFields error = %v", err)
+	}
+
+	if err = repo.Delete(ctx, &gadget{}, byID); err != nil {
+		t.Fatalf("Delete error = %v", err)
+	}
+
+	if err = repo.Delete(ctx, &gadget{}, byID); !store.IsNotFoundError(err) {
+		t.Fatalf("Delete of a missing document error = %v, want not found", err)
+	}
+
+	if _, err = repository.Create(ctx, repo, session{Token: "token"}); err != nil {
+		t.Fatalf("Create error = %v", err)
+	}
+
+	want := []struct {
+		actor   models.Actor
+		action  string
+		changes []models.Change
+	}{
+		{
+			actor:  actor,
+			action: models.ActionCreate,
+			changes: []models.Change{
+				{Field: "color", After: "red"},
+				{Field: "name", After: "Lamp"},
+				{Field: "secret", After: models.Redacted},
+			},
+		},
+		{
+			actor:   actor,
+			action:  models.ActionUpdate,
+			changes: []models.Change{{Field: "color", Before: "red", After: "blue"}},
+		},
+		{
+			actor:   models.System,
+			action:  models.ActionUpdate,
+			changes: []models.Change{{Field: "secret", Before: models.Redacted, After: models.Redacted}},
+		},
+		{
+			actor:  actor,
+			action: models.ActionDelete,
+			changes: []models.Change{
+				{Field: "color", Before: "blue"},
+				{Field: "name", Before: "Desk lamp"},
+				{Field: "secret", Before: models.Redacted},
+			},
+		},
+	}
+
+	got := entries(t, store)
+	if len(got) != len(want) {
+		t.Fatalf("Audit entries = %+v, want %d", got, len(want))
+	}
+
+	for i, entry := range got {
+		if entry.Collection != "gadgets" || entry.DocumentID != created.ID || entry.Timestamp.IsZero() {
+			t.Errorf("Entry %d = %+v, want one for gadget %v", i, entry, created.ID.Hex())
+		}
+
+		if entry.Actor != want[i].actor || entry.Action != want[i].action {
+			t.Errorf("Entry %d = %v by %+v, want: %v by %+v", i, entry.Action, entry.Actor, want[i].action, want[i].actor)
+		}
+
+		if !reflect.DeepEqual(entry.Changes, want[i].changes) {
+			t.Errorf("Entry %d changes = %+v, want: %+v", i, entry.Changes, want[i].changes)
+		}
+	}
+}
+
+// cancelAfterCreate cancels the request as soon as a document has been created, standing in for a
+// client that disconnects once its change is made
+type cancelAfterCreate struct {
+	repository.Repository
+	cancel context.CancelFunc
+}
+
+func (repo cancelAfterCreate) Create(ctx context.Context, model interface{}) error {
+	defer repo.cancel()
+
+	return repo.Repository.Create(ctx, model)
+}
+
+func TestRepository_cancelledRequest(t *testing.T) {
+	store := repository.NewMemoryRepository()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := Record(cancelAfterCreate{Repository: store, cancel: cancel})
+
+	if _, err := repository.Create(ctx, repo, gadget{Name: "Lamp"}); err != nil {
+		t.Fatalf("Create error = %v", err)
+	}
+
+	if got := entries(t, store); len(got) != 1 || got[0].Action != models.ActionCreate {
+		t.Errorf("Audit entries = %+v, want the create", got)
+	}
+}
@@ -0,0 +1,129 @@
+// Package auth contains the logging in of users and the sessions that keep them logged in
+package auth
+
+import (
+	"Home-Intranet-v2-Backend/internal/audit"
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"Home-Intranet-v2-Backend/internal/platform/logger"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/validation"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+// KeyPrefix starts every API key, so a key pasted somewhere it shouldn't be is easy to spot
+const KeyPrefix = "hik_"
+
+// lastUsedInterval is how out of date a key's last use may get before it is saved again, so a
+// script calling the API every few seconds doesn't write on every request
+const lastUsedInterval = time.Minute
+
+// ErrInvalidAPIKey is returned when an API key is unknown, has been revoked or belongs to a user
+// that has been deleted
+var ErrInvalidAPIKey = errors.New("invalid or revoked api key")
+
+// APIKeys is used to create API keys and to find the user a key belongs to
+type APIKeys struct {
+	Repository repository.Repository
+}
+
+// Create adds an API key for a user, returning the key itself, which is only known to the caller,
+// and the stored key, which only holds its hash. Problems with the name or scopes are returned as
+// validation.Errors.
+func (k APIKeys) Create(ctx context.Context, user models.User, name string, scopes []string) (string, models.APIKey, error) {
+	key := models.APIKey{
+		Name:   name,
+		UserID: user.ID,
+		Scopes: scopes,
+	}
+
+	var fields validation.Errors
+	for _, err := range []error{key.Validate(), ValidateScopes("scopes", scopes)} {
+		var errs validation.Errors
+		if errors.As(err, &errs) {
+			fields = append(fields, errs...)
+		}
+	}
+
+	if err := fields.Err(); err != nil {
+		return "", models.APIKey{}, err
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return "", models.APIKey{}, err
+	}
+
+	token = KeyPrefix + token
+	key.Prefix = token[:len(KeyPrefix)+6]
+	key.KeyHash = HashToken(token)
+
+	key, err = repository.Create(ctx, k.Repository, key)
+	if err != nil {
+		return "", models.APIKey{}, fmt.Errorf("issue creating api key: %w", err)
+	}
+
+	return token, key, nil
+}
+
+// Lookup returns the API key and the user it belongs to, and records that the key was used
+func (k APIKeys) Lookup(ctx context.Context, token string) (models.User, models.APIKey, error) {
+	key, err := repository.Get[models.APIKey](ctx, k.Repository, bson.D{{Key: "key_hash", Value: HashToken(token)}})
+	if k.Repository.IsNotFoundError(err) {
+		return models.User{}, models.APIKey{}, ErrInvalidAPIKey
+	}
+
+	if err != nil {
+		return models.User{}, models.APIKey{}, fmt.Errorf("issue finding api key: %w", err)
+	}
+
+	user, err := repository.Get[models.User](ctx, k.Repository, bson.D{{Key: "_id", Value: key.UserID}})
+	if k.Repository.IsNotFoundError(err) {
+		return models.User{}, models.APIKey{}, ErrInvalidAPIKey
+	}
+
+	if err != nil {
+		return models.User{}, models.APIKey{}, fmt.Errorf("issue finding user: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedInterval {
+		// Noting a key's use isn't a change anyone made, so it is kept out of the audit log. The key
+		// is still valid when it can't be noted, so the request carries on.
+		var used models.APIKey
+		err = k.Repository.UpdateFields(audit.WithoutAudit(ctx), &used, bson.D{{Key: "_id", Value: key.ID}}, bson.D{
+			{Key: "last_used_at", Value: now},
+		})
+		if err != nil {
+			logger.FromContext(ctx).Warn("Issue recording api key use", zap.String("api_key_id", key.ID.Hex()), zap.Error(err))
+		} else {
+			key = used
+		}
+	}
+
+	return user, key, nil
+}
+
+// ValidateScopes checks that every scope names a permission, reporting problems against the named
+// field
+func ValidateScopes(field string, scopes []string) error {
+	var errs validation.Errors
+	for i, scope := range scopes {
+		if !slices.Contains(Permissions, Permission(scope)) {
+			errs = append(errs, validation.FieldError{
+				Field:   field + "." + strconv.Itoa(i),
+				Code:    "unknown_scope",
+				Message: fmt.Sprintf("%q is not a permission", scope),
+			})
+		}
+	}
+
+	return errs.Err()
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/validation"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestAPIKeys_Create(t *testing.T) {
+	sessions, user := newTestSessions(t)
+	keys := APIKeys{Repository: sessions.Repository}
+
+	tests := []struct {
+		name       string
+		keyName    string
+		scopes     []string
+		wantFields []string
+	}{
+		{name: "Valid key", keyName: "Shelf scanner", scopes: []string{"library:read", "library:edit"}},
+		{name: "Missing name", scopes: []string{"library:read"}, wantFields: []string{"name"}},
+		{name: "No scopes", keyName: "Home Assistant", scopes: []string{}, wantFields: []string{"scopes"}},
+		{name: "Unknown scope", keyName: "Home Assistant", scopes: []string{"library:read", "lights:on"}, wantFields: []string{"scopes.1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, key, err := keys.Create(context.Background(), user, tt.keyName, tt.scopes)
+
+			var errs validation.Errors
+			errors.As(err, &errs)
+
+			var gotFields []string
+			for _, fieldError := range errs {
+				gotFields = append(gotFields, fieldError.Field)
+			}
+
+			if !reflect.DeepEqual(gotFields, tt.wantFields) {
+				t.Fatalf("Create fields = %v, want: %v, error: %v", gotFields, tt.wantFields, err)
+			}
+
+			if tt.wantFields != nil {
+				return
+			}
+
+			if !strings.HasPrefix(token, KeyPrefix) || !strings.HasPrefix(token, key.Prefix) {
+				t.Errorf("Create token = %v, want one starting with %v and %v", token, KeyPrefix, key.Prefix)
+			}
+
+			if key.ID.IsZero() || key.UserID != user.ID || key.KeyHash != HashToken(token) {
+				t.Errorf("Create key = %+v, want a stored key for %v holding the token's hash", key, user.ID)
+			}
+		})
+	}
+}
+
+func TestAPIKeys_Lookup(t *testing.T) {
+	sessions, user := newTestSessions(t)
+	keys := APIKeys{Repository: sessions.Repository}
+
+	token, created, err := keys.Create(context.Background(), user, "Shelf scanner", []string{"library:read"})
+	if err != nil {
+		t.Fatalf("Failed to create api key: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "Valid key", token: token},
+		{name: "Unknown key", token: KeyPrefix + "unknown", wantErr: ErrInvalidAPIKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1, got2, err := keys.Lookup(context.Background(), tt.token)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Lookup error = %v, want: %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got1.ID != user.ID || got2.ID != created.ID {
+				t.Errorf("Lookup got = %v, %v, want: %v, %v", got1.ID, got2.ID, user.ID, created.ID)
+			}
+
+			if got2.LastUsedAt == nil || time.Since(*got2.LastUsedAt) > time.Minute {
+				t.Errorf("Lookup last used = %v, want about now", got2.LastUsedAt)
+			}
+		})
+	}
+}
+
+func TestAPIKeys_Lookup_deletedUser(t *testing.T) {
+	sessions, user := newTestSessions(t)
+	keys := APIKeys{Repository: sessions.Repository}
+
+	token, _, err := keys.Create(context.Background(), user, "Shelf scanner", []string{"library:read"})
+	if err != nil {
+		t.Fatalf("Failed to create api key: %v", err)
+	}
+
+	if err = sessions.Repository.Delete(context.Background(), &models.User{}, bson.D{{Key: "_id", Value: user.ID}}); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+
+	if _, _, err = keys.Lookup(context.Background(), token); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Lookup error = %v, want: %v", err, ErrInvalidAPIKey)
+	}
+}
+
+// failingUpdates is a repository whose field updates always fail
+type failingUpdates struct {
+	repository.Repository
+}
+
+func (repo failingUpdates) UpdateFields(context.Context, interface{}, interface{}, bson.D) error {
+	return errors.New("disk full")
+}
+
+func TestAPIKeys_Lookup_lastUsedFails(t *testing.T) {
+	sessions, user := newTestSessions(t)
+	keys := APIKeys{Repository: sessions.Repository}
+
+	token, created, err := keys.Create(context.Background(), user, "Shelf scanner", []string{"library:read"})
+	if err != nil {
+		t.Fatalf("Failed to create api key: %v", err)
+	}
+
+	keys.Repository = failingUpdates{Repository: sessions.Repository}
+
+	got1, got2, err := keys.Lookup(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Lookup error = %v, want: nil", err)
+	}
+
+	if got1.ID != user.ID || got2.ID != created.ID {
+		t.Errorf("Lookup got = %v, %v, want: %v, %v", got1.ID, got2.ID, user.ID, created.ID)
+	}
+}
@@ -0,0 +1,223 @@
+// Package auth contains the logging in of users and the sessions that keep them logged in
+package auth
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// CookieName is the cookie the session token is sent in
+const CookieName = "session"
+
+var (
+	// ErrInvalidCredentials is returned when a username and password don't match a user. It
+	// doesn't say which was wrong, so it can't be used to find out which usernames exist.
+	ErrInvalidCredentials = errors.New("invalid username or password")
+
+	// ErrInvalidSession is returned when a session token is unknown, has expired or belongs to a
+	// user that has been deleted
+	ErrInvalidSession = errors.New("invalid or expired session")
+)
+
+// dummyHash is compared against when a username doesn't exist, so a failed login takes as long
+// whether or not the user exists
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// Sessions is used to log users in and out and to find the user a session belongs to
+type Sessions struct {
+	Repository repository.Repository
+
+	// Lifetime is how long a session lasts after logging in
+	Lifetime time.Duration
+}
+
+// Authenticate returns the user with the username and password
+func (s Sessions) Authenticate(ctx context.Context, username string, password string) (models.User, error) {
+	user, err := repository.Get[models.User](ctx, s.Repository, bson.D{{Key: "username", Value: models.NormalizeUsername(username)}})
+	if s.Repository.IsNotFoundError(err) {
+		bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return models.User{}, ErrInvalidCredentials
+	}
+
+	if err != nil {
+		return models.User{}, fmt.Errorf("issue finding user: %w", err)
+	}
+
+	if !user.CheckPassword(password) {
+		return models.User{}, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+// Start creates a session for a user, returning the token that identifies it. The token is only
+// known to the caller, the session stores its hash.
+func (s Sessions) Start(ctx context.Context, user models.User) (string, models.Session, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", models.Session{}, err
+	}
+
+	session, err := repository.Create(ctx, s.Repository, models.Session{
+		UserID:    user.ID,
+		TokenHash: HashToken(token),
+		ExpiresAt: time.Now().UTC().Add(s.Lifetime),
+	})
+	if err != nil {
+		return "", models.Session{}, fmt.Errorf("issue creating session: %w", err)
+	}
+
+	return token, session, nil
+}
+
+// Lookup returns the user a session token belongs to. Expired sessions are removed when they are
+// found.
+func (s Sessions) Lookup(ctx context.Context, token string) (models.User, error) {
+	session, err := repository.Get[models.Session](ctx, s.Repository, bson.D{{Key: "token_hash", Value: HashToken(token)}})
+	if s.Repository.IsNotFoundError(err) {
+		return models.User{}, ErrInvalidSession
+	}
+
+	if err != nil {
+		return models.User{}, fmt.Errorf("issue finding session: %w", err)
+	}
+
+	if session.Expired(time.Now().UTC()) {
+		if err = s.Repository.Delete(ctx, &models.Session{}, bson.D{{Key: "_id", Value: session.ID}}); err != nil && !s.Repository.IsNotFoundError(err) {
+			return models.User{}, fmt.Errorf("issue removing expired session: %w", err)
+		}
+
+		return models.User{}, ErrInvalidSession
+	}
+
+	user, err := repository.Get[models.User](ctx, s.Repository, bson.D{{Key: "_id", Value: session.UserID}})
+	if s.Repository.IsNotFoundError(err) {
+		return models.User{}, ErrInvalidSession
+	}
+
+	if err != nil {
+		return models.User{}, fmt.Errorf("issue finding user: %w", err)
+	}
+
+	return user, nil
+}
+
+// End logs out the session with the token. Ending a session that doesn't exist isn't an error.
+func (s Sessions) End(ctx context.Context, token string) error {
+	err := s.Repository.Delete(ctx, &models.Session{}, bson.D{{Key: "token_hash", Value: HashToken(token)}})
+	if err != nil && !s.Repository.IsNotFoundError(err) {
+		return fmt.Errorf("issue removing session: %w", err)
+	}
+
+	return nil
+}
+
+// EndOthers logs out every session of a user except the one with the token, such as after the
+// user changes their password
+func (s Sessions) EndOthers(ctx context.Context, userID primitive.ObjectID, token string) error {
+	sessions, err := repository.List[models.Session](ctx, s.Repository, bson.D{
+		{Key: "user_id", Value: userID},
+		{Key: "token_hash", Value: bson.D{{Key: "$ne", Value: HashToken(token)}}},
+	}, nil, 0, 0)
+	if err != nil {
+		return fmt.Errorf("issue finding sessions: %w", err)
+	}
+
+	for _, session := range sessions {
+		err = s.Repository.Delete(ctx, &models.Session{}, bson.D{{Key: "_id", Value: session.ID}})
+		if err != nil && !s.Repository.IsNotFoundError(err) {
+			return fmt.Errorf("issue removing session: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// CreateInitialUser adds an admin when there are no users yet, so a new install can be logged in
+// to. It reports whether the user was created.
+func CreateInitialUser(ctx context.Context, repo repository.Repository, username string, password string) (bool, error) {
+	count, err := repo.Count(ctx, &models.User{}, bson.D{})
+	if err != nil {
+		return false, fmt.Errorf("issue counting users: %w", err)
+	}
+
+	if count > 0 {
+		return false, nil
+	}
+
+	user := models.User{
+		Username:    models.NormalizeUsername(username),
+		DisplayName: username,
+		Role:        models.RoleAdmin,
+	}
+
+	if err = user.SetPassword(password); err != nil {
+		return false, fmt.Errorf("issue setting initial password: %w", err)
+	}
+
+	if err = user.Validate(); err != nil {
+		return false, fmt.Errorf("issue validating initial user: %w", err)
+	}
+
+	if _, err = repository.Create(ctx, repo, user); err != nil {
+		return false, fmt.Errorf("issue creating initial user: %w", err)
+	}
+
+	return true, nil
+}
+
+// EnsureIndexes creates the unique indexes the users, sessions and API keys rely on, so a username,
+// linked identity, session token or API key can't be stored twice even by concurrent requests
+func EnsureIndexes(ctx context.Context, repo repository.Repository) error {
+	indexes := []struct {
+		model interface{}
+		field string
+	}{
+		{&models.User{}, "username"},
+		{&models.User{}, "oidc_subject"},
+		{&models.Session{}, "token_hash"},
+		{&models.APIKey{}, "key_hash"},
+	}
+
+	for _, index := range indexes {
+		if err := repo.EnsureUniqueIndex(ctx, index.model, index.field); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HashToken returns the form a session token is stored in. Tokens are random, so a fast hash is
+// enough to keep them from being read back.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// newToken returns a random token to identify a session or API key
+func newToken() (string, error) {
+	data := make([]byte, 32)
+	if _, err := rand.Read(data); err != nil {
+		return "", fmt.Errorf("issue generating token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// newTestSessions returns sessions backed by an in memory repository holding one user, ada, whose
+// password is "correct horse"
+func newTestSessions(t *testing.T) (Sessions, models.User) {
+	t.Helper()
+
+	repo := repository.NewMemoryRepository()
+
+	if err := EnsureIndexes(context.Background(), repo); err != nil {
+		t.Fatalf("EnsureIndexes error = %v", err)
+	}
+
+	created, err := CreateInitialUser(context.Background(), repo, "Ada", "correct horse")
+	if err != nil || !created {
+		t.Fatalf("CreateInitialUser = %v, %v, want: true, nil", created, err)
+	}
+
+	user, err := repository.Get[models.User](context.Background(), repo, bson.D{{Key: "username", Value: "ada"}})
+	if err != nil {
+		t.Fatalf("Failed to read initial user: %v", err)
+	}
+
+	return Sessions{Repository: repo, Lifetime: time.Hour}, user
+}
+
+func TestSessions_Authenticate(t *testing.T) {
+	sessions, user := newTestSessions(t)
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  error
+	}{
+		{name: "Valid credentials", username: "ada", password: "correct horse"},
+		{name: "Username in another case", username: " ADA", password: "correct horse"},
+		{name: "Wrong password", username: "ada", password: "battery staple", wantErr: ErrInvalidCredentials},
+		{name: "Unknown user", username: "grace", password: "correct horse", wantErr: ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1, err := sessions.Authenticate(context.Background(), tt.username, tt.password)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate error = %v, want: %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && got1.ID != user.ID {
+				t.Errorf("Authenticate got1 = %v, want1: %v", got1.ID, user.ID)
+			}
+		})
+	}
+}
+
+func TestSessions_lifecycle(t *testing.T) {
+	ctx := context.Background()
+	sessions, user := newTestSessions(t)
+
+	token, session, err := sessions.Start(ctx, user)
+	if err != nil {
+		t.Fatalf("Start error = %v", err)
+	}
+
+	if session.TokenHash == token || session.TokenHash != HashToken(token) {
+		t.Errorf("Start stored %q, want the hash of the token", session.TokenHash)
+	}
+
+	other, _, err := sessions.Start(ctx, user)
+	if err != nil {
+		t.Fatalf("Start error = %v", err)
+	}
+
+	got1, err := sessions.Lookup(ctx, token)
+	if err != nil || got1.ID != user.ID {
+		t.Fatalf("Lookup = %v, %v, want: %v, nil", got1.ID, err, user.ID)
+	}
+
+	if err = sessions.EndOthers(ctx, user.ID, token); err != nil {
+		t.Fatalf("EndOthers error = %v", err)
+	}
+
+	if _, err = sessions.Lookup(ctx, other); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("Lookup of another session after EndOthers error = %v, want: %v", err, ErrInvalidSession)
+	}
+
+	if _, err = sessions.Lookup(ctx, token); err != nil {
+		t.Errorf("Lookup of the kept session after EndOthers error = %v, want: nil", err)
+	}
+
+	if err = sessions.End(ctx, token); err != nil {
+		t.Fatalf("End error = %v", err)
+	}
+
+	if _, err = sessions.Lookup(ctx, token); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("Lookup after End error = %v, want: %v", err, ErrInvalidSession)
+	}
+
+	if err = sessions.End(ctx, token); err != nil {
+		t.Errorf("End of an ended session error = %v, want: nil", err)
+	}
+}
+
+func TestSessions_Lookup_expired(t *testing.T) {
+	ctx := context.Background()
+	sessions, user := newTestSessions(t)
+	sessions.Lifetime = -time.Minute
+
+	token, _, err := sessions.Start(ctx, user)
+	if err != nil {
+		t.Fatalf("Start error = %v", err)
+	}
+
+	if _, err = sessions.Lookup(ctx, token); !errors.Is(err, ErrInvalidSession) {
+		t.Fatalf("Lookup error = %v, want: %v", err, ErrInvalidSession)
+	}
+
+	count, err := sessions.Repository.Count(ctx, &models.Session{}, bson.D{})
+	if err != nil || count != 0 {
+		t.Errorf("Sessions left = %v, %v, want the expired session removed", count, err)
+	}
+}
+
+func TestCreateInitialUser(t *testing.T) {
+	sessions, _ := newTestSessions(t)
+
+	created, err := CreateInitialUser(context.Background(), sessions.Repository, "grace", "another password")
+	if err != nil || created {
+		t.Errorf("CreateInitialUser = %v, %v, want: false, nil when users exist", created, err)
+	}
+
+	if _, err = CreateInitialUser(context.Background(), repository.NewMemoryRepository(), "grace", "short"); err == nil {
+		t.Error("CreateInitialUser error = nil, want an error for a short password")
+	}
+}
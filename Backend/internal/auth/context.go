@@ -0,0 +1,39 @@
+// Package auth contains the logging in of users and the sessions that keep them logged in
+package auth
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"context"
+)
+
+// userKey is the context key of the user a request was made by
+type userKey struct{}
+
+// apiKeyKey is the context key of the API key a request was made with
+type apiKeyKey struct{}
+
+// WithUser returns a copy of ctx carrying the user a request was made by
+func WithUser(ctx context.Context, user models.User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFromContext returns the user a request was made by, and false when it wasn't made by a
+// logged in user
+func UserFromContext(ctx context.Context) (models.User, bool) {
+	user, ok := ctx.Value(userKey{}).(models.User)
+
+	return user, ok
+}
+
+// WithAPIKey returns a copy of ctx carrying the API key a request was made with
+func WithAPIKey(ctx context.Context, key models.APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyKey{}, key)
+}
+
+// APIKeyFromContext returns the API key a request was made with, and false when it was made
+// without one, such as from a browser session
+func APIKeyFromContext(ctx context.Context) (models.APIKey, bool) {
+	key, ok := ctx.Value(apiKeyKey{}).(models.APIKey)
+
+	return key, ok
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"context"
+	"testing"
+)
+
+func TestUserFromContext(t *testing.T) {
+	if _, ok := UserFromContext(context.Background()); ok {
+		t.Error("UserFromContext ok = true for a context without a user")
+	}
+
+	ctx := WithUser(context.Background(), models.User{Username: "ada"})
+
+	got1, ok := UserFromContext(ctx)
+	if !ok || got1.Username != "ada" {
+		t.Errorf("UserFromContext got1 = %v, %v, want1: ada, true", got1.Username, ok)
+	}
+}
+
+func TestAPIKeyFromContext(t *testing.T) {
+	if _, ok := APIKeyFromContext(context.Background()); ok {
+		t.Error("APIKeyFromContext ok = true for a context without a key")
+	}
+
+	ctx := WithAPIKey(context.Background(), models.APIKey{Name: "Shelf scanner"})
+
+	got1, ok := APIKeyFromContext(ctx)
+	if !ok || got1.Name != "Shelf scanner" {
+		t.Errorf("APIKeyFromContext got1 = %v, %v, want1: Shelf scanner, true", got1.Name, ok)
+	}
+}
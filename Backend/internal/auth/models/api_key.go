@@ -0,0 +1,34 @@
+// Package models stores all of our models for user accounts
+package models
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/validation"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKey lets a script, such as a shelf barcode scanner or Home Assistant, call the API without
+// logging in. A key acts as the user who created it, limited to its scopes. Only a hash of the key
+// is stored, along with its first few characters so it can be recognised in a list.
+type APIKey struct {
+	repository.Model `bson:",inline" json:",inline"`
+	Name             string             `bson:"name" json:"name" validate:"required,max=100"`
+	UserID           primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Scopes           []string           `bson:"scopes" json:"scopes" validate:"min=1"`
+	Prefix           string             `bson:"prefix" json:"prefix"`
+	KeyHash          string             `bson:"key_hash" json:"-"`
+	LastUsedAt       *time.Time         `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+}
+
+// Validate checks the key against its rules, returning every field error at once
+func (k APIKey) Validate() error {
+	return validation.Struct(k).Err()
+}
+
+// HasScope reports whether the key may be used for a permission
+func (k APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/validation"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAPIKey_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        APIKey
+		wantFields []string
+	}{
+		{
+			name: "Valid key",
+			key:  APIKey{Name: "Shelf scanner", Scopes: []string{"library:read"}},
+		},
+		{
+			name:       "Missing name",
+			key:        APIKey{Scopes: []string{"library:read"}},
+			wantFields: []string{"name"},
+		},
+		{
+			name:       "No scopes",
+			key:        APIKey{Name: "Home Assistant", Scopes: []string{}},
+			wantFields: []string{"scopes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.key.Validate()
+
+			var errs validation.Errors
+			errors.As(err, &errs)
+
+			var gotFields []string
+			for _, fieldError := range errs {
+				gotFields = append(gotFields, fieldError.Field)
+			}
+
+			if !reflect.DeepEqual(gotFields, tt.wantFields) {
+				t.Errorf("Validate fields = %v, want: %v", gotFields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestAPIKey_HasScope(t *testing.T) {
+	key := APIKey{Scopes: []string{"library:read", "library:borrow"}}
+
+	tests := []struct {
+		name  string
+		scope string
+		want1 bool
+	}{
+		{name: "Granted scope", scope: "library:borrow", want1: true},
+		{name: "Missing scope", scope: "library:delete", want1: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1 := key.HasScope(tt.scope)
+
+			if got1 != tt.want1 {
+				t.Errorf("HasScope got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+// Package models stores all of our models for user accounts
+package models
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Session is a login of a user. Only a hash of its token is stored, so the sessions collection
+// can't be used to log in if it leaks.
+type Session struct {
+	repository.Model `bson:",inline" json:",inline"`
+	UserID           primitive.ObjectID `bson:"user_id" json:"user_id"`
+	TokenHash        string             `bson:"token_hash" json:"-"`
+	ExpiresAt        time.Time          `bson:"expires_at" json:"expires_at"`
+}
+
+// Expired reports whether the session can no longer be used
+func (s Session) Expired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSession_Expired(t *testing.T) {
+	expires := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		now   time.Time
+		want1 bool
+	}{
+		{name: "Before it expires", now: expires.Add(-time.Second), want1: false},
+		{name: "When it expires", now: expires, want1: true},
+		{name: "After it expires", now: expires.Add(time.Hour), want1: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1 := Session{ExpiresAt: expires}.Expired(tt.now)
+
+			if got1 != tt.want1 {
+				t.Errorf("Expired got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// Package models stores all of our models for user accounts
+package models
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/validation"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// The limits on a password. bcrypt only reads the first 72 bytes, so anything longer would be
+// silently cut short.
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+// The roles a user can have. Admins can do anything, members can browse and borrow books and
+// guests can only browse.
+const (
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+	RoleGuest  = "guest"
+)
+
+// User is a member of the household who can log in. The password is only ever stored as a bcrypt
+// hash, which is never sent to the user. Users who log in through the identity provider are
+// linked to it by their subject, and have no password.
+type User struct {
+	repository.Model `bson:",inline" json:",inline"`
+	Username         string `bson:"username" json:"username" validate:"required,max=50"`
+	DisplayName      string `bson:"display_name" json:"display_name" validate:"max=100"`
+	Role             string `bson:"role" json:"role" validate:"required,oneof=admin member guest"`
+	PasswordHash     string `bson:"password_hash" json:"-"`
+	OIDCSubject      string `bson:"oidc_subject,omitempty" json:"-"`
+}
+
+// NormalizeUsername returns the form usernames are stored and looked up in, so logging in isn't
+// case sensitive
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// Name returns the name the user is shown by
+func (u User) Name() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+
+	return u.Username
+}
+
+// Validate checks the user against its rules, returning every field error at once
+func (u User) Validate() error {
+	return validation.Struct(u).Err()
+}
+
+// SetPassword hashes a new password for the user
+func (u *User) SetPassword(password string) error {
+	if err := ValidatePassword("password", password); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	u.PasswordHash = string(hash)
+
+	return nil
+}
+
+// CheckPassword reports whether the password matches the user's hash
+func (u User) CheckPassword(password string) bool {
+	err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password))
+
+	return err == nil
+}
+
+// ValidatePassword checks the length of a password, reporting problems against the named field
+func ValidatePassword(field string, password string) error {
+	switch {
+	case len(password) < MinPasswordLength:
+		return validation.Errors{{Field: field, Code: "min_length", Message: "must be at least 8 characters"}}
+	case len(password) > MaxPasswordLength:
+		return validation.Errors{{Field: field, Code: "max_length", Message: "must be at most 72 bytes"}}
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/validation"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		want1    string
+	}{
+		{name: "Already normal", username: "ada", want1: "ada"},
+		{name: "Mixed case and spaces", username: "  Ada.Lovelace ", want1: "ada.lovelace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1 := NormalizeUsername(tt.username)
+
+			if got1 != tt.want1 {
+				t.Errorf("NormalizeUsername got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestUser_SetPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantCode string
+	}{
+		{name: "Valid password", password: "correct horse"},
+		{name: "Too short", password: "short", wantCode: "min_length"},
+		{name: "Too long", password: strings.Repeat("a", 73), wantCode: "max_length"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var user User
+			err := user.SetPassword(tt.password)
+
+			if tt.wantCode != "" {
+				var fields validation.Errors
+				if !errors.As(err, &fields) || len(fields) != 1 || fields[0].Code != tt.wantCode {
+					t.Fatalf("SetPassword error = %v, want: %s", err, tt.wantCode)
+				}
+
+				if user.PasswordHash != "" {
+					t.Error("SetPassword set a hash for an invalid password")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("SetPassword error = %v, want: nil", err)
+			}
+
+			if user.PasswordHash == tt.password {
+				t.Error("SetPassword stored the password rather than a hash")
+			}
+
+			if !user.CheckPassword(tt.password) {
+				t.Error("CheckPassword = false for the password that was set")
+			}
+
+			if user.CheckPassword(tt.password + "!") {
+				t.Error("CheckPassword = true for a different password")
+			}
+		})
+	}
+}
+
+func TestUser_CheckPassword_noPassword(t *testing.T) {
+	if (User{}).CheckPassword("") {
+		t.Error("CheckPassword = true for a user without a password")
+	}
+}
+
+func TestUser_Name(t *testing.T) {
+	tests := []struct {
+		name  string
+		user  User
+		want1 string
+	}{
+		{name: "Display name", user: User{Username: "ada", DisplayName: "Ada Lovelace"}, want1: "Ada Lovelace"},
+		{name: "Falls back to the username", user: User{Username: "ada"}, want1: "ada"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1 := tt.user.Name()
+
+			if got1 != tt.want1 {
+				t.Errorf("Name got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestUser_MarshalJSON(t *testing.T) {
+	user := User{Username: "ada"}
+	if err := user.SetPassword("correct horse"); err != nil {
+		t.Fatalf("SetPassword error = %v", err)
+	}
+
+	data, err := json.Marshal(user)
+	if err != nil {
+		t.Fatalf("Marshal error = %v", err)
+	}
+
+	if strings.Contains(string(data), "password") || strings.Contains(string(data), user.PasswordHash) {
+		t.Errorf("Marshal = %s, want the password hash left out", data)
+	}
+}
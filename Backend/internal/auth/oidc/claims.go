@@ -0,0 +1,66 @@
+// Package oidc logs users in through an OpenID Connect identity provider, using the authorization
+// code flow with PKCE. The provider's endpoints are found through discovery, and ID tokens are
+// checked against the keys it publishes.
+package oidc
+
+import (
+	"slices"
+)
+
+// Claims is what a verified ID token says about the user
+type Claims struct {
+	Subject  string `json:"sub"`
+	Username string `json:"preferred_username"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+
+	// raw is every claim, so the role claim can be any of them
+	raw map[string]interface{}
+}
+
+// Values returns the values of a claim, which may be a single string or a list of them
+func (c Claims) Values(name string) []string {
+	switch value := c.raw[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		var values []string
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+
+		return values
+	}
+
+	return nil
+}
+
+// preferredUsername returns the name the user would like to be known by, falling back to their
+// email address and then to their subject
+func (c Claims) preferredUsername() string {
+	switch {
+	case c.Username != "":
+		return c.Username
+	case c.Email != "":
+		return c.Email
+	}
+
+	return c.Subject
+}
+
+// Role returns the role the claims give, and false when they give none and there is no default
+func (p *Provider) Role(claims Claims) (string, bool) {
+	values := claims.Values(p.config.RoleClaim)
+
+	for _, role := range p.config.RoleValues() {
+		for _, value := range role.Values {
+			if slices.Contains(values, value) {
+				return role.Role, true
+			}
+		}
+	}
+
+	return p.config.DefaultRole, p.config.DefaultRole != ""
+}
@@ -0,0 +1,65 @@
+package oidc
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/config"
+	"reflect"
+	"testing"
+)
+
+func TestClaims_Values(t *testing.T) {
+	claims := Claims{raw: map[string]interface{}{
+		"groups": []interface{}{"family", 3, "trove-admins"},
+		"role":   "member",
+	}}
+
+	tests := []struct {
+		name  string
+		claim string
+		want1 []string
+	}{
+		{name: "List", claim: "groups", want1: []string{"family", "trove-admins"}},
+		{name: "Single value", claim: "role", want1: []string{"member"}},
+		{name: "Missing", claim: "roles", want1: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1 := claims.Values(tt.claim)
+
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("Values got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestProvider_Role(t *testing.T) {
+	cfg := config.Default().Auth.OIDC
+	cfg.AdminValues = "trove-admins"
+	cfg.MemberValues = "family, friends"
+
+	tests := []struct {
+		name        string
+		groups      []interface{}
+		defaultRole string
+		want1       string
+		want2       bool
+	}{
+		{name: "Admin is checked first", groups: []interface{}{"family", "trove-admins"}, want1: "admin", want2: true},
+		{name: "Member", groups: []interface{}{"friends"}, want1: "member", want2: true},
+		{name: "No match without a default", groups: []interface{}{"neighbours"}, want1: "", want2: false},
+		{name: "No match with a default", groups: []interface{}{"neighbours"}, defaultRole: "guest", want1: "guest", want2: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.DefaultRole = tt.defaultRole
+
+			got1, got2 := New(cfg, nil).Role(Claims{raw: map[string]interface{}{"groups": tt.groups}})
+
+			if got1 != tt.want1 || got2 != tt.want2 {
+				t.Errorf("Role got = %v, %v, want: %v, %v", got1, got2, tt.want1, tt.want2)
+			}
+		})
+	}
+}
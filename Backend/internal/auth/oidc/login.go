@@ -0,0 +1,82 @@
+// Package oidc logs users in through an OpenID Connect identity provider, using the authorization
+// code flow with PKCE. The provider's endpoints are found through discovery, and ID tokens are
+// checked against the keys it publishes.
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidLogin is returned when an encoded login can't be read
+var ErrInvalidLogin = errors.New("invalid login")
+
+// Login is the random values of one login. The browser keeps them in a cookie while it is at the
+// provider, so the callback can check the provider is answering the login it started.
+type Login struct {
+	// State is sent to the provider and back, tying the callback to the browser
+	State string
+
+	// Nonce is put in the ID token by the provider, tying the token to the login
+	Nonce string
+
+	// Verifier is the PKCE secret whose hash is sent with the login, and which has to be shown to
+	// exchange the code for tokens
+	Verifier string
+
+	// LinkUserID is the hex id of the logged in user who started the login to link the provider to
+	// their account, or empty for a plain login
+	LinkUserID string
+}
+
+// NewLogin returns a login with new random values
+func NewLogin() (Login, error) {
+	var values [3]string
+	for i := range values {
+		data := make([]byte, 32)
+		if _, err := rand.Read(data); err != nil {
+			return Login{}, fmt.Errorf("issue generating login: %w", err)
+		}
+
+		values[i] = base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	return Login{State: values[0], Nonce: values[1], Verifier: values[2]}, nil
+}
+
+// Challenge returns the S256 PKCE challenge of the verifier
+func (l Login) Challenge() string {
+	sum := sha256.Sum256([]byte(l.Verifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Encode returns the login as a cookie value
+func (l Login) Encode() string {
+	value := l.State + "." + l.Nonce + "." + l.Verifier
+	if l.LinkUserID != "" {
+		value += "." + l.LinkUserID
+	}
+
+	return value
+}
+
+// ParseLogin reads a login written by Encode
+func ParseLogin(value string) (Login, error) {
+	parts := strings.Split(value, ".")
+	if len(parts) < 3 || len(parts) > 4 || slices.Contains(parts, "") {
+		return Login{}, ErrInvalidLogin
+	}
+
+	login := Login{State: parts[0], Nonce: parts[1], Verifier: parts[2]}
+	if len(parts) == 4 {
+		login.LinkUserID = parts[3]
+	}
+
+	return login, nil
+}
@@ -0,0 +1,65 @@
+package oidc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewLogin(t *testing.T) {
+	first, err := NewLogin()
+	if err != nil {
+		t.Fatalf("NewLogin error = %v", err)
+	}
+
+	second, err := NewLogin()
+	if err != nil {
+		t.Fatalf("NewLogin error = %v", err)
+	}
+
+	if first == second || first.State == first.Nonce || first.Nonce == first.Verifier {
+		t.Errorf("NewLogin got = %+v and %+v, want different random values", first, second)
+	}
+}
+
+func TestLogin_Challenge(t *testing.T) {
+	// The example from RFC 7636 appendix B
+	login := Login{Verifier: "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"}
+
+	if got1, want1 := login.Challenge(), "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got1 != want1 {
+		t.Errorf("Challenge got1 = %v, want1: %v", got1, want1)
+	}
+}
+
+func TestParseLogin(t *testing.T) {
+	login := Login{State: "state", Nonce: "nonce", Verifier: "verifier"}
+	link := Login{State: "state", Nonce: "nonce", Verifier: "verifier", LinkUserID: "user"}
+
+	tests := []struct {
+		name    string
+		value   string
+		want1   Login
+		wantErr error
+	}{
+		{name: "Encoded login", value: login.Encode(), want1: login},
+		{name: "Encoded link", value: link.Encode(), want1: link},
+		{name: "Too many parts", value: "state.nonce.verifier.user.extra", wantErr: ErrInvalidLogin},
+		{name: "Empty link user", value: "state.nonce.verifier.", wantErr: ErrInvalidLogin},
+		{name: "Empty", value: "", wantErr: ErrInvalidLogin},
+		{name: "Missing verifier", value: "state.nonce", wantErr: ErrInvalidLogin},
+		{name: "Empty part", value: "state..verifier", wantErr: ErrInvalidLogin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1, err := ParseLogin(tt.value)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseLogin error = %v, want: %v", err, tt.wantErr)
+			}
+
+			if got1 != tt.want1 {
+				t.Errorf("ParseLogin got1 = %+v, want1: %+v", got1, tt.want1)
+			}
+		})
+	}
+}
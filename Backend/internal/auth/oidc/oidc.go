@@ -0,0 +1,417 @@
+// Package oidc logs users in through an OpenID Connect identity provider, using the authorization
+// code flow with PKCE. The provider's endpoints are found through discovery, and ID tokens are
+// checked against the keys it publishes.
+package oidc
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/config"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clockSkew is how far the provider's clock may be from ours when checking when a token expires
+const clockSkew = time.Minute
+
+// keysRefreshInterval is the least time between fetching the provider's keys because a token was
+// signed by one we don't know, so tokens with made up key ids can't make us fetch on every request
+const keysRefreshInterval = time.Minute
+
+var (
+	// ErrLoginRejected is returned when the provider refuses to exchange a code for tokens, such as
+	// when the code was already used or the PKCE verifier doesn't match
+	ErrLoginRejected = errors.New("the identity provider rejected the login")
+
+	// ErrInvalidToken is returned when an ID token isn't signed by the provider, isn't meant for
+	// us, has expired or doesn't belong to the login
+	ErrInvalidToken = errors.New("invalid id token")
+)
+
+// Provider is an OpenID Connect identity provider. Its endpoints and keys are fetched the first
+// time they are needed, so the service can start while the provider is down.
+type Provider struct {
+	config config.OIDC
+	client *http.Client
+	now    func() time.Time
+
+	mu          sync.Mutex
+	discovery   *discovery
+	keys        map[string]crypto.PublicKey
+	keysFetched time.Time
+}
+
+// discovery is the part of the provider's discovery document that is used
+type discovery struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	CodeChallengeMethods  []string `json:"code_challenge_methods_supported"`
+}
+
+// New returns the provider in the configuration. A nil client uses one with a short timeout.
+func New(cfg config.OIDC, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &Provider{
+		config: cfg,
+		client: client,
+		now:    time.Now,
+	}
+}
+
+// LoginRedirect returns where the browser is sent once it has logged in
+func (p *Provider) LoginRedirect() string {
+	return p.config.LoginRedirect
+}
+
+// AuthCodeURL returns the address of the provider's login page for a login
+func (p *Provider) AuthCodeURL(ctx context.Context, login Login) (string, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {p.config.Scopes},
+		"state":                 {login.State},
+		"nonce":                 {login.Nonce},
+		"code_challenge":        {login.Challenge()},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return doc.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange swaps the code the provider sent back for an ID token, returning its claims once the
+// token has been verified
+func (p *Provider) Exchange(ctx context.Context, code string, login Login) (Claims, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"client_id":     {p.config.ClientID},
+		"code_verifier": {login.Verifier},
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Claims{}, fmt.Errorf("issue building token request: %w", err)
+	}
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		request.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return Claims{}, fmt.Errorf("issue requesting tokens: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return Claims{}, fmt.Errorf("issue reading token response: %w", err)
+	}
+
+	var tokens struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+
+	if err = json.Unmarshal(body, &tokens); err != nil {
+		return Claims{}, fmt.Errorf("issue reading token response: %w", err)
+	}
+
+	if response.StatusCode >= 400 && response.StatusCode < 500 && tokens.Error != "" {
+		return Claims{}, fmt.Errorf("%w: %s %s", ErrLoginRejected, tokens.Error, tokens.ErrorDescription)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return Claims{}, fmt.Errorf("token endpoint returned %s", response.Status)
+	}
+
+	if tokens.IDToken == "" {
+		return Claims{}, fmt.Errorf("%w: the token response has no id_token", ErrInvalidToken)
+	}
+
+	return p.Verify(ctx, tokens.IDToken, login.Nonce)
+}
+
+// Verify checks an ID token's signature against the provider's keys, and that it was issued by
+// the provider to us for the login with the nonce and hasn't expired, returning its claims
+func (p *Provider) Verify(ctx context.Context, token string, nonce string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, fmt.Errorf("%w: not a signed JWT", ErrInvalidToken)
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Claims{}, fmt.Errorf("%w: header: %s", ErrInvalidToken, err)
+	}
+
+	// Only RS256 is accepted, which every provider has to support, so a token can't pick a weaker
+	// algorithm or none at all
+	if header.Algorithm != "RS256" {
+		return Claims{}, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, header.Algorithm)
+	}
+
+	key, err := p.key(ctx, header.KeyID)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: signature: %s", ErrInvalidToken, err)
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return Claims{}, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	var registered struct {
+		Issuer          string   `json:"iss"`
+		Audience        audience `json:"aud"`
+		AuthorizedParty string   `json:"azp"`
+		Expiry          float64  `json:"exp"`
+		Nonce           string   `json:"nonce"`
+	}
+
+	if err = decodeSegment(parts[1], &registered); err != nil {
+		return Claims{}, fmt.Errorf("%w: claims: %s", ErrInvalidToken, err)
+	}
+
+	now := p.now()
+
+	switch {
+	case registered.Issuer != p.config.Issuer:
+		return Claims{}, fmt.Errorf("%w: issued by %q", ErrInvalidToken, registered.Issuer)
+	case !slices.Contains(registered.Audience, p.config.ClientID):
+		return Claims{}, fmt.Errorf("%w: not meant for this client", ErrInvalidToken)
+	case len(registered.Audience) > 1 && registered.AuthorizedParty != p.config.ClientID:
+		return Claims{}, fmt.Errorf("%w: authorized for %q", ErrInvalidToken, registered.AuthorizedParty)
+	case registered.Expiry == 0 || now.After(time.Unix(int64(registered.Expiry), 0).Add(clockSkew)):
+		return Claims{}, fmt.Errorf("%w: expired", ErrInvalidToken)
+	case nonce == "" || registered.Nonce != nonce:
+		return Claims{}, fmt.Errorf("%w: belongs to another login", ErrInvalidToken)
+	}
+
+	var claims Claims
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return Claims{}, fmt.Errorf("%w: claims: %s", ErrInvalidToken, err)
+	}
+
+	if err = decodeSegment(parts[1], &claims.raw); err != nil {
+		return Claims{}, fmt.Errorf("%w: claims: %s", ErrInvalidToken, err)
+	}
+
+	if claims.Subject == "" {
+		return Claims{}, fmt.Errorf("%w: no subject", ErrInvalidToken)
+	}
+
+	return claims, nil
+}
+
+// getDiscovery returns the provider's discovery document, fetching it the first time
+func (p *Provider) getDiscovery(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var doc discovery
+	if err := p.getJSON(ctx, strings.TrimSuffix(p.config.Issuer, "/")+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, fmt.Errorf("issue discovering identity provider: %w", err)
+	}
+
+	switch {
+	case doc.Issuer != p.config.Issuer:
+		return nil, fmt.Errorf("identity provider says its issuer is %q, not %q", doc.Issuer, p.config.Issuer)
+	case doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "":
+		return nil, errors.New("identity provider discovery is missing an endpoint")
+	case len(doc.CodeChallengeMethods) > 0 && !slices.Contains(doc.CodeChallengeMethods, "S256"):
+		return nil, errors.New("identity provider doesn't support S256 PKCE")
+	}
+
+	p.discovery = &doc
+
+	return p.discovery, nil
+}
+
+// key returns the provider's key with the id, fetching the keys again when it isn't known, such
+// as after the provider rotates them
+func (p *Provider) key(ctx context.Context, keyID string) (*rsa.PublicKey, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, ok := p.findKey(keyID)
+	if !ok && p.now().Sub(p.keysFetched) >= keysRefreshInterval {
+		var set struct {
+			Keys []jsonWebKey `json:"keys"`
+		}
+
+		if err = p.getJSON(ctx, doc.JWKSURI, &set); err != nil {
+			return nil, fmt.Errorf("issue fetching identity provider keys: %w", err)
+		}
+
+		p.keys = make(map[string]crypto.PublicKey, len(set.Keys))
+		for _, jwk := range set.Keys {
+			if public, ok := jwk.publicKey(); ok {
+				p.keys[jwk.KeyID] = public
+			}
+		}
+		p.keysFetched = p.now()
+
+		key, ok = p.findKey(keyID)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, keyID)
+	}
+
+	return key, nil
+}
+
+// findKey returns the key with the id. A token without a key id can only be checked when the
+// provider has a single key.
+func (p *Provider) findKey(keyID string) (*rsa.PublicKey, bool) {
+	if keyID == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			public, ok := key.(*rsa.PublicKey)
+			return public, ok
+		}
+	}
+
+	public, ok := p.keys[keyID].(*rsa.PublicKey)
+
+	return public, ok
+}
+
+// getJSON fetches a JSON document from the provider
+func (p *Provider) getJSON(ctx context.Context, address string, value interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Accept", "application/json")
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", address, response.Status)
+	}
+
+	return json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(value)
+}
+
+// jsonWebKey is a key the provider signs tokens with, as published in its key set
+type jsonWebKey struct {
+	KeyType  string `json:"kty"`
+	KeyID    string `json:"kid"`
+	Use      string `json:"use"`
+	Modulus  string `json:"n"`
+	Exponent string `json:"e"`
+}
+
+// publicKey returns the RSA signing key, and false for any other kind of key
+func (k jsonWebKey) publicKey() (crypto.PublicKey, bool) {
+	if k.KeyType != "RSA" || (k.Use != "" && k.Use != "sig") {
+		return nil, false
+	}
+
+	modulus, err := base64.RawURLEncoding.DecodeString(k.Modulus)
+	if err != nil || len(modulus) == 0 {
+		return nil, false
+	}
+
+	exponent, err := base64.RawURLEncoding.DecodeString(k.Exponent)
+	if err != nil || len(exponent) == 0 || len(exponent) > 4 {
+		return nil, false
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(new(big.Int).SetBytes(exponent).Int64()),
+	}, true
+}
+
+// audience is the aud claim, which may be a single string or a list
+type audience []string
+
+// UnmarshalJSON reads either form of the claim
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	*a = list
+
+	return nil
+}
+
+// decodeSegment decodes a base64url part of a JWT as JSON
+func decodeSegment(segment string, value interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, value)
+}
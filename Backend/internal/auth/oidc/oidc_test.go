@@ -0,0 +1,216 @@
+package oidc
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth/oidc/oidctest"
+	"Home-Intranet-v2-Backend/internal/platform/config"
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestProvider returns a provider configured for a stand-in identity provider, which the test
+// can change the claims of
+func newTestProvider(t *testing.T) (*Provider, *oidctest.Provider) {
+	t.Helper()
+
+	stand := oidctest.NewProvider(t, "trove", "s3cret")
+
+	cfg := config.Default().Auth.OIDC
+	cfg.Issuer = stand.Issuer()
+	cfg.ClientID = "trove"
+	cfg.ClientSecret = "s3cret"
+	cfg.RedirectURL = "https://api-trove.intranet.dev/v1/auth/oidc/callback"
+	cfg.AdminValues = "trove-admins"
+	cfg.MemberValues = "family"
+
+	return New(cfg, stand.Client()), stand
+}
+
+// newTestLogin returns a login, failing the test if one can't be made
+func newTestLogin(t *testing.T) Login {
+	t.Helper()
+
+	login, err := NewLogin()
+	if err != nil {
+		t.Fatalf("NewLogin error = %v", err)
+	}
+
+	return login
+}
+
+func TestProvider_AuthCodeURL(t *testing.T) {
+	provider, stand := newTestProvider(t)
+	login := newTestLogin(t)
+
+	got1, err := provider.AuthCodeURL(context.Background(), login)
+	if err != nil {
+		t.Fatalf("AuthCodeURL error = %v", err)
+	}
+
+	address, err := url.Parse(got1)
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", got1, err)
+	}
+
+	if !strings.HasPrefix(got1, stand.URL+"/authorize?") {
+		t.Errorf("AuthCodeURL got1 = %v, want the provider's authorization endpoint", got1)
+	}
+
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "trove",
+		"redirect_uri":          "https://api-trove.intranet.dev/v1/auth/oidc/callback",
+		"scope":                 "openid profile email",
+		"state":                 login.State,
+		"nonce":                 login.Nonce,
+		"code_challenge":        login.Challenge(),
+		"code_challenge_method": "S256",
+	}
+	for name, value := range want {
+		if got := address.Query().Get(name); got != value {
+			t.Errorf("AuthCodeURL %s = %q, want: %q", name, got, value)
+		}
+	}
+}
+
+func TestProvider_AuthCodeURL_wrongIssuer(t *testing.T) {
+	stand := oidctest.NewProvider(t, "trove", "")
+
+	cfg := config.Default().Auth.OIDC
+	cfg.Issuer = stand.Issuer() + "/"
+	cfg.ClientID = "trove"
+
+	if _, err := New(cfg, stand.Client()).AuthCodeURL(context.Background(), newTestLogin(t)); err == nil {
+		t.Error("AuthCodeURL error = nil for a provider naming another issuer")
+	}
+}
+
+func TestProvider_Exchange(t *testing.T) {
+	tests := []struct {
+		name    string
+		verify  func(login Login) Login
+		wantErr error
+	}{
+		{
+			name:   "Valid login",
+			verify: func(login Login) Login { return login },
+		},
+		{
+			name: "Wrong PKCE verifier",
+			verify: func(login Login) Login {
+				login.Verifier = "guessed"
+				return login
+			},
+			wantErr: ErrLoginRejected,
+		},
+		{
+			name: "Wrong nonce",
+			verify: func(login Login) Login {
+				login.Nonce = "another-login"
+				return login
+			},
+			wantErr: ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, stand := newTestProvider(t)
+			stand.Claims["preferred_username"] = "Grace"
+			stand.Claims["groups"] = []string{"family"}
+
+			login := newTestLogin(t)
+
+			address, err := provider.AuthCodeURL(context.Background(), login)
+			if err != nil {
+				t.Fatalf("AuthCodeURL error = %v", err)
+			}
+
+			callback := stand.Authorize(t, address)
+			if callback.Query().Get("state") != login.State {
+				t.Fatalf("Callback state = %v, want: %v", callback.Query().Get("state"), login.State)
+			}
+
+			got1, err := provider.Exchange(context.Background(), callback.Query().Get("code"), tt.verify(login))
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Exchange error = %v, want: %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && (got1.Subject != "subject-1" || got1.Username != "Grace") {
+				t.Errorf("Exchange got1 = %+v, want subject-1 named Grace", got1)
+			}
+		})
+	}
+}
+
+func TestProvider_Verify(t *testing.T) {
+	provider, stand := newTestProvider(t)
+	_, other := newTestProvider(t)
+	now := time.Now()
+
+	claims := func(changes map[string]interface{}) map[string]interface{} {
+		claims := map[string]interface{}{
+			"iss":   stand.Issuer(),
+			"sub":   "subject-1",
+			"aud":   "trove",
+			"exp":   now.Add(time.Minute).Unix(),
+			"iat":   now.Unix(),
+			"nonce": "nonce-1",
+		}
+		for name, value := range changes {
+			if value == nil {
+				delete(claims, name)
+				continue
+			}
+
+			claims[name] = value
+		}
+
+		return claims
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"subject-1"}`)) + "."
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "Valid token", token: stand.SignToken(t, claims(nil))},
+		{name: "Audience list with the client as authorized party", token: stand.SignToken(t, claims(map[string]interface{}{"aud": []string{"trove", "other"}, "azp": "trove"}))},
+		{name: "Audience list without an authorized party", token: stand.SignToken(t, claims(map[string]interface{}{"aud": []string{"trove", "other"}})), wantErr: true},
+		{name: "Another audience", token: stand.SignToken(t, claims(map[string]interface{}{"aud": "other"})), wantErr: true},
+		{name: "Another issuer", token: stand.SignToken(t, claims(map[string]interface{}{"iss": "https://evil.example"})), wantErr: true},
+		{name: "Expired", token: stand.SignToken(t, claims(map[string]interface{}{"exp": now.Add(-time.Hour).Unix()})), wantErr: true},
+		{name: "No expiry", token: stand.SignToken(t, claims(map[string]interface{}{"exp": nil})), wantErr: true},
+		{name: "Another login's nonce", token: stand.SignToken(t, claims(map[string]interface{}{"nonce": "nonce-2"})), wantErr: true},
+		{name: "No subject", token: stand.SignToken(t, claims(map[string]interface{}{"sub": nil})), wantErr: true},
+		{name: "Signed by another key", token: other.SignToken(t, claims(nil)), wantErr: true},
+		{name: "Unsigned", token: unsigned, wantErr: true},
+		{name: "Not a JWT", token: "not-a-token", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1, err := provider.Verify(context.Background(), tt.token, "nonce-1")
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Errorf("Verify error = %v, want: %v", err, ErrInvalidToken)
+				}
+
+				return
+			}
+
+			if err != nil || got1.Subject != "subject-1" {
+				t.Errorf("Verify got1 = %+v, %v, want subject-1", got1, err)
+			}
+		})
+	}
+}
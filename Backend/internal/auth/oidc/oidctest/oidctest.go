@@ -0,0 +1,299 @@
+// Package oidctest is a stand-in OpenID Connect identity provider for tests. It serves discovery,
+// a key set, a login page that logs in straight away and a token endpoint that checks PKCE, and
+// signs its ID tokens with a key made for each provider.
+package oidctest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// KeyID is the id of the key the provider signs with
+const KeyID = "oidctest"
+
+// Provider is a running stand-in identity provider. Its issuer is the URL of its server.
+type Provider struct {
+	*httptest.Server
+
+	ClientID     string
+	ClientSecret string
+
+	// Subject and Claims are put in the ID token of each login, such as preferred_username and
+	// groups. They can be changed between logins.
+	Subject string
+	Claims  map[string]interface{}
+
+	key *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]grant
+}
+
+// grant is a code handed out by the login page, waiting to be exchanged for tokens
+type grant struct {
+	redirectURI string
+	challenge   string
+	nonce       string
+}
+
+// NewProvider starts a provider for the client, which is stopped when the test ends. A client
+// without a secret is a public client.
+func NewProvider(t *testing.T, clientID string, clientSecret string) *Provider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate provider key: %v", err)
+	}
+
+	p := &Provider{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Subject:      "subject-1",
+		Claims:       map[string]interface{}{},
+		key:          key,
+		grants:       map[string]grant{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET /jwks", p.jwks)
+	mux.HandleFunc("GET /authorize", p.authorize)
+	mux.HandleFunc("POST /token", p.token)
+
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Server.Close)
+
+	return p
+}
+
+// Issuer returns the issuer the provider's tokens name
+func (p *Provider) Issuer() string {
+	return p.URL
+}
+
+// Authorize visits a login address as a browser would, returning the callback address the provider
+// sends the browser back to
+func (p *Provider) Authorize(t *testing.T, address string) *url.URL {
+	t.Helper()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	response, err := client.Get(address)
+	if err != nil {
+		t.Fatalf("Failed to visit login page: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusFound {
+		t.Fatalf("Login page status code = %v, want: %v", response.StatusCode, http.StatusFound)
+	}
+
+	callback, err := url.Parse(response.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("Failed to parse callback: %v", err)
+	}
+
+	return callback
+}
+
+// SignToken returns a JWT of the claims signed by the provider's key, for testing how tokens that
+// are valid, or almost valid, are handled
+func (p *Provider) SignToken(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+
+	token, err := p.sign(claims)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	return token
+}
+
+// discovery serves the discovery document
+func (p *Provider) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.Issuer(),
+		"authorization_endpoint":                p.URL + "/authorize",
+		"token_endpoint":                        p.URL + "/token",
+		"jwks_uri":                              p.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// jwks serves the public half of the signing key
+func (p *Provider) jwks(w http.ResponseWriter, _ *http.Request) {
+	public := p.key.PublicKey
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": KeyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}},
+	})
+}
+
+// authorize is the login page. It logs in straight away and sends the browser back with a code.
+func (p *Provider) authorize(w http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+
+	switch {
+	case query.Get("response_type") != "code":
+		http.Error(w, "response_type must be code", http.StatusBadRequest)
+		return
+	case query.Get("client_id") != p.ClientID:
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	case query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "":
+		http.Error(w, "an S256 code_challenge is required", http.StatusBadRequest)
+		return
+	}
+
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirect.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+
+	p.mu.Lock()
+	p.grants[code] = grant{
+		redirectURI: query.Get("redirect_uri"),
+		challenge:   query.Get("code_challenge"),
+		nonce:       query.Get("nonce"),
+	}
+	p.mu.Unlock()
+
+	values := redirect.Query()
+	values.Set("code", code)
+	values.Set("state", query.Get("state"))
+	redirect.RawQuery = values.Encode()
+
+	http.Redirect(w, request, redirect.String(), http.StatusFound)
+}
+
+// token exchanges a code for an ID token, once, when the client and PKCE verifier match
+func (p *Provider) token(w http.ResponseWriter, request *http.Request) {
+	if err := request.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	clientID, clientSecret, ok := request.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID, clientSecret = request.PostForm.Get("client_id"), request.PostForm.Get("client_secret")
+	}
+
+	if clientID != p.ClientID || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(p.ClientSecret)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	if request.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	p.mu.Lock()
+	code := request.PostForm.Get("code")
+	granted, ok := p.grants[code]
+	delete(p.grants, code)
+	p.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(request.PostForm.Get("code_verifier")))
+	verified := base64.RawURLEncoding.EncodeToString(sum[:]) == granted.challenge
+
+	if !ok || granted.redirectURI != request.PostForm.Get("redirect_uri") || !verified {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "the code, redirect_uri or code_verifier is wrong"})
+		return
+	}
+
+	claims := map[string]interface{}{}
+	for name, value := range p.Claims {
+		claims[name] = value
+	}
+
+	now := time.Now()
+	claims["iss"] = p.Issuer()
+	claims["sub"] = p.Subject
+	claims["aud"] = p.ClientID
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(5 * time.Minute).Unix()
+	claims["nonce"] = granted.nonce
+
+	idToken, err := p.sign(claims)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// sign returns an RS256 JWT of the claims
+func (p *Provider) sign(claims map[string]interface{}) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": KeyID})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// randomString returns a random value for codes and access tokens
+func randomString() string {
+	data := make([]byte, 16)
+	_, _ = rand.Read(data)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// writeJSON sends a JSON response
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
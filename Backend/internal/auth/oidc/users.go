@@ -0,0 +1,140 @@
+// Package oidc logs users in through an OpenID Connect identity provider, using the authorization
+// code flow with PKCE. The provider's endpoints are found through discovery, and ID tokens are
+// checked against the keys it publishes.
+package oidc
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var (
+	// ErrNoRole is returned when the claims don't give the user a role and there is no default
+	ErrNoRole = errors.New("the identity provider doesn't give the user a role")
+
+	// ErrUsernameTaken is returned when a user seen for the first time has the username of an
+	// existing user. Existing users link the provider to their account themselves while logged in.
+	ErrUsernameTaken = errors.New("the username is already taken")
+
+	// ErrSubjectLinked is returned when linking an identity that is already linked to another user
+	ErrSubjectLinked = errors.New("the identity is already linked to another user")
+)
+
+// User returns the user the claims are for, who is only ever matched on their subject, since the
+// username and email can be changed at the provider. A user seen for the first time is created.
+// Their display name is kept in step with the provider, as is their role unless they also have a
+// password, in which case their role is managed here.
+func (p *Provider) User(ctx context.Context, repo repository.Repository, claims Claims) (models.User, error) {
+	role, ok := p.Role(claims)
+	if !ok {
+		return models.User{}, ErrNoRole
+	}
+
+	user, err := repository.Get[models.User](ctx, repo, bson.D{{Key: "oidc_subject", Value: claims.Subject}})
+	if repo.IsNotFoundError(err) {
+		return p.createUser(ctx, repo, claims, role)
+	}
+
+	if err != nil {
+		return models.User{}, fmt.Errorf("issue finding user: %w", err)
+	}
+
+	displayName := user.DisplayName
+	if claims.Name != "" {
+		displayName = claims.Name
+	}
+
+	if user.PasswordHash != "" {
+		role = user.Role
+	}
+
+	if user.Role == role && user.DisplayName == displayName {
+		return user, nil
+	}
+
+	err = repo.UpdateFields(ctx, &user, bson.D{{Key: "_id", Value: user.ID}}, bson.D{
+		{Key: "role", Value: role},
+		{Key: "display_name", Value: displayName},
+	})
+	if err != nil {
+		return models.User{}, fmt.Errorf("issue updating user: %w", err)
+	}
+
+	return user, nil
+}
+
+// Link ties the identity in the claims to an existing user, who has to be logged in to ask for it,
+// so they can log in through the provider from then on. Their role and display name are left as
+// they are.
+func (p *Provider) Link(ctx context.Context, repo repository.Repository, user models.User, claims Claims) (models.User, error) {
+	linked, err := repository.Get[models.User](ctx, repo, bson.D{{Key: "oidc_subject", Value: claims.Subject}})
+	if err == nil && linked.ID != user.ID {
+		return models.User{}, ErrSubjectLinked
+	}
+
+	if err == nil {
+		return linked, nil
+	}
+
+	if !repo.IsNotFoundError(err) {
+		return models.User{}, fmt.Errorf("issue finding user: %w", err)
+	}
+
+	// Another user may have linked the identity since it was looked for
+	err = repo.UpdateFields(ctx, &user, bson.D{{Key: "_id", Value: user.ID}}, bson.D{
+		{Key: "oidc_subject", Value: claims.Subject},
+	})
+	if errors.Is(err, repository.ErrDuplicateKey) {
+		return models.User{}, ErrSubjectLinked
+	}
+
+	if err != nil {
+		return models.User{}, fmt.Errorf("issue updating user: %w", err)
+	}
+
+	return user, nil
+}
+
+// createUser adds the user for claims seen for the first time. Their username comes from the
+// claims, and is refused when another user already has it. The unique indexes on usernames and
+// subjects decide between concurrent first logins, so the one that loses is given the user the
+// other created.
+func (p *Provider) createUser(ctx context.Context, repo repository.Repository, claims Claims, role string) (models.User, error) {
+	username := models.NormalizeUsername(claims.preferredUsername())
+
+	user := models.User{
+		Username:    username,
+		DisplayName: claims.Name,
+		Role:        role,
+		OIDCSubject: claims.Subject,
+	}
+
+	if err := user.Validate(); err != nil {
+		return models.User{}, err
+	}
+
+	created, err := repository.Create(ctx, repo, user)
+	if err == nil {
+		return created, nil
+	}
+
+	if !errors.Is(err, repository.ErrDuplicateKey) {
+		return models.User{}, fmt.Errorf("issue creating user: %w", err)
+	}
+
+	existing, err := repository.Get[models.User](ctx, repo, bson.D{{Key: "oidc_subject", Value: claims.Subject}})
+	if err == nil {
+		return existing, nil
+	}
+
+	if !repo.IsNotFoundError(err) {
+		return models.User{}, fmt.Errorf("issue finding user: %w", err)
+	}
+
+	return models.User{}, fmt.Errorf("%w: %s", ErrUsernameTaken, username)
+}
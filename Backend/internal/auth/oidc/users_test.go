@@ -0,0 +1,187 @@
+package oidc
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth"
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// newTestRepository returns an in memory repository with the indexes users rely on
+func newTestRepository(t *testing.T) repository.Repository {
+	t.Helper()
+
+	repo := repository.NewMemoryRepository()
+	if err := auth.EnsureIndexes(context.Background(), repo); err != nil {
+		t.Fatalf("Failed to create indexes: %v", err)
+	}
+
+	return repo
+}
+
+func TestProvider_User(t *testing.T) {
+	provider, _ := newTestProvider(t)
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	// ada has a password account from before the provider was set up, which she has linked
+	ada := models.User{Username: "ada", Role: models.RoleAdmin, OIDCSubject: "subject-ada"}
+	if err := ada.SetPassword("correct horse"); err != nil {
+		t.Fatalf("Failed to set password: %v", err)
+	}
+
+	ada, err := repository.Create(ctx, repo, ada)
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	claims := func(subject string, username string, groups ...interface{}) Claims {
+		return Claims{Subject: subject, Username: username, Name: username + " from the provider", raw: map[string]interface{}{"groups": groups}}
+	}
+
+	tests := []struct {
+		name     string
+		claims   Claims
+		wantErr  error
+		wantUser func(user models.User) bool
+	}{
+		{
+			name:    "Existing username isn't taken over",
+			claims:  claims("subject-impostor", "Ada", "trove-admins"),
+			wantErr: ErrUsernameTaken,
+		},
+		{
+			name:   "New user is created",
+			claims: claims("subject-grace", "Grace", "family"),
+			wantUser: func(user models.User) bool {
+				return !user.ID.IsZero() && user.ID != ada.ID && user.Username == "grace" && user.DisplayName == "Grace from the provider" && user.Role == models.RoleMember
+			},
+		},
+		{
+			name:   "User is matched on their subject, whatever their username",
+			claims: claims("subject-grace", "renamed", "trove-admins"),
+			wantUser: func(user models.User) bool {
+				return user.Username == "grace" && user.Role == models.RoleAdmin && user.DisplayName == "renamed from the provider"
+			},
+		},
+		{
+			name:   "Linked user with a password keeps their role",
+			claims: claims("subject-ada", "someone-else", "family"),
+			wantUser: func(user models.User) bool {
+				return user.ID == ada.ID && user.Role == models.RoleAdmin && user.DisplayName == "someone-else from the provider"
+			},
+		},
+		{
+			name:    "No role",
+			claims:  claims("subject-pip", "pip", "neighbours"),
+			wantErr: ErrNoRole,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1, err := provider.User(ctx, repo, tt.claims)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("User error = %v, want: %v", err, tt.wantErr)
+			}
+
+			if tt.wantUser != nil && !tt.wantUser(got1) {
+				t.Errorf("User got1 = %+v", got1)
+			}
+		})
+	}
+
+	stored, err := repository.Get[models.User](ctx, repo, bson.D{{Key: "_id", Value: ada.ID}})
+	if err != nil {
+		t.Fatalf("Failed to read user: %v", err)
+	}
+
+	if stored.Username != "ada" || stored.Role != models.RoleAdmin {
+		t.Errorf("Existing user = %+v, want ada kept as an admin", stored)
+	}
+}
+
+func TestProvider_User_concurrentFirstLogin(t *testing.T) {
+	provider, _ := newTestProvider(t)
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	claims := Claims{Subject: "subject-grace", Username: "grace", raw: map[string]interface{}{"groups": []interface{}{"family"}}}
+
+	var wg sync.WaitGroup
+	users := make(chan models.User, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			user, err := provider.User(ctx, repo, claims)
+			if err != nil {
+				t.Errorf("User error = %v", err)
+			}
+			users <- user
+		}()
+	}
+	wg.Wait()
+	close(users)
+
+	ids := map[string]bool{}
+	for user := range users {
+		ids[user.ID.Hex()] = true
+	}
+
+	count, err := repo.Count(ctx, &models.User{}, bson.D{{Key: "oidc_subject", Value: "subject-grace"}})
+	if err != nil {
+		t.Fatalf("Count error = %v", err)
+	}
+
+	if len(ids) != 1 || count != 1 {
+		t.Errorf("Concurrent first logins gave %d users and stored %d, want: 1 and 1", len(ids), count)
+	}
+}
+
+func TestProvider_Link(t *testing.T) {
+	provider, _ := newTestProvider(t)
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	ada, err := repository.Create(ctx, repo, models.User{Username: "ada", Role: models.RoleMember})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	grace, err := repository.Create(ctx, repo, models.User{Username: "grace", Role: models.RoleMember})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		user    models.User
+		subject string
+		wantErr error
+	}{
+		{name: "Identity is linked", user: ada, subject: "subject-ada"},
+		{name: "Linking again is allowed", user: ada, subject: "subject-ada"},
+		{name: "Identity linked to another user", user: grace, subject: "subject-ada", wantErr: ErrSubjectLinked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1, err := provider.Link(ctx, repo, tt.user, Claims{Subject: tt.subject, Username: "someone-else"})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Link error = %v, want: %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && (got1.ID != tt.user.ID || got1.OIDCSubject != tt.subject || got1.Role != tt.user.Role || got1.Username != tt.user.Username) {
+				t.Errorf("Link got1 = %+v, want %s linked to %s", got1, tt.user.Username, tt.subject)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Package auth contains the logging in of users and the sessions that keep them logged in
+package auth
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"context"
+	"slices"
+)
+
+// Permission is something a user may be allowed to do. Routes declare the permission they need,
+// and a user's role decides which permissions they have.
+type Permission string
+
+// The permissions routes can require
+const (
+	// PermissionReadLibrary allows browsing books, authors and loans
+	PermissionReadLibrary Permission = "library:read"
+
+	// PermissionBorrow allows checking books out to yourself and returning them
+	PermissionBorrow Permission = "library:borrow"
+
+	// PermissionEditLibrary allows adding and changing books and authors
+	PermissionEditLibrary Permission = "library:edit"
+
+	// PermissionDeleteLibrary allows deleting and merging books and authors
+	PermissionDeleteLibrary Permission = "library:delete"
+
+	// PermissionManageLoans allows checking books out to, and returning books for, other people
+	PermissionManageLoans Permission = "loans:manage"
+
+	// PermissionManageUsers allows adding users and changing their roles
+	PermissionManageUsers Permission = "users:manage"
+
+	// PermissionManageAPIKeys allows creating, listing and revoking API keys
+	PermissionManageAPIKeys Permission = "apikeys:manage"
+
+	// PermissionReadAudit allows reading the audit log of who changed what
+	PermissionReadAudit Permission = "audit:read"
+
+	// PermissionOperate allows inspecting and tuning the running service
+	PermissionOperate Permission = "system:operate"
+)
+
+// Permissions is every permission, in the order they are listed to users
+var Permissions = []Permission{
+	PermissionReadLibrary,
+	PermissionBorrow,
+	PermissionEditLibrary,
+	PermissionDeleteLibrary,
+	PermissionManageLoans,
+	PermissionManageUsers,
+	PermissionManageAPIKeys,
+	PermissionReadAudit,
+	PermissionOperate,
+}
+
+// rolePermissions is what each role is allowed to do. Admins are allowed everything, so they
+// aren't listed.
+var rolePermissions = map[string][]Permission{
+	models.RoleMember: {PermissionReadLibrary, PermissionBorrow},
+	models.RoleGuest:  {PermissionReadLibrary},
+}
+
+// HasPermission reports whether a user's role allows a permission. A user without a known role
+// has no permissions.
+func HasPermission(user models.User, permission Permission) bool {
+	if user.Role == models.RoleAdmin {
+		return true
+	}
+
+	return slices.Contains(rolePermissions[user.Role], permission)
+}
+
+// Can reports whether the user a request was made by has a permission. Anonymous requests have
+// none, and requests made with an API key are also limited to the key's scopes.
+func Can(ctx context.Context, permission Permission) bool {
+	user, ok := UserFromContext(ctx)
+	if !ok || !HasPermission(user, permission) {
+		return false
+	}
+
+	if key, ok := APIKeyFromContext(ctx); ok {
+		return key.HasScope(string(permission))
+	}
+
+	return true
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"Home-Intranet-v2-Backend/internal/auth/models"
+	"context"
+	"testing"
+)
+
+func TestHasPermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       string
+		permission Permission
+		want1      bool
+	}{
+		{name: "Admin can delete", role: models.RoleAdmin, permission: PermissionDeleteLibrary, want1: true},
+		{name: "Admin can manage users", role: models.RoleAdmin, permission: PermissionManageUsers, want1: true},
+		{name: "Member can read", role: models.RoleMember, permission: PermissionReadLibrary, want1: true},
+		{name: "Member can borrow", role: models.RoleMember, permission: PermissionBorrow, want1: true},
+		{name: "Member can't delete", role: models.RoleMember, permission: PermissionDeleteLibrary, want1: false},
+		{name: "Member can't manage other loans", role: models.RoleMember, permission: PermissionManageLoans, want1: false},
+		{name: "Guest can read", role: models.RoleGuest, permission: PermissionReadLibrary, want1: true},
+		{name: "Guest can't borrow", role: models.RoleGuest, permission: PermissionBorrow, want1: false},
+		{name: "No role can't read", role: "", permission: PermissionReadLibrary, want1: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1 := HasPermission(models.User{Role: tt.role}, tt.permission)
+
+			if got1 != tt.want1 {
+				t.Errorf("HasPermission got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestCan(t *testing.T) {
+	if Can(context.Background(), PermissionReadLibrary) {
+		t.Error("Can = true for an anonymous request")
+	}
+
+	ctx := WithUser(context.Background(), models.User{Role: models.RoleGuest})
+
+	if !Can(ctx, PermissionReadLibrary) || Can(ctx, PermissionBorrow) {
+		t.Error("Can should allow a guest to read and not to borrow")
+	}
+
+	ctx = WithAPIKey(WithUser(context.Background(), models.User{Role: models.RoleAdmin}), models.APIKey{
+		Scopes: []string{string(PermissionReadLibrary)},
+	})
+
+	if !Can(ctx, PermissionReadLibrary) || Can(ctx, PermissionDeleteLibrary) {
+		t.Error("Can should limit an admin's API key to its scopes")
+	}
+}
@@ -3,25 +3,86 @@ package models
 
 import (
 	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/validation"
+	"encoding/json"
 
 	"go.mongodb.org/mongo-driver/bson"
 )
 
 // Author is the type for authors in our library
 type Author struct {
-	repository.Model `bson:",inline" json:"-"`
-	FirstName        string `bson:"first_name" json:"first_name"`
-	MiddleName       string `bson:"middle_name" json:"middle_name"`
-	LastName         string `bson:"last_name" json:"last_name"`
-	Suffix           string `bson:"suffix" json:"suffix"`
+	repository.Model `bson:",inline" json:",inline"`
+	FirstName        string `bson:"first_name" json:"first_name" validate:"required_without=LastName,max=100"`
+	MiddleName       string `bson:"middle_name" json:"middle_name" validate:"max=100"`
+	LastName         string `bson:"last_name" json:"last_name" validate:"required_without=FirstName,max=100"`
+	Suffix           string `bson:"suffix" json:"suffix" validate:"max=20"`
 }
 
-// MarshalBSON is used when the author are embedded in a book object and marshalled into BSON
+// Validate checks the author against its rules, returning every field error at once
+func (a Author) Validate() error {
+	return validation.Struct(a).Err()
+}
+
+// MarshalBSON is used when the author are embedded in a book object and marshalled into BSON. The
+// id is kept so the embedded copy stays linked to its author document, while the timestamps are
+// only written when they are set.
 func (a Author) MarshalBSON() ([]byte, error) {
-	return bson.Marshal(bson.M{
+	doc := bson.M{
+		"first_name":  a.FirstName,
+		"middle_name": a.MiddleName,
+		"last_name":   a.LastName,
+		"suffix":      a.Suffix,
+	}
+
+	if !a.ID.IsZero() {
+		doc["_id"] = a.ID
+	}
+
+	if !a.CreatedAt.IsZero() {
+		doc["created_at"] = a.CreatedAt
+	}
+
+	if !a.UpdatedAt.IsZero() {
+		doc["updated_at"] = a.UpdatedAt
+	}
+
+	return bson.Marshal(doc)
+}
+
+// MarshalJSON leaves the timestamps out of the JSON sent to the user when they aren't set, as they
+// aren't on the copies of authors embedded in books
+func (a Author) MarshalJSON() ([]byte, error) {
+	doc := map[string]interface{}{
 		"first_name":  a.FirstName,
 		"middle_name": a.MiddleName,
 		"last_name":   a.LastName,
 		"suffix":      a.Suffix,
-	})
+	}
+
+	if !a.ID.IsZero() {
+		doc["_id"] = a.ID
+	}
+
+	if !a.CreatedAt.IsZero() {
+		doc["created_at"] = a.CreatedAt
+	}
+
+	if !a.UpdatedAt.IsZero() {
+		doc["updated_at"] = a.UpdatedAt
+	}
+
+	return json.Marshal(doc)
+}
+
+// Embedded returns the copy of the author that is stored inside a book
+func (a Author) Embedded() Author {
+	return Author{
+		Model: repository.Model{
+			ID: a.ID,
+		},
+		FirstName:  a.FirstName,
+		MiddleName: a.MiddleName,
+		LastName:   a.LastName,
+		Suffix:     a.Suffix,
+	}
 }
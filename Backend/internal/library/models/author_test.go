@@ -0,0 +1,217 @@
+package models
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/validation"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAuthor_MarshalBSON(t *testing.T) {
+	id := primitive.NewObjectID()
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		author Author
+		want1  bson.M
+	}{
+		{
+			name: "New author without an id",
+			author: Author{
+				FirstName:  "Ursula",
+				MiddleName: "K.",
+				LastName:   "Le Guin",
+			},
+			want1: bson.M{
+				"first_name":  "Ursula",
+				"middle_name": "K.",
+				"last_name":   "Le Guin",
+				"suffix":      "",
+			},
+		},
+		{
+			name: "Embedded author keeps its id",
+			author: Author{
+				Model:     repository.Model{ID: id},
+				FirstName: "Ursula",
+				LastName:  "Le Guin",
+			},
+			want1: bson.M{
+				"_id":         id,
+				"first_name":  "Ursula",
+				"middle_name": "",
+				"last_name":   "Le Guin",
+				"suffix":      "",
+			},
+		},
+		{
+			name: "Stored author keeps its timestamps",
+			author: Author{
+				Model:     repository.Model{ID: id, CreatedAt: now, UpdatedAt: now},
+				FirstName: "Ursula",
+				LastName:  "Le Guin",
+			},
+			want1: bson.M{
+				"_id":         id,
+				"created_at":  primitive.NewDateTimeFromTime(now),
+				"updated_at":  primitive.NewDateTimeFromTime(now),
+				"first_name":  "Ursula",
+				"middle_name": "",
+				"last_name":   "Le Guin",
+				"suffix":      "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.author.MarshalBSON()
+			if err != nil {
+				t.Fatalf("MarshalBSON error = %v", err)
+			}
+
+			var got1 bson.M
+			if err = bson.Unmarshal(data, &got1); err != nil {
+				t.Fatalf("Failed to unmarshal author bson: %v", err)
+			}
+
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("MarshalBSON got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestAuthor_MarshalJSON(t *testing.T) {
+	id := primitive.NewObjectID()
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		author Author
+		want1  map[string]interface{}
+	}{
+		{
+			name: "Embedded author has no timestamps",
+			author: Author{
+				Model:     repository.Model{ID: id},
+				FirstName: "Ursula",
+				LastName:  "Le Guin",
+			},
+			want1: map[string]interface{}{
+				"_id":         id.Hex(),
+				"first_name":  "Ursula",
+				"middle_name": "",
+				"last_name":   "Le Guin",
+				"suffix":      "",
+			},
+		},
+		{
+			name: "Stored author keeps its timestamps",
+			author: Author{
+				Model:     repository.Model{ID: id, CreatedAt: now, UpdatedAt: now},
+				FirstName: "Ursula",
+				LastName:  "Le Guin",
+			},
+			want1: map[string]interface{}{
+				"_id":         id.Hex(),
+				"created_at":  "2026-03-01T12:00:00Z",
+				"updated_at":  "2026-03-01T12:00:00Z",
+				"first_name":  "Ursula",
+				"middle_name": "",
+				"last_name":   "Le Guin",
+				"suffix":      "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.author)
+			if err != nil {
+				t.Fatalf("MarshalJSON error = %v", err)
+			}
+
+			var got1 map[string]interface{}
+			if err = json.Unmarshal(data, &got1); err != nil {
+				t.Fatalf("Failed to unmarshal author json: %v", err)
+			}
+
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("MarshalJSON got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestAuthor_Embedded(t *testing.T) {
+	id := primitive.NewObjectID()
+	now := time.Now()
+
+	author := Author{
+		Model:     repository.Model{ID: id, CreatedAt: now, UpdatedAt: now},
+		FirstName: "Terry",
+		LastName:  "Pratchett",
+	}
+
+	want1 := Author{
+		Model:     repository.Model{ID: id},
+		FirstName: "Terry",
+		LastName:  "Pratchett",
+	}
+
+	if got1 := author.Embedded(); !reflect.DeepEqual(got1, want1) {
+		t.Errorf("Embedded got1 = %v, want1: %v", got1, want1)
+	}
+}
+
+func TestAuthor_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		author Author
+		want1  error
+	}{
+		{
+			name:   "Last name only",
+			author: Author{LastName: "Le Guin"},
+			want1:  nil,
+		},
+		{
+			name:   "First name only",
+			author: Author{FirstName: "Homer"},
+			want1:  nil,
+		},
+		{
+			name:   "No name",
+			author: Author{MiddleName: "K."},
+			want1: validation.Errors{
+				{Field: "first_name", Code: "required", Message: "is required when last_name is empty"},
+				{Field: "last_name", Code: "required", Message: "is required when first_name is empty"},
+			},
+		},
+		{
+			name:   "Name too long",
+			author: Author{LastName: strings.Repeat("a", 101)},
+			want1: validation.Errors{
+				{Field: "last_name", Code: "max_length", Message: "must be at most 100 characters"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1 := tt.author.Validate()
+
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("Validate got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
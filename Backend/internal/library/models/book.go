@@ -3,16 +3,68 @@ package models
 
 import (
 	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/validation"
+	"fmt"
+	"math"
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // Book is the type for books in our library
 type Book struct {
 	repository.Model `bson:",inline" json:",inline"`
-	Title            string    `bson:"title" json:"title"`
-	Authors          []Author  `bson:"authors" json:"authors"`
-	Shelf            string    `bson:"shelf" json:"shelf"`
+	Title            string    `bson:"title" json:"title" validate:"required,max=200"`
+	Authors          []Author  `bson:"authors" json:"authors" validate:"required"`
+	Shelf            string    `bson:"shelf" json:"shelf" validate:"max=20"`
 	CheckedOut       bool      `bson:"checked_out" json:"checked_out"`
-	CheckedOutBy     string    `bson:"checked_out_by" json:"checked_out_by"`
+	CheckedOutBy     string    `bson:"checked_out_by" json:"checked_out_by" validate:"required_if=CheckedOut true,max=100"`
 	CheckedOutTime   time.Time `bson:"checked_out_time" json:"checked_out_time"`
+	DueDate          time.Time `bson:"due_date" json:"due_date"`
+
+	// CheckedOutByID is the user account the book is checked out to. It is empty for books checked
+	// out to someone without an account, or before accounts were recorded.
+	CheckedOutByID primitive.ObjectID `bson:"checked_out_by_id" json:"checked_out_by_id"`
+}
+
+// Validate checks the book against its rules, returning every field error at once. Authors sent
+// with an id refer to a stored author whose names are filled in later, so only the authors sent by
+// name are checked.
+func (b Book) Validate() error {
+	errs := validation.Struct(b)
+
+	for i, author := range b.Authors {
+		if !author.ID.IsZero() {
+			continue
+		}
+
+		errs = append(errs, validation.Struct(author).Prefix(fmt.Sprintf("authors.%d", i))...)
+	}
+
+	return errs.Err()
+}
+
+// DueBy returns when a checked out book has to be back. Books checked out before due dates were
+// recorded fall back to the checkout time plus the loan period.
+func (b Book) DueBy(loanPeriod time.Duration) time.Time {
+	if !b.DueDate.IsZero() {
+		return b.DueDate
+	}
+
+	return b.CheckedOutTime.Add(loanPeriod)
+}
+
+// DaysOverdue returns how many started days a checked out book is past its due date, or zero when
+// it isn't overdue
+func (b Book) DaysOverdue(loanPeriod time.Duration, now time.Time) int {
+	if !b.CheckedOut {
+		return 0
+	}
+
+	late := now.Sub(b.DueBy(loanPeriod))
+	if late <= 0 {
+		return 0
+	}
+
+	return int(math.Ceil(late.Hours() / 24))
 }
@@ -0,0 +1,169 @@
+package models
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/validation"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBook_DueBy(t *testing.T) {
+	checkedOut := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	dueDate := time.Date(2026, time.March, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		book  Book
+		want1 time.Time
+	}{
+		{
+			name: "Recorded due date",
+			book: Book{
+				CheckedOut:     true,
+				CheckedOutTime: checkedOut,
+				DueDate:        dueDate,
+			},
+			want1: dueDate,
+		},
+		{
+			name: "Falls back to the loan period",
+			book: Book{
+				CheckedOut:     true,
+				CheckedOutTime: checkedOut,
+			},
+			want1: checkedOut.Add(14 * 24 * time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1 := tt.book.DueBy(14 * 24 * time.Hour)
+
+			if !got1.Equal(tt.want1) {
+				t.Errorf("DueBy got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestBook_DaysOverdue(t *testing.T) {
+	dueDate := time.Date(2026, time.March, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		book  Book
+		now   time.Time
+		want1 int
+	}{
+		{
+			name:  "Not checked out",
+			book:  Book{DueDate: dueDate},
+			now:   dueDate.Add(72 * time.Hour),
+			want1: 0,
+		},
+		{
+			name:  "Not yet due",
+			book:  Book{CheckedOut: true, DueDate: dueDate},
+			now:   dueDate.Add(-time.Hour),
+			want1: 0,
+		},
+		{
+			name:  "Part of a day late",
+			book:  Book{CheckedOut: true, DueDate: dueDate},
+			now:   dueDate.Add(3 * time.Hour),
+			want1: 1,
+		},
+		{
+			name:  "Several days late",
+			book:  Book{CheckedOut: true, DueDate: dueDate},
+			now:   dueDate.Add(72 * time.Hour),
+			want1: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1 := tt.book.DaysOverdue(14*24*time.Hour, tt.now)
+
+			if got1 != tt.want1 {
+				t.Errorf("DaysOverdue got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestBook_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		book  Book
+		want1 error
+	}{
+		{
+			name: "Valid book",
+			book: Book{
+				Title:        "The Dispossessed",
+				Authors:      []Author{{LastName: "Le Guin"}},
+				Shelf:        "A1",
+				CheckedOut:   true,
+				CheckedOutBy: "James",
+			},
+			want1: nil,
+		},
+		{
+			name: "Authors sent by id don't need names",
+			book: Book{
+				Title:   "The Dispossessed",
+				Authors: []Author{{Model: repository.Model{ID: primitive.NewObjectID()}}},
+			},
+			want1: nil,
+		},
+		{
+			name: "Every field error is returned",
+			book: Book{
+				Shelf:      strings.Repeat("a", 21),
+				CheckedOut: true,
+			},
+			want1: validation.Errors{
+				{Field: "title", Code: "required", Message: "is required"},
+				{Field: "authors", Code: "required", Message: "is required"},
+				{Field: "shelf", Code: "max_length", Message: "must be at most 20 characters"},
+				{Field: "checked_out_by", Code: "required", Message: "is required when checked_out is true"},
+			},
+		},
+		{
+			name: "Empty authors list",
+			book: Book{
+				Title:   "The Dispossessed",
+				Authors: []Author{},
+			},
+			want1: validation.Errors{
+				{Field: "authors", Code: "required", Message: "is required"},
+			},
+		},
+		{
+			name: "Authors sent by name are checked",
+			book: Book{
+				Title:   "The Dispossessed",
+				Authors: []Author{{LastName: "Le Guin"}, {Suffix: "Jr."}},
+			},
+			want1: validation.Errors{
+				{Field: "authors.1.first_name", Code: "required", Message: "is required when last_name is empty"},
+				{Field: "authors.1.last_name", Code: "required", Message: "is required when first_name is empty"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1 := tt.book.Validate()
+
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("Validate got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
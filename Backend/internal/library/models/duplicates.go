@@ -0,0 +1,203 @@
+// Package models stores all of our models for the library module
+package models
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DuplicateAuthors is a group of authors that are likely the same person. The survivor is the
+// author with the most complete name, which is the suggested target of a merge.
+type DuplicateAuthors struct {
+	Survivor   Author   `json:"survivor"`
+	Duplicates []Author `json:"duplicates"`
+}
+
+// NormalizedName returns the author's given names and last name as case folded tokens with the
+// punctuation removed, so "J.R.R. Tolkien" and "j. r. r. tolkien" normalize the same way
+func (a Author) NormalizedName() (givenNames []string, lastName string, suffix string) {
+	givenNames = append(nameTokens(a.FirstName), nameTokens(a.MiddleName)...)
+	lastName = strings.Join(nameTokens(a.LastName), "")
+	suffix = strings.Join(nameTokens(a.Suffix), "")
+
+	return givenNames, lastName, suffix
+}
+
+// IsLikelySameAs reports whether two authors are probably the same person. The last names must
+// match once normalized, and each given name must match or be the initial of the other, allowing
+// one of the authors to leave out trailing middle names.
+func (a Author) IsLikelySameAs(other Author) bool {
+	givenNames, lastName, suffix := a.NormalizedName()
+	otherGivenNames, otherLastName, otherSuffix := other.NormalizedName()
+
+	if lastName == "" || lastName != otherLastName {
+		return false
+	}
+
+	if suffix != "" && otherSuffix != "" && suffix != otherSuffix {
+		return false
+	}
+
+	shorter, longer := givenNames, otherGivenNames
+	if len(shorter) > len(longer) {
+		shorter, longer = longer, shorter
+	}
+
+	for i := range shorter {
+		if !namesMatch(shorter[i], longer[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FindDuplicateAuthors groups the authors that are likely the same person, so that every author in
+// a group is likely the same as every other. Authors without a likely duplicate are left out, as are
+// ambiguous ones such as "J Smith" next to "John Smith" and "James Smith", since merging them would
+// fold two people's books together.
+func FindDuplicateAuthors(authors []Author) []DuplicateAuthors {
+	matches := make([][]int, len(authors))
+	for i := range authors {
+		for j := i + 1; j < len(authors); j++ {
+			if authors[i].IsLikelySameAs(authors[j]) {
+				matches[i] = append(matches[i], j)
+				matches[j] = append(matches[j], i)
+			}
+		}
+	}
+
+	// An author is ambiguous when two of its matches don't match each other. Once the ambiguous
+	// authors are left out, matches chain together only between authors that all match.
+	ambiguous := make([]bool, len(authors))
+	for i := range authors {
+		for a, j := range matches[i] {
+			for _, k := range matches[i][a+1:] {
+				if !authors[j].IsLikelySameAs(authors[k]) {
+					ambiguous[i] = true
+				}
+			}
+		}
+	}
+
+	// Union find over the matches of the authors that aren't ambiguous
+	parent := make([]int, len(authors))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range authors {
+		for _, j := range matches[i] {
+			if !ambiguous[i] && !ambiguous[j] {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	groups := map[int][]Author{}
+	roots := []int{}
+	for i, author := range authors {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], author)
+	}
+
+	duplicates := []DuplicateAuthors{}
+	for _, root := range roots {
+		group := groups[root]
+		if len(group) < 2 {
+			continue
+		}
+
+		sort.SliceStable(group, func(i, j int) bool {
+			return nameCompleteness(group[i]) > nameCompleteness(group[j])
+		})
+
+		duplicates = append(duplicates, DuplicateAuthors{
+			Survivor:   group[0],
+			Duplicates: group[1:],
+		})
+	}
+
+	return duplicates
+}
+
+// nameTokens splits a name into lower case words. Runs of capitals such as "JRR" are treated as
+// initials, and dots, hyphens and other punctuation separate words.
+func nameTokens(name string) []string {
+	tokens := []string{}
+
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	}) {
+		word = strings.ReplaceAll(word, "'", "")
+
+		if isInitials(word) {
+			for _, r := range word {
+				tokens = append(tokens, strings.ToLower(string(r)))
+			}
+			continue
+		}
+
+		if word != "" {
+			tokens = append(tokens, strings.ToLower(word))
+		}
+	}
+
+	return tokens
+}
+
+// isInitials reports whether a word is two or three capital letters written together, like "JRR"
+func isInitials(word string) bool {
+	runes := []rune(word)
+	if len(runes) < 2 || len(runes) > 3 {
+		return false
+	}
+
+	for _, r := range runes {
+		if !unicode.IsUpper(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// namesMatch reports whether two normalized given names could be the same name, where either one
+// may be an initial
+func namesMatch(a string, b string) bool {
+	if a == b {
+		return true
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 1 || len(rb) == 1 {
+		return ra[0] == rb[0]
+	}
+
+	return false
+}
+
+// nameCompleteness scores how much of an author's name is spelled out, preferring full given
+// names over initials
+func nameCompleteness(author Author) int {
+	givenNames, lastName, suffix := author.NormalizedName()
+
+	score := len(lastName) + len(suffix)
+	for _, name := range givenNames {
+		score += len(name)
+	}
+
+	return score
+}
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAuthor_NormalizedName(t *testing.T) {
+	tests := []struct {
+		name           string
+		author         Author
+		wantGivenNames []string
+		wantLastName   string
+		wantSuffix     string
+	}{
+		{
+			name:           "Initials with dots",
+			author:         Author{FirstName: "J.R.R.", LastName: "Tolkien"},
+			wantGivenNames: []string{"j", "r", "r"},
+			wantLastName:   "tolkien",
+			wantSuffix:     "",
+		},
+		{
+			name:           "Initials with spaces",
+			author:         Author{FirstName: "J. R. R.", LastName: "TOLKIEN"},
+			wantGivenNames: []string{"j", "r", "r"},
+			wantLastName:   "tolkien",
+			wantSuffix:     "",
+		},
+		{
+			name:           "Initials written together",
+			author:         Author{FirstName: "JRR", LastName: "Tolkien"},
+			wantGivenNames: []string{"j", "r", "r"},
+			wantLastName:   "tolkien",
+			wantSuffix:     "",
+		},
+		{
+			name:           "Multi word last name and suffix",
+			author:         Author{FirstName: "Martin", MiddleName: "Luther", LastName: "King", Suffix: "Jr."},
+			wantGivenNames: []string{"martin", "luther"},
+			wantLastName:   "king",
+			wantSuffix:     "jr",
+		},
+		{
+			name:           "Spaces in last name are ignored",
+			author:         Author{FirstName: "Ursula", LastName: "Le Guin"},
+			wantGivenNames: []string{"ursula"},
+			wantLastName:   "leguin",
+			wantSuffix:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotGivenNames, gotLastName, gotSuffix := tt.author.NormalizedName()
+
+			if !reflect.DeepEqual(gotGivenNames, tt.wantGivenNames) {
+				t.Errorf("NormalizedName givenNames = %v, want: %v", gotGivenNames, tt.wantGivenNames)
+			}
+
+			if gotLastName != tt.wantLastName {
+				t.Errorf("NormalizedName lastName = %v, want: %v", gotLastName, tt.wantLastName)
+			}
+
+			if gotSuffix != tt.wantSuffix {
+				t.Errorf("NormalizedName suffix = %v, want: %v", gotSuffix, tt.wantSuffix)
+			}
+		})
+	}
+}
+
+func TestAuthor_IsLikelySameAs(t *testing.T) {
+	tests := []struct {
+		name  string
+		a     Author
+		b     Author
+		want1 bool
+	}{
+		{
+			name:  "Different initial spacing",
+			a:     Author{FirstName: "J.R.R.", LastName: "Tolkien"},
+			b:     Author{FirstName: "J. R. R.", LastName: "Tolkien"},
+			want1: true,
+		},
+		{
+			name:  "Full names against initials",
+			a:     Author{FirstName: "John", MiddleName: "Ronald Reuel", LastName: "Tolkien"},
+			b:     Author{FirstName: "J.R.R.", LastName: "Tolkien"},
+			want1: true,
+		},
+		{
+			name:  "Missing middle names",
+			a:     Author{FirstName: "Ursula", MiddleName: "K.", LastName: "Le Guin"},
+			b:     Author{FirstName: "ursula", LastName: "LeGuin"},
+			want1: true,
+		},
+		{
+			name:  "Different first names",
+			a:     Author{FirstName: "Christopher", LastName: "Tolkien"},
+			b:     Author{FirstName: "John", LastName: "Tolkien"},
+			want1: false,
+		},
+		{
+			name:  "Different suffixes",
+			a:     Author{FirstName: "Martin", LastName: "King", Suffix: "Jr."},
+			b:     Author{FirstName: "Martin", LastName: "King", Suffix: "Sr."},
+			want1: false,
+		},
+		{
+			name:  "Different last names",
+			a:     Author{FirstName: "Terry", LastName: "Pratchett"},
+			b:     Author{FirstName: "Terry", LastName: "Brooks"},
+			want1: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got1 := tt.a.IsLikelySameAs(tt.b); got1 != tt.want1 {
+				t.Errorf("IsLikelySameAs got1 = %v, want1: %v", got1, tt.want1)
+			}
+
+			if got1 := tt.b.IsLikelySameAs(tt.a); got1 != tt.want1 {
+				t.Errorf("IsLikelySameAs reversed got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestFindDuplicateAuthors(t *testing.T) {
+	full := Author{FirstName: "John", MiddleName: "Ronald Reuel", LastName: "Tolkien"}
+	dotted := Author{FirstName: "J.R.R.", LastName: "Tolkien"}
+	spaced := Author{FirstName: "J. R. R.", LastName: "Tolkien"}
+	christopher := Author{FirstName: "Christopher", LastName: "Tolkien"}
+	pratchett := Author{FirstName: "Terry", LastName: "Pratchett"}
+	initial := Author{FirstName: "J", LastName: "Smith"}
+	john := Author{FirstName: "John", LastName: "Smith"}
+	james := Author{FirstName: "James", LastName: "Smith"}
+	johnQuincy := Author{FirstName: "John", MiddleName: "Q", LastName: "Smith"}
+
+	tests := []struct {
+		name    string
+		authors []Author
+		want1   []DuplicateAuthors
+	}{
+		{
+			name:    "No duplicates",
+			authors: []Author{christopher, pratchett},
+			want1:   []DuplicateAuthors{},
+		},
+		{
+			name:    "Most complete name survives",
+			authors: []Author{dotted, pratchett, christopher, spaced, full},
+			want1: []DuplicateAuthors{
+				{
+					Survivor:   full,
+					Duplicates: []Author{dotted, spaced},
+				},
+			},
+		},
+		{
+			name:    "Initial matching two different people isn't grouped with either",
+			authors: []Author{initial, john, james},
+			want1:   []DuplicateAuthors{},
+		},
+		{
+			name:    "Unambiguous matches are still grouped next to an ambiguous one",
+			authors: []Author{initial, john, james, johnQuincy},
+			want1: []DuplicateAuthors{
+				{
+					Survivor:   johnQuincy,
+					Duplicates: []Author{john},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1 := FindDuplicateAuthors(tt.authors)
+
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("FindDuplicateAuthors got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// Package models stores all of our models for the library module
+package models
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Loan is the record of a single checkout of a book, kept after the book is returned
+type Loan struct {
+	repository.Model `bson:",inline" json:",inline"`
+	BookID           primitive.ObjectID `bson:"book_id" json:"book_id"`
+	BookTitle        string             `bson:"book_title" json:"book_title"`
+	Borrower         string             `bson:"borrower" json:"borrower"`
+	BorrowerID       primitive.ObjectID `bson:"borrower_id" json:"borrower_id"`
+	CheckedOutTime   time.Time          `bson:"checked_out_time" json:"checked_out_time"`
+	DueDate          time.Time          `bson:"due_date" json:"due_date"`
+	Returned         bool               `bson:"returned" json:"returned"`
+	ReturnedTime     time.Time          `bson:"returned_time" json:"returned_time"`
+}
+
+// Duration is how long the book has been out. Loans that are still open are measured up to now.
+func (l Loan) Duration(now time.Time) time.Duration {
+	end := now
+	if l.Returned {
+		end = l.ReturnedTime
+	}
+
+	return end.Sub(l.CheckedOutTime)
+}
+
+// MarshalJSON adds the loan duration to the JSON sent to the user
+func (l Loan) MarshalJSON() ([]byte, error) {
+	type loan Loan
+
+	return json.Marshal(struct {
+		loan
+		DurationSeconds int64 `json:"duration_seconds"`
+	}{
+		loan:            loan(l),
+		DurationSeconds: int64(l.Duration(time.Now()).Seconds()),
+	})
+}
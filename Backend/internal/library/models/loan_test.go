@@ -0,0 +1,74 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestLoan_Duration(t *testing.T) {
+	checkedOut := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	now := checkedOut.Add(72 * time.Hour)
+
+	tests := []struct {
+		name  string
+		loan  Loan
+		want1 time.Duration
+	}{
+		{
+			name: "Open loan is measured until now",
+			loan: Loan{
+				CheckedOutTime: checkedOut,
+			},
+			want1: 72 * time.Hour,
+		},
+		{
+			name: "Returned loan is measured until it was returned",
+			loan: Loan{
+				CheckedOutTime: checkedOut,
+				Returned:       true,
+				ReturnedTime:   checkedOut.Add(24 * time.Hour),
+			},
+			want1: 24 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1 := tt.loan.Duration(now)
+
+			if got1 != tt.want1 {
+				t.Errorf("Duration got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestLoan_MarshalJSON(t *testing.T) {
+	checkedOut := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	loan := Loan{
+		Borrower:       "Sam",
+		CheckedOutTime: checkedOut,
+		Returned:       true,
+		ReturnedTime:   checkedOut.Add(time.Hour),
+	}
+
+	data, err := json.Marshal(loan)
+	if err != nil {
+		t.Fatalf("MarshalJSON error = %v", err)
+	}
+
+	var got map[string]interface{}
+	if err = json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Failed to unmarshal loan json: %v", err)
+	}
+
+	if got["borrower"] != "Sam" {
+		t.Errorf("MarshalJSON borrower = %v, want: %v", got["borrower"], "Sam")
+	}
+
+	if got["duration_seconds"] != float64(3600) {
+		t.Errorf("MarshalJSON duration_seconds = %v, want: %v", got["duration_seconds"], 3600)
+	}
+}
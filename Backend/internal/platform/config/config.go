@@ -2,44 +2,465 @@
 package config
 
 import (
-	"net/url"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
-// GetDBUserName returns the DB_USERNAME env configuration
-func GetDBUserName() string {
-	return os.Getenv("DB_USERNAME")
+// FileEnv is the env variable naming an optional YAML file to read settings from. Env variables
+// take precedence over the file, and the file over the defaults.
+const FileEnv = "BACKEND_CONFIG_FILE"
+
+// Config is every setting of the service. It is loaded once at startup by Load and passed to the
+// parts that need it. The env tag of each setting is the variable it is read from, and the same
+// variable with a _FILE suffix names a file holding the value, which is how Docker secrets are
+// passed in.
+type Config struct {
+	DB      DB      `yaml:"db"`
+	Server  Server  `yaml:"server"`
+	Log     Log     `yaml:"log"`
+	Auth    Auth    `yaml:"auth"`
+	Library Library `yaml:"library"`
+}
+
+// DB is the storage backend configuration
+type DB struct {
+	Driver   string `yaml:"driver" env:"DB_DRIVER"`
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Name     string `yaml:"name" env:"DB_NAME"`
+	Username string `yaml:"username" env:"DB_USERNAME"`
+	Password string `yaml:"password" env:"DB_PASSWORD"`
+	Path     string `yaml:"path" env:"DB_PATH"`
+}
+
+// Server is the HTTP server configuration
+type Server struct {
+	Host            string        `yaml:"host" env:"BACKEND_HOST"`
+	AllowedHosts    string        `yaml:"allowed_hosts" env:"BACKEND_ALLOWED_HOSTS"`
+	Production      bool          `yaml:"production" env:"BACKEND_PROD_FLAG"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"BACKEND_READ_TIMEOUT"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"BACKEND_WRITE_TIMEOUT"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"BACKEND_IDLE_TIMEOUT"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"BACKEND_SHUTDOWN_TIMEOUT"`
+	ReadyTimeout    time.Duration `yaml:"ready_timeout" env:"BACKEND_READY_TIMEOUT"`
+}
+
+// Log is the logger configuration. The level, format and sampling default to info, json and on in
+// production and to debug, console and off in development.
+type Log struct {
+	Level          string `yaml:"level" env:"LOG_LEVEL"`
+	Format         string `yaml:"format" env:"LOG_FORMAT"`
+	Sampling       *bool  `yaml:"sampling" env:"LOG_SAMPLING"`
+	File           string `yaml:"file" env:"LOG_FILE"`
+	FileMaxSizeMB  int    `yaml:"file_max_size_mb" env:"LOG_FILE_MAX_SIZE_MB"`
+	FileMaxBackups int    `yaml:"file_max_backups" env:"LOG_FILE_MAX_BACKUPS"`
+	FileMaxAgeDays int    `yaml:"file_max_age_days" env:"LOG_FILE_MAX_AGE_DAYS"`
+}
+
+// SamplingEnabled reports whether repeated log lines are sampled under load
+func (l Log) SamplingEnabled() bool {
+	return l.Sampling != nil && *l.Sampling
+}
+
+// Auth is the configuration of user accounts and sessions. The initial user is created at
+// startup when there are no users yet, so a new install can be logged in to.
+type Auth struct {
+	SessionLifetime time.Duration `yaml:"session_lifetime" env:"AUTH_SESSION_LIFETIME"`
+	InitialUsername string        `yaml:"initial_username" env:"AUTH_INITIAL_USERNAME"`
+	InitialPassword string        `yaml:"initial_password" env:"AUTH_INITIAL_PASSWORD"`
+	OIDC            OIDC          `yaml:"oidc"`
+}
+
+// OIDC is the configuration of logging in through an OpenID Connect identity provider, which is
+// turned on by setting the issuer. The role of a user is taken from the values of RoleClaim in
+// their ID token, checking the admin values first. Users matching none get DefaultRole, or can't
+// log in when it is empty. The value lists are separated by commas and the scopes by spaces.
+type OIDC struct {
+	Issuer        string `yaml:"issuer" env:"AUTH_OIDC_ISSUER"`
+	ClientID      string `yaml:"client_id" env:"AUTH_OIDC_CLIENT_ID"`
+	ClientSecret  string `yaml:"client_secret" env:"AUTH_OIDC_CLIENT_SECRET"`
+	RedirectURL   string `yaml:"redirect_url" env:"AUTH_OIDC_REDIRECT_URL"`
+	Scopes        string `yaml:"scopes" env:"AUTH_OIDC_SCOPES"`
+	RoleClaim     string `yaml:"role_claim" env:"AUTH_OIDC_ROLE_CLAIM"`
+	AdminValues   string `yaml:"admin_values" env:"AUTH_OIDC_ADMIN_VALUES"`
+	MemberValues  string `yaml:"member_values" env:"AUTH_OIDC_MEMBER_VALUES"`
+	GuestValues   string `yaml:"guest_values" env:"AUTH_OIDC_GUEST_VALUES"`
+	DefaultRole   string `yaml:"default_role" env:"AUTH_OIDC_DEFAULT_ROLE"`
+	LoginRedirect string `yaml:"login_redirect" env:"AUTH_OIDC_LOGIN_REDIRECT"`
+}
+
+// Enabled reports whether logging in through the identity provider is turned on
+func (o OIDC) Enabled() bool {
+	return o.Issuer != ""
+}
+
+// RoleValues returns the claim values that give each role, in the order they are checked
+func (o OIDC) RoleValues() []RoleValues {
+	return []RoleValues{
+		{Role: "admin", Values: splitList(o.AdminValues)},
+		{Role: "member", Values: splitList(o.MemberValues)},
+		{Role: "guest", Values: splitList(o.GuestValues)},
+	}
+}
+
+// RoleValues is the claim values that give a role
+type RoleValues struct {
+	Role   string
+	Values []string
+}
+
+// Library is the configuration of the library module
+type Library struct {
+	LoanPeriodDays int `yaml:"loan_period_days" env:"LIBRARY_LOAN_PERIOD_DAYS"`
+}
+
+// LoanPeriod returns how long a book may be checked out
+func (l Library) LoanPeriod() time.Duration {
+	return time.Duration(l.LoanPeriodDays) * 24 * time.Hour
+}
+
+// Errors is every problem found while loading the configuration
+type Errors []string
+
+// Error lists the problems, one per line
+func (errs Errors) Error() string {
+	return "invalid configuration:\n  " + strings.Join(errs, "\n  ")
+}
+
+// Default returns the configuration used when nothing is set, which is a development setup
+func Default() Config {
+	cfg := defaults()
+	cfg.resolve()
+
+	return cfg
+}
+
+// Load reads the configuration from the defaults, the file named by BACKEND_CONFIG_FILE and the env
+// in that order. Env variables that are set but empty are treated as unset, as docker compose
+// passes them that way. Every missing or invalid setting is reported at once as Errors.
+func Load() (Config, error) {
+	cfg := defaults()
+
+	var errs Errors
+
+	if path := os.Getenv(FileEnv); path != "" {
+		if err := readFile(path, &cfg); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", FileEnv, err))
+		}
+	}
+
+	errs = append(errs, readEnv(reflect.ValueOf(&cfg).Elem())...)
+
+	cfg.resolve()
+	errs = append(errs, cfg.validate()...)
+
+	if len(errs) > 0 {
+		return cfg, errs
+	}
+
+	return cfg, nil
+}
+
+// defaults returns the settings that don't depend on any other setting
+func defaults() Config {
+	return Config{
+		DB: DB{
+			Driver: "mongo",
+		},
+		Server: Server{
+			Host:            ":3000",
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+			ReadyTimeout:    2 * time.Second,
+		},
+		Log: Log{
+			FileMaxSizeMB:  100,
+			FileMaxBackups: 5,
+			FileMaxAgeDays: 28,
+		},
+		Auth: Auth{
+			SessionLifetime: 30 * 24 * time.Hour,
+			OIDC: OIDC{
+				Scopes:        "openid profile email",
+				RoleClaim:     "groups",
+				LoginRedirect: "/",
+			},
+		},
+		Library: Library{
+			LoanPeriodDays: 14,
+		},
+	}
 }
 
-// GetDBPassword returns the DB_PASSWORD env configuration
-func GetDBPassword() string {
-	return url.QueryEscape(os.Getenv("DB_PASSWORD"))
+// resolve fills in the settings whose defaults depend on whether this is production
+func (cfg *Config) resolve() {
+	cfg.DB.Driver = strings.ToLower(cfg.DB.Driver)
+	cfg.Log.Level = strings.ToLower(cfg.Log.Level)
+	cfg.Log.Format = strings.ToLower(cfg.Log.Format)
+
+	production := cfg.Server.Production
+
+	if cfg.Log.Level == "" {
+		cfg.Log.Level = "debug"
+		if production {
+			cfg.Log.Level = "info"
+		}
+	}
+
+	if cfg.Log.Format == "" {
+		cfg.Log.Format = "console"
+		if production {
+			cfg.Log.Format = "json"
+		}
+	}
+
+	if cfg.Log.Sampling == nil {
+		cfg.Log.Sampling = &production
+	}
+}
+
+// validate checks the settings against each other, returning every problem
+func (cfg Config) validate() Errors {
+	var errs Errors
+
+	switch cfg.DB.Driver {
+	case "mongo":
+		if cfg.DB.Host == "" {
+			errs = append(errs, "DB_HOST is required when DB_DRIVER is mongo")
+		}
+
+		if cfg.DB.Name == "" {
+			errs = append(errs, "DB_NAME is required when DB_DRIVER is mongo")
+		}
+
+		if cfg.DB.Username != "" && cfg.DB.Password == "" {
+			errs = append(errs, "DB_PASSWORD is required when DB_USERNAME is set")
+		}
+	case "sqlite":
+		if cfg.DB.Path == "" {
+			errs = append(errs, "DB_PATH is required when DB_DRIVER is sqlite")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("DB_DRIVER must be mongo or sqlite, not %q", cfg.DB.Driver))
+	}
+
+	if cfg.Server.Host == "" {
+		errs = append(errs, "BACKEND_HOST is required")
+	}
+
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"BACKEND_READ_TIMEOUT", cfg.Server.ReadTimeout},
+		{"BACKEND_WRITE_TIMEOUT", cfg.Server.WriteTimeout},
+		{"BACKEND_IDLE_TIMEOUT", cfg.Server.IdleTimeout},
+		{"BACKEND_SHUTDOWN_TIMEOUT", cfg.Server.ShutdownTimeout},
+		{"BACKEND_READY_TIMEOUT", cfg.Server.ReadyTimeout},
+		{"AUTH_SESSION_LIFETIME", cfg.Auth.SessionLifetime},
+	}
+	for _, duration := range durations {
+		if duration.value <= 0 {
+			errs = append(errs, fmt.Sprintf("%s must be a positive duration such as 30s", duration.name))
+		}
+	}
+
+	switch cfg.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Sprintf("LOG_LEVEL must be one of debug, info, warn or error, not %q", cfg.Log.Level))
+	}
+
+	if cfg.Log.Format != "json" && cfg.Log.Format != "console" {
+		errs = append(errs, fmt.Sprintf("LOG_FORMAT must be json or console, not %q", cfg.Log.Format))
+	}
+
+	if cfg.Auth.InitialUsername != "" && cfg.Auth.InitialPassword == "" {
+		errs = append(errs, "AUTH_INITIAL_PASSWORD is required when AUTH_INITIAL_USERNAME is set")
+	}
+
+	if cfg.Auth.OIDC.Enabled() {
+		if cfg.Auth.OIDC.ClientID == "" {
+			errs = append(errs, "AUTH_OIDC_CLIENT_ID is required when AUTH_OIDC_ISSUER is set")
+		}
+
+		if cfg.Auth.OIDC.RedirectURL == "" {
+			errs = append(errs, "AUTH_OIDC_REDIRECT_URL is required when AUTH_OIDC_ISSUER is set")
+		}
+
+		if !slices.Contains(strings.Fields(cfg.Auth.OIDC.Scopes), "openid") {
+			errs = append(errs, fmt.Sprintf("AUTH_OIDC_SCOPES must include openid, not %q", cfg.Auth.OIDC.Scopes))
+		}
+	}
+
+	switch cfg.Auth.OIDC.DefaultRole {
+	case "", "admin", "member", "guest":
+	default:
+		errs = append(errs, fmt.Sprintf("AUTH_OIDC_DEFAULT_ROLE must be empty or one of admin, member or guest, not %q", cfg.Auth.OIDC.DefaultRole))
+	}
+
+	numbers := []struct {
+		name  string
+		value int
+	}{
+		{"LOG_FILE_MAX_SIZE_MB", cfg.Log.FileMaxSizeMB},
+		{"LOG_FILE_MAX_BACKUPS", cfg.Log.FileMaxBackups},
+		{"LOG_FILE_MAX_AGE_DAYS", cfg.Log.FileMaxAgeDays},
+		{"LIBRARY_LOAN_PERIOD_DAYS", cfg.Library.LoanPeriodDays},
+	}
+	for _, number := range numbers {
+		if number.value <= 0 {
+			errs = append(errs, fmt.Sprintf("%s must be a positive number", number.name))
+		}
+	}
+
+	return errs
 }
 
-// GetDBHost returns the DB_HOST env configuration
-func GetDBHost() string {
-	return os.Getenv("DB_HOST")
+// readFile decodes a YAML configuration file over cfg. Unknown keys are an error, so a typo in
+// the file isn't silently ignored.
+func readFile(path string, cfg *Config) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+	default:
+		return fmt.Errorf("%s is not a .yaml or .yml file", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	if err = decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	return nil
 }
 
-// GetDBName returns the DB_Name env configuration
-func GetDBName() string {
-	return os.Getenv("DB_NAME")
+// readEnv sets every field with an env tag from its env variable, or from the file named by the
+// variable with a _FILE suffix
+func readEnv(v reflect.Value) Errors {
+	var errs Errors
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Type.Kind() == reflect.Struct {
+			errs = append(errs, readEnv(v.Field(i))...)
+			continue
+		}
+
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+
+		value, err := lookup(key)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		if value == "" {
+			continue
+		}
+
+		if err = setField(v.Field(i), value); err != nil {
+			errs = append(errs, fmt.Sprintf("%s %s", key, err))
+		}
+	}
+
+	return errs
 }
 
-// GetServerHost returns the BACKEND_HOST env configuration
-func GetServerHost() string {
-	return os.Getenv("BACKEND_HOST")
+// lookup returns the value of an env variable, or the contents of the file named by its _FILE
+// variant. Setting both is an error, as it isn't clear which should win.
+func lookup(key string) (string, error) {
+	value := os.Getenv(key)
+	path := os.Getenv(key + "_FILE")
+
+	if path == "" {
+		return value, nil
+	}
+
+	if value != "" {
+		return "", fmt.Errorf("only one of %s and %s_FILE can be set", key, key)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%s_FILE: %s", key, err)
+	}
+
+	// Secret files usually end with a newline that isn't part of the value
+	return strings.TrimRight(string(data), "\r\n"), nil
 }
 
-// GetAllowedHosts returns the BACKEND_ALLOWED_HOSTS env configuration
-func GetAllowedHosts() string {
-	return os.Getenv("BACKEND_ALLOWED_HOSTS")
+// setField parses a value into a field of any type used by Config
+func setField(field reflect.Value, value string) error {
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(value)
+	case bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("must be true or false, not %q", value)
+		}
+
+		field.SetBool(parsed)
+	case *bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("must be true or false, not %q", value)
+		}
+
+		field.Set(reflect.ValueOf(&parsed))
+	case int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("must be a whole number, not %q", value)
+		}
+
+		field.SetInt(int64(parsed))
+	case time.Duration:
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("must be a duration such as 30s, not %q", value)
+		}
+
+		field.SetInt(int64(parsed))
+	default:
+		panic(fmt.Sprintf("config: unsupported setting type %s", field.Type()))
+	}
+
+	return nil
 }
 
-// GetProductionFlag returns the BACKEND_PROD_FLAG env configuration
-func GetProductionFlag() bool {
-	flag := os.Getenv("BACKEND_PROD_FLAG")
+// splitList returns the items of a comma separated setting, without blanks
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
 
-	return strings.ToLower(flag) == "true"
+	return items
 }
@@ -1,204 +1,310 @@
 package config
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
-func TestGetDBUserName(t *testing.T) {
-	tests := []struct {
-		name string
+// settings are every env variable Load reads, cleared before each test so the environment the
+// tests run in can't leak into them
+var settings = []string{
+	FileEnv,
+	"DB_DRIVER", "DB_HOST", "DB_NAME", "DB_USERNAME", "DB_PASSWORD", "DB_PATH",
+	"BACKEND_HOST", "BACKEND_ALLOWED_HOSTS", "BACKEND_PROD_FLAG",
+	"BACKEND_READ_TIMEOUT", "BACKEND_WRITE_TIMEOUT", "BACKEND_IDLE_TIMEOUT", "BACKEND_SHUTDOWN_TIMEOUT", "BACKEND_READY_TIMEOUT",
+	"LOG_LEVEL", "LOG_FORMAT", "LOG_SAMPLING", "LOG_FILE", "LOG_FILE_MAX_SIZE_MB", "LOG_FILE_MAX_BACKUPS", "LOG_FILE_MAX_AGE_DAYS",
+	"AUTH_SESSION_LIFETIME", "AUTH_INITIAL_USERNAME", "AUTH_INITIAL_PASSWORD",
+	"AUTH_OIDC_ISSUER", "AUTH_OIDC_CLIENT_ID", "AUTH_OIDC_CLIENT_SECRET", "AUTH_OIDC_REDIRECT_URL", "AUTH_OIDC_SCOPES",
+	"AUTH_OIDC_ROLE_CLAIM", "AUTH_OIDC_ADMIN_VALUES", "AUTH_OIDC_MEMBER_VALUES", "AUTH_OIDC_GUEST_VALUES",
+	"AUTH_OIDC_DEFAULT_ROLE", "AUTH_OIDC_LOGIN_REDIRECT",
+	"LIBRARY_LOAN_PERIOD_DAYS",
+}
 
-		want string
-	}{
-		{
-			name: "Success - GetDBUserName Set Variable",
-			want: "Test",
-		},
-		{
-			name: "Success - GetDBUserName Unset Variable",
-			want: "",
-		},
-	}
+func setEnv(t *testing.T, env map[string]string) {
+	t.Helper()
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Setenv("DB_USERNAME", tt.want)
-			got := GetDBUserName()
+	for _, key := range settings {
+		t.Setenv(key, "")
+		t.Setenv(key+"_FILE", "")
+	}
 
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("GetDBUserName got = %v, want: %v", got, tt.want)
-			}
-		})
+	for key, value := range env {
+		t.Setenv(key, value)
 	}
 }
 
-func TestGetDBPassword(t *testing.T) {
-	tests := []struct {
-		name string
+func writeFile(t *testing.T, name string, contents string) string {
+	t.Helper()
 
-		want string
-	}{
-		{
-			name: "Success - GetDBPassword Set Variable",
-			want: "Test",
-		},
-		{
-			name: "Success - GetDBPassword Unset Variable",
-			want: "",
-		},
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Setenv("DB_PASSWORD", tt.want)
-			got := GetDBPassword()
-
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("GetDBPassword got = %v, want: %v", got, tt.want)
-			}
-		})
-	}
+	return path
 }
 
-func TestGetDBHost(t *testing.T) {
-	tests := []struct {
-		name string
+func TestLoad(t *testing.T) {
+	mongo := map[string]string{"DB_HOST": "db:27017", "DB_NAME": "library"}
 
-		want string
+	tests := []struct {
+		name    string
+		env     func(t *testing.T) map[string]string
+		inspect func(t *testing.T, cfg Config)
+		// want1 is the start of each problem expected, as some include details of the platform
+		want1 Errors
 	}{
 		{
-			name: "Success - GetDBHost Set Variable",
-			want: "Test",
+			name: "Defaults",
+			env:  func(_ *testing.T) map[string]string { return mongo },
+			inspect: func(t *testing.T, cfg Config) {
+				want := Default()
+				want.DB.Host = "db:27017"
+				want.DB.Name = "library"
+
+				if !reflect.DeepEqual(cfg, want) {
+					t.Errorf("Load got = %+v, want: %+v", cfg, want)
+				}
+			},
 		},
 		{
-			name: "Success - GetDBHost Unset Variable",
-			want: "",
+			name: "Production defaults",
+			env: func(_ *testing.T) map[string]string {
+				return map[string]string{"DB_HOST": "db", "DB_NAME": "library", "BACKEND_PROD_FLAG": "TRUE"}
+			},
+			inspect: func(t *testing.T, cfg Config) {
+				if cfg.Log.Level != "info" || cfg.Log.Format != "json" || !cfg.Log.SamplingEnabled() {
+					t.Errorf("Load log = %+v, want info, json and sampling", cfg.Log)
+				}
+			},
 		},
-	}
+		{
+			name: "Env settings",
+			env: func(_ *testing.T) map[string]string {
+				return map[string]string{
+					"DB_DRIVER":                "SQLite",
+					"DB_PATH":                  "/data/library.db",
+					"BACKEND_WRITE_TIMEOUT":    "1m",
+					"LOG_SAMPLING":             "true",
+					"LIBRARY_LOAN_PERIOD_DAYS": "21",
+				}
+			},
+			inspect: func(t *testing.T, cfg Config) {
+				if cfg.DB.Driver != "sqlite" || cfg.DB.Path != "/data/library.db" {
+					t.Errorf("Load db = %+v, want sqlite at /data/library.db", cfg.DB)
+				}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Setenv("DB_HOST", tt.want)
-			got := GetDBHost()
+				if cfg.Server.WriteTimeout != time.Minute {
+					t.Errorf("Load write timeout = %v, want: 1m", cfg.Server.WriteTimeout)
+				}
 
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("GetDBHost got = %v, want: %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestGetDBName(t *testing.T) {
-	tests := []struct {
-		name string
+				if !cfg.Log.SamplingEnabled() {
+					t.Error("Load sampling = false, want: true")
+				}
 
-		want string
-	}{
+				if cfg.Library.LoanPeriod() != 21*24*time.Hour {
+					t.Errorf("Load loan period = %v, want: 21 days", cfg.Library.LoanPeriod())
+				}
+			},
+		},
 		{
-			name: "Success - GetDBName Set Variable",
-			want: "Test",
+			name: "OIDC settings",
+			env: func(_ *testing.T) map[string]string {
+				return map[string]string{
+					"DB_HOST":                 "db",
+					"DB_NAME":                 "library",
+					"AUTH_OIDC_ISSUER":        "https://id.intranet.dev",
+					"AUTH_OIDC_CLIENT_ID":     "trove",
+					"AUTH_OIDC_REDIRECT_URL":  "https://api-trove.intranet.dev/v1/auth/oidc/callback",
+					"AUTH_OIDC_ADMIN_VALUES":  "trove-admins",
+					"AUTH_OIDC_MEMBER_VALUES": " family, friends ,",
+				}
+			},
+			inspect: func(t *testing.T, cfg Config) {
+				if !cfg.Auth.OIDC.Enabled() || cfg.Auth.OIDC.RoleClaim != "groups" {
+					t.Errorf("Load oidc = %+v, want enabled with the groups claim", cfg.Auth.OIDC)
+				}
+
+				want := []RoleValues{
+					{Role: "admin", Values: []string{"trove-admins"}},
+					{Role: "member", Values: []string{"family", "friends"}},
+					{Role: "guest"},
+				}
+				if got := cfg.Auth.OIDC.RoleValues(); !reflect.DeepEqual(got, want) {
+					t.Errorf("RoleValues got = %+v, want: %+v", got, want)
+				}
+			},
 		},
 		{
-			name: "Success - GetDBName Unset Variable",
-			want: "",
+			name: "Incomplete OIDC settings",
+			env: func(_ *testing.T) map[string]string {
+				return map[string]string{
+					"DB_HOST":                "db",
+					"DB_NAME":                "library",
+					"AUTH_OIDC_ISSUER":       "https://id.intranet.dev",
+					"AUTH_OIDC_SCOPES":       "profile email",
+					"AUTH_OIDC_DEFAULT_ROLE": "owner",
+				}
+			},
+			want1: Errors{
+				"AUTH_OIDC_CLIENT_ID is required when AUTH_OIDC_ISSUER is set",
+				"AUTH_OIDC_REDIRECT_URL is required when AUTH_OIDC_ISSUER is set",
+				`AUTH_OIDC_SCOPES must include openid, not "profile email"`,
+				`AUTH_OIDC_DEFAULT_ROLE must be empty or one of admin, member or guest, not "owner"`,
+			},
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Setenv("DB_NAME", tt.want)
-			got := GetDBName()
-
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("GetDBName got = %v, want: %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestGetServerHost(t *testing.T) {
-	tests := []struct {
-		name string
+		{
+			name: "File settings are overridden by the env",
+			env: func(t *testing.T) map[string]string {
+				path := writeFile(t, "backend.yaml", `
+db:
+  host: file-db
+  name: library
+server:
+  read_timeout: 5s
+library:
+  loan_period_days: 7
+`)
+				return map[string]string{FileEnv: path, "DB_HOST": "env-db"}
+			},
+			inspect: func(t *testing.T, cfg Config) {
+				if cfg.DB.Host != "env-db" || cfg.DB.Name != "library" {
+					t.Errorf("Load db = %+v, want host from the env and name from the file", cfg.DB)
+				}
 
-		want string
-	}{
+				if cfg.Server.ReadTimeout != 5*time.Second || cfg.Library.LoanPeriodDays != 7 {
+					t.Errorf("Load got = %+v, want the file settings", cfg)
+				}
+			},
+		},
 		{
-			name: "Success - GetServerHost Set Variable",
-			want: "Test",
+			name: "Secrets are read from files",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{
+					"DB_HOST":          "db",
+					"DB_NAME":          "library",
+					"DB_USERNAME":      "root",
+					"DB_PASSWORD_FILE": writeFile(t, "db_password", "s3cret\n"),
+				}
+			},
+			inspect: func(t *testing.T, cfg Config) {
+				if cfg.DB.Password != "s3cret" {
+					t.Errorf("Load password = %q, want: s3cret", cfg.DB.Password)
+				}
+			},
 		},
 		{
-			name: "Success - GetServerHost Unset Variable",
-			want: "",
+			name: "Every problem is reported",
+			env: func(_ *testing.T) map[string]string {
+				return map[string]string{
+					"DB_USERNAME":              "root",
+					"BACKEND_READ_TIMEOUT":     "soon",
+					"BACKEND_IDLE_TIMEOUT":     "-1s",
+					"LOG_LEVEL":                "loud",
+					"LOG_FILE_MAX_SIZE_MB":     "big",
+					"LOG_FILE_MAX_BACKUPS":     "0",
+					"BACKEND_PROD_FLAG":        "yes please",
+					"DB_PASSWORD_FILE":         "/missing/secret",
+					"LIBRARY_LOAN_PERIOD_DAYS": "-3",
+					"AUTH_INITIAL_USERNAME":    "admin",
+				}
+			},
+			want1: Errors{
+				"DB_PASSWORD_FILE: open /missing/secret: no such file or directory",
+				`BACKEND_PROD_FLAG must be true or false, not "yes please"`,
+				`BACKEND_READ_TIMEOUT must be a duration such as 30s, not "soon"`,
+				`LOG_FILE_MAX_SIZE_MB must be a whole number, not "big"`,
+				"DB_HOST is required when DB_DRIVER is mongo",
+				"DB_NAME is required when DB_DRIVER is mongo",
+				"DB_PASSWORD is required when DB_USERNAME is set",
+				"BACKEND_IDLE_TIMEOUT must be a positive duration such as 30s",
+				`LOG_LEVEL must be one of debug, info, warn or error, not "loud"`,
+				"AUTH_INITIAL_PASSWORD is required when AUTH_INITIAL_USERNAME is set",
+				"LOG_FILE_MAX_BACKUPS must be a positive number",
+				"LIBRARY_LOAN_PERIOD_DAYS must be a positive number",
+			},
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Setenv("BACKEND_HOST", tt.want)
-			got := GetServerHost()
-
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("GetServerHost got = %v, want: %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestGetAllowedHosts(t *testing.T) {
-	tests := []struct {
-		name string
-
-		want string
-	}{
 		{
-			name: "Success - GetAllowedHosts Set Variable",
-			want: "Test",
+			name: "Value and file both set",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{
+					"DB_HOST":          "db",
+					"DB_NAME":          "library",
+					"DB_PASSWORD":      "one",
+					"DB_PASSWORD_FILE": writeFile(t, "db_password", "two"),
+				}
+			},
+			want1: Errors{"only one of DB_PASSWORD and DB_PASSWORD_FILE can be set"},
 		},
 		{
-			name: "Success - GetAllowedHosts Unset Variable",
-			want: "",
+			name: "Log levels that stop the service aren't accepted",
+			env: func(_ *testing.T) map[string]string {
+				return map[string]string{"DB_HOST": "db", "DB_NAME": "library", "LOG_LEVEL": "fatal"}
+			},
+			want1: Errors{`LOG_LEVEL must be one of debug, info, warn or error, not "fatal"`},
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Setenv("BACKEND_ALLOWED_HOSTS", tt.want)
-			got := GetAllowedHosts()
-
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("GetAllowedHosts got = %v, want: %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestGetProductionFlag(t *testing.T) {
-	tests := []struct {
-		name string
-		set  string
-		want bool
-	}{
 		{
-			name: "Success -  Set True",
-			set:  "true",
-			want: true,
+			name: "Unknown file keys",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{FileEnv: writeFile(t, "backend.yml", "db:\n  hots: db\n  name: library\n")}
+			},
+			want1: Errors{
+				"BACKEND_CONFIG_FILE: yaml: unmarshal errors:",
+				"DB_HOST is required when DB_DRIVER is mongo",
+			},
 		},
 		{
-			name: "Success -  Unset",
-			set:  "",
-			want: false,
+			name: "Unsupported file format",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{FileEnv: writeFile(t, "backend.toml", ""), "DB_HOST": "db", "DB_NAME": "library"}
+			},
+			want1: Errors{"BACKEND_CONFIG_FILE: "},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			t.Setenv("BACKEND_PROD_FLAG", tt.set)
-			got := GetProductionFlag()
+			setEnv(t, tt.env(t))
+
+			got, err := Load()
 
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("GetProductionFlag got = %v, want: %v", got, tt.want)
+			if tt.want1 == nil {
+				if err != nil {
+					t.Fatalf("Load error = %v, want: nil", err)
+				}
+
+				tt.inspect(t, got)
+				return
+			}
+
+			var got1 Errors
+			if !errors.As(err, &got1) {
+				t.Fatalf("Load error = %v, want1: %v", err, tt.want1)
+			}
+
+			if len(got1) != len(tt.want1) {
+				t.Fatalf("Load got1 = %#v, want1: %#v", got1, tt.want1)
+			}
+
+			for i := range got1 {
+				if !strings.HasPrefix(got1[i], tt.want1[i]) {
+					t.Errorf("Load got1[%d] = %q, want1: %q", i, got1[i], tt.want1[i])
+				}
 			}
 		})
 	}
 }
+
+func TestErrors_Error(t *testing.T) {
+	errs := Errors{"DB_HOST is required when DB_DRIVER is mongo", "LOG_FORMAT must be json or console, not \"xml\""}
+
+	want := "invalid configuration:\n  DB_HOST is required when DB_DRIVER is mongo\n  LOG_FORMAT must be json or console, not \"xml\""
+	if got := errs.Error(); got != want {
+		t.Errorf("Error got = %q, want: %q", got, want)
+	}
+}
@@ -0,0 +1,43 @@
+// Package logger is the wrapper for our log tooling
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// contextKey is the key the request logger is stored under, a type of its own so no other package
+// can collide with it
+type contextKey struct{}
+
+// Replace swaps the application logger, returning a function that puts the previous one back.
+// Tests use it to capture what other packages log.
+func Replace(log *zap.Logger) func() {
+	previous := logger
+	logger = log
+
+	return func() {
+		logger = previous
+	}
+}
+
+// With returns a logger that adds the given fields to every entry
+func With(fields ...zap.Field) *zap.Logger {
+	return logger.With(fields...)
+}
+
+// WithContext returns a copy of ctx carrying log, which FromContext returns
+func WithContext(ctx context.Context, log *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, log)
+}
+
+// FromContext returns the logger stored in ctx, such as the one the access log middleware sets up
+// with the request id. Outside of a request it falls back to the application logger.
+func FromContext(ctx context.Context) *zap.Logger {
+	if log, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+		return log
+	}
+
+	return logger
+}
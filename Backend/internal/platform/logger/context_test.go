@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	customLogger := zap.New(
+		zapcore.NewCore(
+			zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+			zapcore.AddSync(&buf),
+			zapcore.DebugLevel,
+		),
+	)
+
+	originalLogger := logger
+	logger = customLogger
+	defer func() { logger = originalLogger }()
+
+	tests := []struct {
+		name      string
+		ctx       func() context.Context
+		wantField string
+	}{
+		{
+			name:      "Falls back to the application logger",
+			ctx:       context.Background,
+			wantField: "",
+		},
+		{
+			name: "Request logger",
+			ctx: func() context.Context {
+				return WithContext(context.Background(), With(zap.String("request_id", "abc")))
+			},
+			wantField: "abc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+
+			FromContext(tt.ctx()).Info("Handled")
+
+			var logEntry map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &logEntry); err != nil {
+				t.Fatalf("Failed to parse log output: %v", err)
+			}
+
+			if got, _ := logEntry["request_id"].(string); got != tt.wantField {
+				t.Errorf("FromContext request_id = %q, want: %q", got, tt.wantField)
+			}
+		})
+	}
+}
@@ -3,61 +3,129 @@ package logger
 
 import (
 	"Home-Intranet-v2-Backend/internal/platform/config"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-var logger, _ = newLogger()
+// level is the minimum level logged. It is shared by every logger built from the application
+// logger, so changing it at runtime takes effect everywhere at once.
+var level = zap.NewAtomicLevel()
 
-func newLogger() (*zap.Logger, error) {
-	var zapLogger *zap.Logger
-	var err error
+var logger = initLogger()
 
-	production := config.GetProductionFlag()
+// initLogger builds the logger used until Configure is called, so anything logged while the
+// configuration is loaded still goes somewhere
+func initLogger() *zap.Logger {
+	cfg := config.Default()
 
-	if production {
-		zapLogger, err = zap.NewProduction()
-	} else {
-		zapLogger, err = zap.NewDevelopment()
+	log, err := newLogger(cfg.Log, cfg.Server.Production)
+	if err != nil {
+		panic(err)
 	}
 
+	return log
+}
+
+// Configure replaces the application logger with one built from the configuration. It should be
+// called once at startup, before anything else is logged.
+func Configure(cfg config.Log, production bool) error {
+	log, err := newLogger(cfg, production)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return zapLogger, nil
+	logger = log
+
+	return nil
+}
+
+// newLogger builds the application logger. Entries go to stderr, and to a rotated file as well
+// when a log file is configured.
+func newLogger(cfg config.Log, production bool) (*zap.Logger, error) {
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, fmt.Errorf("issue reading log level: %w", err)
+	}
+
+	var encoderConfig zapcore.EncoderConfig
+	var encoder zapcore.Encoder
+
+	switch cfg.Format {
+	case "json":
+		encoderConfig = zap.NewProductionEncoderConfig()
+		encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	case "console":
+		encoderConfig = zap.NewDevelopmentEncoderConfig()
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+
+	sinks := []zapcore.WriteSyncer{zapcore.Lock(os.Stderr)}
+
+	if cfg.File != "" {
+		sinks = append(sinks, zapcore.AddSync(&lumberjack.Logger{
+			Filename:   cfg.File,
+			MaxSize:    cfg.FileMaxSizeMB,
+			MaxBackups: cfg.FileMaxBackups,
+			MaxAge:     cfg.FileMaxAgeDays,
+		}))
+	}
+
+	core := zapcore.NewCore(encoder, zapcore.NewMultiWriteSyncer(sinks...), level)
+
+	// Sampling keeps the first 100 identical entries each second and every 100th after that, so
+	// a burst of the same error can't flood the output
+	if cfg.SamplingEnabled() {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, 100, 100)
+	}
+
+	options := []zap.Option{zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel)}
+	if !production {
+		options = append(options, zap.Development())
+	}
+
+	return zap.New(core, options...), nil
+}
+
+// LevelHandler returns the handler for reading and changing the log level at runtime. A GET
+// returns the level as {"level":"info"} and a PUT with the same body changes it.
+func LevelHandler() http.Handler {
+	return level
+}
+
+// Sync flushes any buffered log entries. It should be called once before the process exits.
+func Sync() error {
+	return logger.Sync()
 }
 
 // Debug log messages at the debug level
 func Debug(msg string, fields ...zap.Field) {
 	logger.Debug(msg, fields...)
-	sync()
 }
 
 // Info log messages at the info level
 func Info(msg string, fields ...zap.Field) {
 	logger.Info(msg, fields...)
-	sync()
 }
 
 // Warn log messages at the warn level
 func Warn(msg string, fields ...zap.Field) {
 	logger.Warn(msg, fields...)
-	sync()
 }
 
 // Error log messages at the error level
 func Error(msg string, fields ...zap.Field) {
 	logger.Error(msg, fields...)
-	sync()
 }
 
-// Fatal log messages at the fatal level
+// Fatal log messages at the fatal level, then exits. Fatal entries are flushed before exiting.
 func Fatal(msg string, fields ...zap.Field) {
 	logger.Fatal(msg, fields...)
-	sync()
-}
-
-func sync() error {
-	return logger.Sync()
 }
@@ -1,9 +1,15 @@
 package logger
 
 import (
+	"Home-Intranet-v2-Backend/internal/platform/config"
 	"bytes"
 	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"go.uber.org/zap"
@@ -13,16 +19,16 @@ import (
 func Test_newLogger(t *testing.T) {
 	tests := []struct {
 		name       string
-		prodFlag   string
+		production bool
 		want1      *zap.Logger
 		wantErr    bool
 		inspectErr func(err error, t *testing.T)
 		inspectLog func(log *zap.Logger, t *testing.T)
 	}{
 		{
-			name:     "Production Logger",
-			prodFlag: "true",
-			wantErr:  false,
+			name:       "Production Logger",
+			production: true,
+			wantErr:    false,
 			inspectLog: func(log *zap.Logger, t *testing.T) {
 				if log.Core().Enabled(zap.DebugLevel) {
 					t.Error("Production logger should not have debug level enabled")
@@ -30,9 +36,9 @@ func Test_newLogger(t *testing.T) {
 			},
 		},
 		{
-			name:     "Development Logger",
-			prodFlag: "false",
-			wantErr:  false,
+			name:       "Development Logger",
+			production: false,
+			wantErr:    false,
 			inspectLog: func(log *zap.Logger, t *testing.T) {
 				if !log.Core().Enabled(zap.DebugLevel) {
 					t.Error("Development logger should have debug level enabled")
@@ -43,9 +49,17 @@ func Test_newLogger(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			t.Setenv("BACKEND_PROD_FLAG", tt.prodFlag)
+			cfg := config.Default()
+			cfg.Server.Production = tt.production
+			cfg.Log.Level = "debug"
+			if tt.production {
+				cfg.Log.Level = "info"
+			}
+
+			originalLevel := level.Level()
+			defer level.SetLevel(originalLevel)
 
-			got1, err := newLogger()
+			got1, err := newLogger(cfg.Log, cfg.Server.Production)
 
 			if (err != nil) != tt.wantErr {
 				t.Fatalf("newLogger error = %v, wantErr: %t", err, tt.wantErr)
@@ -567,7 +581,7 @@ func TestFatal(t *testing.T) {
 	}
 }
 
-func Test_sync(t *testing.T) {
+func TestSync(t *testing.T) {
 	tests := []struct {
 		name        string
 		setupLogger func() *zap.Logger
@@ -589,18 +603,145 @@ func Test_sync(t *testing.T) {
 			logger = tt.setupLogger()
 			defer func() { logger = originalLogger }()
 
-			err := sync()
+			err := Sync()
 
-			t.Logf("sync() returned error: %v", err)
+			t.Logf("Sync() returned error: %v", err)
 
 			if tt.wantErr != (err != nil) {
-				t.Errorf("sync() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("Sync() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
 			if tt.inspectErr != nil && !tt.inspectErr(err) {
-				t.Errorf("sync() unexpected error: %v", err)
+				t.Errorf("Sync() unexpected error: %v", err)
 			}
 		})
 	}
 }
+
+func Test_newLogger_config(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        func(dir string) config.Log
+		wantErr    bool
+		inspectLog func(t *testing.T, log *zap.Logger, dir string)
+	}{
+		{
+			name: "Level from the configuration",
+			cfg: func(_ string) config.Log {
+				return config.Log{Level: "warn", Format: "console"}
+			},
+			inspectLog: func(t *testing.T, log *zap.Logger, _ string) {
+				if log.Core().Enabled(zap.InfoLevel) || !log.Core().Enabled(zap.WarnLevel) {
+					t.Error("Logger should log warn and above")
+				}
+			},
+		},
+		{
+			name: "File sink",
+			cfg: func(dir string) config.Log {
+				return config.Log{Level: "info", Format: "json", File: filepath.Join(dir, "backend.log"), FileMaxSizeMB: 1, FileMaxBackups: 1, FileMaxAgeDays: 1}
+			},
+			inspectLog: func(t *testing.T, log *zap.Logger, dir string) {
+				log.Info("Written to the file", zap.String("key1", "value1"))
+
+				data, err := os.ReadFile(filepath.Join(dir, "backend.log"))
+				if err != nil {
+					t.Fatalf("Failed to read log file: %v", err)
+				}
+
+				var logEntry map[string]interface{}
+				if err = json.Unmarshal(data, &logEntry); err != nil {
+					t.Fatalf("Failed to parse log file: %v", err)
+				}
+
+				if logEntry["msg"] != "Written to the file" || logEntry["key1"] != "value1" {
+					t.Errorf("Log file entry = %v, want the message and its fields", logEntry)
+				}
+			},
+		},
+		{
+			name: "Unknown level",
+			cfg: func(_ string) config.Log {
+				return config.Log{Level: "loud", Format: "console"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unknown format",
+			cfg: func(_ string) config.Log {
+				return config.Log{Level: "info", Format: "xml"}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			originalLevel := level.Level()
+			defer level.SetLevel(originalLevel)
+
+			got1, err := newLogger(tt.cfg(dir), false)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newLogger error = %v, wantErr: %t", err, tt.wantErr)
+			}
+
+			if tt.inspectLog != nil && got1 != nil {
+				tt.inspectLog(t, got1, dir)
+			}
+		})
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	originalLevel := level.Level()
+	defer level.SetLevel(originalLevel)
+
+	level.SetLevel(zapcore.InfoLevel)
+
+	recorder := httptest.NewRecorder()
+	LevelHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/debug/log-level", strings.NewReader(`{"level":"error"}`)))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("LevelHandler status code = %v, want: %v", recorder.Code, http.StatusOK)
+	}
+
+	if level.Level() != zapcore.ErrorLevel {
+		t.Errorf("LevelHandler level = %v, want: error", level.Level())
+	}
+
+	recorder = httptest.NewRecorder()
+	LevelHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/log-level", nil))
+
+	if body := strings.TrimSpace(recorder.Body.String()); body != `{"level":"error"}` {
+		t.Errorf("LevelHandler body = %v, want: {\"level\":\"error\"}", body)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	originalLogger := logger
+	originalLevel := level.Level()
+	defer func() {
+		logger = originalLogger
+		level.SetLevel(originalLevel)
+	}()
+
+	if err := Configure(config.Log{Level: "loud", Format: "json"}, true); err == nil {
+		t.Fatal("Configure error = nil, want an error for an unknown level")
+	}
+
+	if logger != originalLogger {
+		t.Error("Configure replaced the logger with an invalid configuration")
+	}
+
+	if err := Configure(config.Log{Level: "error", Format: "json"}, true); err != nil {
+		t.Fatalf("Configure error = %v, want: nil", err)
+	}
+
+	if logger == originalLogger || logger.Core().Enabled(zap.WarnLevel) {
+		t.Error("Configure should replace the logger with one logging error and above")
+	}
+}
@@ -0,0 +1,127 @@
+// Package metrics is the wrapper for our Prometheus instrumentation
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// registry holds every metric of the service. A registry of our own, rather than the global one,
+// keeps metrics registered by dependencies out of the endpoint.
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Number of HTTP requests handled, by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time taken to handle HTTP requests, by method, route pattern and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	httpInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "Number of HTTP requests currently being handled.",
+	})
+
+	repositoryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "repository_operation_duration_seconds",
+		Help:    "Time taken by repository operations, by collection and operation.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"collection", "operation"})
+
+	repositoryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "repository_operation_errors_total",
+		Help: "Number of repository operations that failed, by collection and operation. Documents that weren't found aren't counted.",
+	}, []string{"collection", "operation"})
+
+	mongoConnections = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "mongo_pool_connections",
+		Help: "Number of connections open in the MongoDB connection pool.",
+	})
+
+	mongoConnectionsInUse = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "mongo_pool_connections_in_use",
+		Help: "Number of MongoDB connections checked out of the pool.",
+	})
+
+	mongoCheckoutFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mongo_pool_checkout_failures_total",
+		Help: "Number of times a MongoDB connection couldn't be checked out of the pool, by reason.",
+	}, []string{"reason"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		httpInFlight,
+		repositoryDuration,
+		repositoryErrors,
+		mongoConnections,
+		mongoConnectionsInUse,
+		mongoCheckoutFailures,
+	)
+}
+
+// Handler returns the handler serving every metric in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// RequestStarted records a request being taken on. The returned function records it finishing and
+// must be called once its route and status are known.
+func RequestStarted() func(method string, route string, status int) {
+	start := time.Now()
+	httpInFlight.Inc()
+
+	return func(method string, route string, status int) {
+		httpInFlight.Dec()
+
+		code := strconv.Itoa(status)
+		httpRequests.WithLabelValues(method, route, code).Inc()
+		httpDuration.WithLabelValues(method, route, code).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveRepository records how long a repository operation on a collection took and whether it
+// failed
+func ObserveRepository(collection string, operation string, duration time.Duration, failed bool) {
+	repositoryDuration.WithLabelValues(collection, operation).Observe(duration.Seconds())
+
+	if failed {
+		repositoryErrors.WithLabelValues(collection, operation).Inc()
+	}
+}
+
+// MongoPoolMonitor returns the monitor that keeps the MongoDB connection pool metrics up to date.
+// It is set on the client options when connecting.
+func MongoPoolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(evt *event.PoolEvent) {
+			switch evt.Type {
+			case event.ConnectionCreated:
+				mongoConnections.Inc()
+			case event.ConnectionClosed:
+				mongoConnections.Dec()
+			case event.GetSucceeded:
+				mongoConnectionsInUse.Inc()
+			case event.ConnectionReturned:
+				mongoConnectionsInUse.Dec()
+			case event.GetFailed:
+				mongoCheckoutFailures.WithLabelValues(evt.Reason).Inc()
+			}
+		},
+	}
+}
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func TestRequestStarted(t *testing.T) {
+	before := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/v1/books/{id}", "404"))
+
+	finish := RequestStarted()
+
+	if got := testutil.ToFloat64(httpInFlight); got != 1 {
+		t.Errorf("http_requests_in_flight = %v, want: 1", got)
+	}
+
+	finish("GET", "/v1/books/{id}", http.StatusNotFound)
+
+	if got := testutil.ToFloat64(httpInFlight); got != 0 {
+		t.Errorf("http_requests_in_flight = %v, want: 0", got)
+	}
+
+	if got := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/v1/books/{id}", "404")); got != before+1 {
+		t.Errorf("http_requests_total = %v, want: %v", got, before+1)
+	}
+}
+
+func TestObserveRepository(t *testing.T) {
+	tests := []struct {
+		name       string
+		failed     bool
+		wantErrors float64
+	}{
+		{
+			name:       "Successful operation",
+			failed:     false,
+			wantErrors: 0,
+		},
+		{
+			name:       "Failed operation",
+			failed:     true,
+			wantErrors: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operation := strings.ReplaceAll(tt.name, " ", "_")
+			ObserveRepository("books", operation, 3*time.Millisecond, tt.failed)
+
+			if got := testutil.CollectAndCount(repositoryDuration, "repository_operation_duration_seconds"); got == 0 {
+				t.Error("repository_operation_duration_seconds has no series")
+			}
+
+			if got := testutil.ToFloat64(repositoryErrors.WithLabelValues("books", operation)); got != tt.wantErrors {
+				t.Errorf("repository_operation_errors_total = %v, want: %v", got, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestMongoPoolMonitor(t *testing.T) {
+	monitor := MongoPoolMonitor()
+
+	for _, eventType := range []string{event.ConnectionCreated, event.ConnectionCreated, event.GetSucceeded, event.ConnectionReturned, event.GetSucceeded, event.ConnectionClosed} {
+		monitor.Event(&event.PoolEvent{Type: eventType})
+	}
+	monitor.Event(&event.PoolEvent{Type: event.GetFailed, Reason: event.ReasonTimedOut})
+
+	if got := testutil.ToFloat64(mongoConnections); got != 1 {
+		t.Errorf("mongo_pool_connections = %v, want: 1", got)
+	}
+
+	if got := testutil.ToFloat64(mongoConnectionsInUse); got != 1 {
+		t.Errorf("mongo_pool_connections_in_use = %v, want: 1", got)
+	}
+
+	if got := testutil.ToFloat64(mongoCheckoutFailures.WithLabelValues(event.ReasonTimedOut)); got != 1 {
+		t.Errorf("mongo_pool_checkout_failures_total = %v, want: 1", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	RequestStarted()("GET", "/", http.StatusOK)
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Handler status code = %v, want: %v", recorder.Code, http.StatusOK)
+	}
+
+	for _, name := range []string{"http_requests_total", "http_request_duration_seconds_bucket", "http_requests_in_flight", "go_goroutines"} {
+		if !strings.Contains(recorder.Body.String(), name) {
+			t.Errorf("Handler body is missing %s", name)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Package repository servers as the wrapper for our data persistance packages
+package repository
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Filter is a condition documents have to meet. Filters are built with the functions below,
+// nested with And and Or, and compile to the MongoDB query language every backend understands.
+// A Filter can be passed to any Repository method that takes a filter.
+type Filter struct {
+	doc bson.D
+}
+
+// D returns the compiled filter document
+func (f Filter) D() bson.D {
+	if f.doc == nil {
+		return bson.D{}
+	}
+
+	return f.doc
+}
+
+// IsEmpty reports whether the filter matches every document
+func (f Filter) IsEmpty() bool {
+	return len(f.doc) == 0
+}
+
+// MarshalBSON compiles the filter when it is sent to the database
+func (f Filter) MarshalBSON() ([]byte, error) {
+	return bson.Marshal(f.D())
+}
+
+// Eq matches documents where field equals value, or contains it when field is an array
+func Eq(field string, value interface{}) Filter {
+	return condition(field, bson.D{{Key: "$eq", Value: value}})
+}
+
+// Ne matches documents where field doesn't equal value
+func Ne(field string, value interface{}) Filter {
+	return condition(field, bson.D{{Key: "$ne", Value: value}})
+}
+
+// Gt matches documents where field is greater than value
+func Gt(field string, value interface{}) Filter {
+	return condition(field, bson.D{{Key: "$gt", Value: value}})
+}
+
+// Gte matches documents where field is greater than or equal to value
+func Gte(field string, value interface{}) Filter {
+	return condition(field, bson.D{{Key: "$gte", Value: value}})
+}
+
+// Lt matches documents where field is less than value
+func Lt(field string, value interface{}) Filter {
+	return condition(field, bson.D{{Key: "$lt", Value: value}})
+}
+
+// Lte matches documents where field is less than or equal to value
+func Lte(field string, value interface{}) Filter {
+	return condition(field, bson.D{{Key: "$lte", Value: value}})
+}
+
+// In matches documents where field equals any of the values
+func In(field string, values ...interface{}) Filter {
+	return condition(field, bson.D{{Key: "$in", Value: bson.A(append([]interface{}{}, values...))}})
+}
+
+// Regex matches documents where field matches a regular expression. Options are the MongoDB
+// regex options, such as "i" for a case insensitive match.
+func Regex(field string, pattern string, options string) Filter {
+	match := bson.D{{Key: "$regex", Value: pattern}}
+	if options != "" {
+		match = append(match, bson.E{Key: "$options", Value: options})
+	}
+
+	return condition(field, match)
+}
+
+// Exists matches documents that have field when exists is true, and those without it otherwise
+func Exists(field string, exists bool) Filter {
+	return condition(field, bson.D{{Key: "$exists", Value: exists}})
+}
+
+// Between matches documents where field is at or after from and before to. A zero time leaves that
+// end of the range open.
+func Between(field string, from time.Time, to time.Time) Filter {
+	bounds := bson.D{}
+	if !from.IsZero() {
+		bounds = append(bounds, bson.E{Key: "$gte", Value: from})
+	}
+
+	if !to.IsZero() {
+		bounds = append(bounds, bson.E{Key: "$lt", Value: to})
+	}
+
+	if len(bounds) == 0 {
+		return Filter{}
+	}
+
+	return condition(field, bounds)
+}
+
+// And matches documents that meet every filter. Empty filters are ignored.
+func And(filters ...Filter) Filter {
+	parts := nonEmpty(filters)
+
+	switch len(parts) {
+	case 0:
+		return Filter{}
+	case 1:
+		return parts[0]
+	}
+
+	return Filter{doc: bson.D{{Key: "$and", Value: compile(parts)}}}
+}
+
+// Or matches documents that meet at least one filter. Since an empty filter matches everything,
+// an Or containing one, or no filters at all, places no condition.
+func Or(filters ...Filter) Filter {
+	parts := nonEmpty(filters)
+
+	if len(parts) == 0 || len(parts) < len(filters) {
+		return Filter{}
+	}
+
+	if len(parts) == 1 {
+		return parts[0]
+	}
+
+	return Filter{doc: bson.D{{Key: "$or", Value: compile(parts)}}}
+}
+
+// condition builds the filter for a single field
+func condition(field string, operators bson.D) Filter {
+	return Filter{doc: bson.D{{Key: field, Value: operators}}}
+}
+
+// nonEmpty drops the filters that place no condition
+func nonEmpty(filters []Filter) []Filter {
+	parts := make([]Filter, 0, len(filters))
+	for _, filter := range filters {
+		if !filter.IsEmpty() {
+			parts = append(parts, filter)
+		}
+	}
+
+	return parts
+}
+
+// compile returns the documents of a list of filters
+func compile(filters []Filter) bson.A {
+	docs := make(bson.A, 0, len(filters))
+	for _, filter := range filters {
+		docs = append(docs, filter.D())
+	}
+
+	return docs
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestFilter_D(t *testing.T) {
+	from := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want1  bson.D
+	}{
+		{
+			name:   "Zero filter",
+			filter: Filter{},
+			want1:  bson.D{},
+		},
+		{
+			name:   "Comparison",
+			filter: Gte("size", 3),
+			want1:  bson.D{{Key: "size", Value: bson.D{{Key: "$gte", Value: 3}}}},
+		},
+		{
+			name:   "In list",
+			filter: In("shelf", "4", "12"),
+			want1:  bson.D{{Key: "shelf", Value: bson.D{{Key: "$in", Value: bson.A{"4", "12"}}}}},
+		},
+		{
+			name:   "Regex with options",
+			filter: Regex("name", "^sprocket", "i"),
+			want1:  bson.D{{Key: "name", Value: bson.D{{Key: "$regex", Value: "^sprocket"}, {Key: "$options", Value: "i"}}}},
+		},
+		{
+			name:   "Date range",
+			filter: Between("made_at", from, to),
+			want1:  bson.D{{Key: "made_at", Value: bson.D{{Key: "$gte", Value: from}, {Key: "$lt", Value: to}}}},
+		},
+		{
+			name:   "Open ended date range",
+			filter: Between("made_at", time.Time{}, to),
+			want1:  bson.D{{Key: "made_at", Value: bson.D{{Key: "$lt", Value: to}}}},
+		},
+		{
+			name:   "And of one filter is the filter",
+			filter: And(Filter{}, Eq("active", true)),
+			want1:  bson.D{{Key: "active", Value: bson.D{{Key: "$eq", Value: true}}}},
+		},
+		{
+			name:   "Or nested in And",
+			filter: And(Exists("parts", true), Or(Eq("size", 3), Ne("shelf", "12"))),
+			want1: bson.D{{Key: "$and", Value: bson.A{
+				bson.D{{Key: "parts", Value: bson.D{{Key: "$exists", Value: true}}}},
+				bson.D{{Key: "$or", Value: bson.A{
+					bson.D{{Key: "size", Value: bson.D{{Key: "$eq", Value: 3}}}},
+					bson.D{{Key: "shelf", Value: bson.D{{Key: "$ne", Value: "12"}}}},
+				}}},
+			}}},
+		},
+		{
+			name:   "Or with an empty filter matches everything",
+			filter: Or(Eq("size", 3), Filter{}),
+			want1:  bson.D{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1 := tt.filter.D()
+
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("D got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestFilter_List(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	madeAt := time.Date(2026, time.January, 10, 0, 0, 0, 0, time.UTC)
+
+	for i, name := range []string{"Sprocket", "Gizmo", "Widget"} {
+		if _, err := Create(ctx, repo, widget{Name: name, Shelf: "12", Size: i, MadeAt: madeAt.AddDate(0, i, 0)}); err != nil {
+			t.Fatalf("Create error = %v", err)
+		}
+	}
+
+	filter := Or(
+		Between("made_at", madeAt.AddDate(0, 1, 0), madeAt.AddDate(0, 2, 0)),
+		And(Eq("shelf", "12"), Lt("size", 1)),
+	)
+
+	got1, err := List[widget](ctx, repo, filter, Sort{Asc("name")}, 0, 0)
+	if err != nil {
+		t.Fatalf("List error = %v", err)
+	}
+
+	names := []string{}
+	for _, w := range got1 {
+		names = append(names, w.Name)
+	}
+
+	if want1 := []string{"Gizmo", "Sprocket"}; !reflect.DeepEqual(names, want1) {
+		t.Errorf("List got1 = %v, want1: %v", names, want1)
+	}
+}
@@ -0,0 +1,34 @@
+// Package repository servers as the wrapper for our data persistance packages
+package repository
+
+import (
+	"context"
+)
+
+// List is used to list all documents of type T that match a filter. The collection is inferred from
+// T and an empty result is an empty slice rather than nil.
+func List[T any](ctx context.Context, repo Repository, filter interface{}, sort Sort, offset int64, limit int64) ([]T, error) {
+	results := []T{}
+
+	if err := repo.List(ctx, &results, filter, sort, offset, limit); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Get is used to find one document of type T based on a filter
+func Get[T any](ctx context.Context, repo Repository, filter interface{}) (T, error) {
+	var model T
+
+	err := repo.Read(ctx, &model, filter)
+
+	return model, err
+}
+
+// Create is used to insert a new document of type T, returning it with the id and timestamps set
+func Create[T any](ctx context.Context, repo Repository, model T) (T, error) {
+	err := repo.Create(ctx, &model)
+
+	return model, err
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestCreate(t *testing.T) {
+	repo := NewMemoryRepository()
+
+	got1, err := Create(context.Background(), repo, widget{Name: "Sprocket"})
+	if err != nil {
+		t.Fatalf("Create error = %v", err)
+	}
+
+	if got1.ID.IsZero() || got1.CreatedAt.IsZero() || got1.UpdatedAt.IsZero() {
+		t.Errorf("Create got1 = %+v, want the id and timestamps set", got1)
+	}
+}
+
+func TestGet(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	madeAt := time.Date(2026, time.January, 10, 0, 0, 0, 0, time.UTC)
+
+	created, err := Create(ctx, repo, widget{Name: "Sprocket", MadeAt: madeAt})
+	if err != nil {
+		t.Fatalf("Create error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		filter       interface{}
+		want1        string
+		wantNotFound bool
+	}{
+		{
+			name:   "Found by id",
+			filter: bson.D{{Key: "_id", Value: created.ID}},
+			want1:  "Sprocket",
+		},
+		{
+			name:         "Not found",
+			filter:       bson.D{{Key: "name", Value: "Gizmo"}},
+			wantNotFound: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1, err := Get[widget](ctx, repo, tt.filter)
+
+			if repo.IsNotFoundError(err) != tt.wantNotFound {
+				t.Fatalf("Get error = %v, wantNotFound: %t", err, tt.wantNotFound)
+			}
+
+			if got1.Name != tt.want1 {
+				t.Errorf("Get got1 = %v, want1: %v", got1.Name, tt.want1)
+			}
+
+			if !tt.wantNotFound && (got1.ID != created.ID || !got1.MadeAt.Equal(madeAt)) {
+				t.Errorf("Get got1 = %+v, want the stored id and time", got1)
+			}
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	for _, name := range []string{"Sprocket", "Gizmo"} {
+		if _, err := Create(ctx, repo, widget{Name: name}); err != nil {
+			t.Fatalf("Create error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter interface{}
+		want1  []string
+	}{
+		{
+			name:   "Sorted matches",
+			filter: bson.D{},
+			want1:  []string{"Gizmo", "Sprocket"},
+		},
+		{
+			name:   "No matches is an empty slice",
+			filter: bson.D{{Key: "name", Value: "Missing"}},
+			want1:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1, err := List[widget](ctx, repo, tt.filter, Sort{Asc("name")}, 0, 0)
+			if err != nil {
+				t.Fatalf("List error = %v", err)
+			}
+
+			if got1 == nil {
+				t.Fatal("List got1 = nil, want an empty slice")
+			}
+
+			names := []string{}
+			for _, w := range got1 {
+				names = append(names, w.Name)
+			}
+
+			if !reflect.DeepEqual(names, tt.want1) {
+				t.Errorf("List got1 = %v, want1: %v", names, tt.want1)
+			}
+		})
+	}
+}
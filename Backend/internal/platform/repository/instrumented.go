@@ -0,0 +1,87 @@
+// Package repository servers as the wrapper for our data persistance packages
+package repository
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/metrics"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// InstrumentedRepository wraps another repository, recording how long each operation takes per
+// collection. Documents that aren't found are an answer rather than a failure, so they aren't
+// counted as errors.
+type InstrumentedRepository struct {
+	Repository
+}
+
+// Instrument is used to wrap a repository so its operations are timed
+func Instrument(repo Repository) *InstrumentedRepository {
+	return &InstrumentedRepository{
+		Repository: repo,
+	}
+}
+
+// Create is used to insert a new document into a collection
+func (db *InstrumentedRepository) Create(ctx context.Context, model interface{}) error {
+	start := time.Now()
+	err := db.Repository.Create(ctx, model)
+	return observe(model, "create", start, err)
+}
+
+// Read is used to find one document based on a filter
+func (db *InstrumentedRepository) Read(ctx context.Context, model interface{}, filter interface{}) error {
+	start := time.Now()
+	err := db.Repository.Read(ctx, model, filter)
+	return observe(model, "read", start, err)
+}
+
+// List is used to decode all documents in a collection that match a filter into results
+func (db *InstrumentedRepository) List(ctx context.Context, results interface{}, filter interface{}, sort Sort, offset int64, limit int64) error {
+	start := time.Now()
+	err := db.Repository.List(ctx, results, filter, sort, offset, limit)
+	return observe(results, "list", start, err)
+}
+
+// Count is used to count the documents in a collection that match a filter
+func (db *InstrumentedRepository) Count(ctx context.Context, model interface{}, filter interface{}) (int64, error) {
+	start := time.Now()
+	count, err := db.Repository.Count(ctx, model, filter)
+	return count, observe(model, "count", start, err)
+}
+
+// Update is used to replace a document in specified collection
+func (db *InstrumentedRepository) Update(ctx context.Context, model interface{}, filter interface{}) error {
+	start := time.Now()
+	err := db.Repository.Update(ctx, model, filter)
+	return observe(model, "update", start, err)
+}
+
+// UpdateFields is used to atomically set fields on the first document matching a filter
+func (db *InstrumentedRepository) UpdateFields(ctx context.Context, model interface{}, filter interface{}, fields bson.D) error {
+	start := time.Now()
+	err := db.Repository.UpdateFields(ctx, model, filter, fields)
+	return observe(model, "update_fields", start, err)
+}
+
+// Delete is used to delete a document in specified collection
+func (db *InstrumentedRepository) Delete(ctx context.Context, model interface{}, filter interface{}) error {
+	start := time.Now()
+	err := db.Repository.Delete(ctx, model, filter)
+	return observe(model, "delete", start, err)
+}
+
+// observe records an operation that started at start and returns its error unchanged
+func observe(model interface{}, operation string, start time.Time, err error) error {
+	collection, nameErr := getCollectionName(model)
+	if nameErr != nil {
+		collection = "unknown"
+	}
+
+	failed := err != nil && !errors.Is(err, ErrNotFound)
+	metrics.ObserveRepository(collection, operation, time.Since(start), failed)
+
+	return err
+}
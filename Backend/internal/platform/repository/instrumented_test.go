@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/metrics"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInstrumentedRepository(t *testing.T) {
+	testRepository(t, func(_ *testing.T) Repository {
+		return Instrument(NewMemoryRepository())
+	})
+
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	for _, series := range []string{
+		`repository_operation_duration_seconds_count{collection="widgets",operation="create"}`,
+		`repository_operation_duration_seconds_count{collection="widgets",operation="list"}`,
+		`repository_operation_duration_seconds_count{collection="widgets",operation="delete"}`,
+	} {
+		if !strings.Contains(recorder.Body.String(), series) {
+			t.Errorf("Instrumented operations are missing %s", series)
+		}
+	}
+}
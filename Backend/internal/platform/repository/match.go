@@ -0,0 +1,569 @@
+// Package repository servers as the wrapper for our data persistance packages
+package repository
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The in memory and embedded storage backends evaluate MongoDB style filters themselves, so the
+// handlers can build one filter document and have it behave the same on every backend. Only the
+// subset of the query language the application uses is supported.
+
+// normalizeFilter converts a filter into a bson.D by round tripping it through BSON, so Go values
+// such as time.Time and int become the same BSON types the stored documents hold
+func normalizeFilter(filter interface{}) (bson.D, error) {
+	if filter == nil {
+		return bson.D{}, nil
+	}
+
+	data, err := bson.Marshal(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	var doc bson.D
+	if err = bson.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	return doc, nil
+}
+
+// matchDocument reports whether a stored document satisfies a normalized filter
+func matchDocument(doc bson.M, filter bson.D) (bool, error) {
+	for _, element := range filter {
+		matched, err := matchElement(doc, element)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func matchElement(doc bson.M, element bson.E) (bool, error) {
+	switch element.Key {
+	case "$and", "$or", "$nor":
+		clauses, ok := element.Value.(bson.A)
+		if !ok {
+			return false, fmt.Errorf("%s must be an array", element.Key)
+		}
+
+		for _, clause := range clauses {
+			clauseDoc, ok := asDocument(clause)
+			if !ok {
+				return false, fmt.Errorf("%s clauses must be documents", element.Key)
+			}
+
+			matched, err := matchDocument(doc, clauseDoc)
+			if err != nil {
+				return false, err
+			}
+
+			if element.Key == "$and" && !matched {
+				return false, nil
+			}
+
+			if element.Key == "$or" && matched {
+				return true, nil
+			}
+
+			if element.Key == "$nor" && matched {
+				return false, nil
+			}
+		}
+
+		return element.Key != "$or", nil
+	}
+
+	if strings.HasPrefix(element.Key, "$") {
+		return false, fmt.Errorf("unsupported top level operator %s", element.Key)
+	}
+
+	values := lookupValues(doc, strings.Split(element.Key, "."))
+
+	if operators, ok := asDocument(element.Value); ok && isOperatorDocument(operators) {
+		return matchOperators(values, operators)
+	}
+
+	return matchEquals(values, element.Value), nil
+}
+
+// matchOperators checks the values found at a path against every operator in the document
+func matchOperators(values []interface{}, operators bson.D) (bool, error) {
+	for _, operator := range operators {
+		var matched bool
+		var err error
+
+		switch operator.Key {
+		case "$eq":
+			matched = matchEquals(values, operator.Value)
+		case "$ne":
+			matched = !matchEquals(values, operator.Value)
+		case "$gt", "$gte", "$lt", "$lte":
+			matched = matchComparison(values, operator.Key, operator.Value)
+		case "$in", "$nin":
+			list, ok := operator.Value.(bson.A)
+			if !ok {
+				return false, fmt.Errorf("%s must be an array", operator.Key)
+			}
+
+			for _, item := range list {
+				if matchEquals(values, item) {
+					matched = true
+					break
+				}
+			}
+
+			if operator.Key == "$nin" {
+				matched = !matched
+			}
+		case "$exists":
+			matched = (len(values) > 0) == isTruthy(operator.Value)
+		case "$regex":
+			matched, err = matchRegex(values, operator.Value, lookupOption(operators, "$options"))
+		case "$options":
+			matched = true
+		case "$not":
+			inner, ok := asDocument(operator.Value)
+			if !ok {
+				return false, fmt.Errorf("$not must be a document")
+			}
+
+			matched, err = matchOperators(values, inner)
+			matched = !matched
+		case "$elemMatch":
+			inner, ok := asDocument(operator.Value)
+			if !ok {
+				return false, fmt.Errorf("$elemMatch must be a document")
+			}
+
+			matched, err = matchElemMatch(values, inner)
+		default:
+			return false, fmt.Errorf("unsupported operator %s", operator.Key)
+		}
+
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// matchEquals follows MongoDB equality: an array matches when it equals the value or when any of
+// its elements does, and a null value matches a missing field
+func matchEquals(values []interface{}, expected interface{}) bool {
+	if expected == nil && len(values) == 0 {
+		return true
+	}
+
+	for _, candidate := range expandArrays(values) {
+		if valuesEqual(candidate, expected) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchComparison(values []interface{}, operator string, expected interface{}) bool {
+	for _, candidate := range expandArrays(values) {
+		result, ok := compareValues(candidate, expected)
+		if !ok {
+			continue
+		}
+
+		switch operator {
+		case "$gt":
+			if result > 0 {
+				return true
+			}
+		case "$gte":
+			if result >= 0 {
+				return true
+			}
+		case "$lt":
+			if result < 0 {
+				return true
+			}
+		case "$lte":
+			if result <= 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func matchRegex(values []interface{}, pattern interface{}, options string) (bool, error) {
+	var expression string
+
+	switch typed := pattern.(type) {
+	case string:
+		expression = typed
+	case primitive.Regex:
+		expression = typed.Pattern
+		options += typed.Options
+	default:
+		return false, fmt.Errorf("$regex must be a string")
+	}
+
+	flags := ""
+	for _, option := range []string{"i", "m", "s"} {
+		if strings.Contains(options, option) {
+			flags += option
+		}
+	}
+
+	if flags != "" {
+		expression = "(?" + flags + ")" + expression
+	}
+
+	compiled, err := regexp.Compile(expression)
+	if err != nil {
+		return false, fmt.Errorf("invalid $regex: %w", err)
+	}
+
+	for _, candidate := range expandArrays(values) {
+		if text, ok := candidate.(string); ok && compiled.MatchString(text) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func matchElemMatch(values []interface{}, filter bson.D) (bool, error) {
+	for _, value := range values {
+		array, ok := value.(bson.A)
+		if !ok {
+			continue
+		}
+
+		for _, item := range array {
+			var matched bool
+			var err error
+
+			if isOperatorDocument(filter) {
+				matched, err = matchOperators([]interface{}{item}, filter)
+			} else if itemDoc, ok := asMap(item); ok {
+				matched, err = matchDocument(itemDoc, filter)
+			}
+
+			if err != nil {
+				return false, err
+			}
+
+			if matched {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// lookupValues returns every value found at a dotted path. Arrays of documents are walked into, so
+// "authors._id" finds the id of each author.
+func lookupValues(value interface{}, path []string) []interface{} {
+	if len(path) == 0 {
+		return []interface{}{value}
+	}
+
+	if doc, ok := asMap(value); ok {
+		child, exists := doc[path[0]]
+		if !exists {
+			return nil
+		}
+
+		return lookupValues(child, path[1:])
+	}
+
+	if array, ok := value.(bson.A); ok {
+		if index, err := strconv.Atoi(path[0]); err == nil {
+			if index >= 0 && index < len(array) {
+				return lookupValues(array[index], path[1:])
+			}
+			return nil
+		}
+
+		var found []interface{}
+		for _, item := range array {
+			if _, ok := asMap(item); ok {
+				found = append(found, lookupValues(item, path)...)
+			}
+		}
+
+		return found
+	}
+
+	return nil
+}
+
+// expandArrays adds the elements of any array value to the values, since MongoDB compares
+// against both the array and each of its elements
+func expandArrays(values []interface{}) []interface{} {
+	expanded := make([]interface{}, 0, len(values))
+
+	for _, value := range values {
+		expanded = append(expanded, value)
+
+		if array, ok := value.(bson.A); ok {
+			expanded = append(expanded, array...)
+		}
+	}
+
+	return expanded
+}
+
+// sortValue returns the value a document is sorted by for a path, or nil when it is missing
+func sortValue(doc bson.M, path string) interface{} {
+	values := lookupValues(doc, strings.Split(path, "."))
+	if len(values) == 0 {
+		return nil
+	}
+
+	return values[0]
+}
+
+// compareForSort orders any two values, using the MongoDB ordering of types when they differ
+func compareForSort(a interface{}, b interface{}) int {
+	rankA, rankB := typeRank(a), typeRank(b)
+	if rankA != rankB {
+		if rankA < rankB {
+			return -1
+		}
+		return 1
+	}
+
+	result, ok := compareValues(a, b)
+	if !ok {
+		return 0
+	}
+
+	return result
+}
+
+// compareValues compares two values of the same kind. The second return value is false when the
+// values can't be compared with each other.
+func compareValues(a interface{}, b interface{}) (int, bool) {
+	if numberA, ok := asNumber(a); ok {
+		numberB, ok := asNumber(b)
+		if !ok {
+			return 0, false
+		}
+
+		switch {
+		case numberA < numberB:
+			return -1, true
+		case numberA > numberB:
+			return 1, true
+		}
+
+		return 0, true
+	}
+
+	switch typedA := a.(type) {
+	case nil:
+		return 0, b == nil
+	case string:
+		typedB, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(typedA, typedB), true
+	case bool:
+		typedB, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case typedA == typedB:
+			return 0, true
+		case !typedA:
+			return -1, true
+		}
+		return 1, true
+	case primitive.DateTime:
+		typedB, ok := b.(primitive.DateTime)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case typedA < typedB:
+			return -1, true
+		case typedA > typedB:
+			return 1, true
+		}
+		return 0, true
+	case primitive.ObjectID:
+		typedB, ok := b.(primitive.ObjectID)
+		if !ok {
+			return 0, false
+		}
+		return bytes.Compare(typedA[:], typedB[:]), true
+	}
+
+	return 0, false
+}
+
+// valuesEqual compares two values, treating every number type as the same and comparing documents
+// and arrays element by element
+func valuesEqual(a interface{}, b interface{}) bool {
+	if result, ok := compareValues(a, b); ok {
+		return result == 0
+	}
+
+	if docA, ok := asMap(a); ok {
+		docB, ok := asMap(b)
+		if !ok || len(docA) != len(docB) {
+			return false
+		}
+
+		for key, value := range docA {
+			other, exists := docB[key]
+			if !exists || !valuesEqual(value, other) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if arrayA, ok := a.(bson.A); ok {
+		arrayB, ok := b.(bson.A)
+		if !ok || len(arrayA) != len(arrayB) {
+			return false
+		}
+
+		for i := range arrayA {
+			if !valuesEqual(arrayA[i], arrayB[i]) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// typeRank is the position of a value's type in the MongoDB sort order
+func typeRank(value interface{}) int {
+	if _, ok := asNumber(value); ok {
+		return 2
+	}
+
+	if _, ok := asMap(value); ok {
+		return 4
+	}
+
+	switch value.(type) {
+	case nil:
+		return 1
+	case string:
+		return 3
+	case bson.A:
+		return 5
+	case primitive.Binary:
+		return 6
+	case primitive.ObjectID:
+		return 7
+	case bool:
+		return 8
+	case primitive.DateTime:
+		return 9
+	case primitive.Timestamp:
+		return 10
+	case primitive.Regex:
+		return 11
+	}
+
+	return 12
+}
+
+func asNumber(value interface{}) (float64, bool) {
+	switch typed := value.(type) {
+	case int32:
+		return float64(typed), true
+	case int64:
+		return float64(typed), true
+	case int:
+		return float64(typed), true
+	case float64:
+		return typed, true
+	}
+
+	return 0, false
+}
+
+// asDocument returns a filter value as an ordered document
+func asDocument(value interface{}) (bson.D, bool) {
+	switch typed := value.(type) {
+	case bson.D:
+		return typed, true
+	case bson.M:
+		doc := bson.D{}
+		for key, item := range typed {
+			doc = append(doc, bson.E{Key: key, Value: item})
+		}
+		return doc, true
+	}
+
+	return nil, false
+}
+
+// asMap returns a stored value as a map when it is an embedded document
+func asMap(value interface{}) (bson.M, bool) {
+	switch typed := value.(type) {
+	case bson.M:
+		return typed, true
+	case bson.D:
+		doc := bson.M{}
+		for _, element := range typed {
+			doc[element.Key] = element.Value
+		}
+		return doc, true
+	}
+
+	return nil, false
+}
+
+// isOperatorDocument reports whether a document holds query operators rather than a value to compare
+func isOperatorDocument(doc bson.D) bool {
+	return len(doc) > 0 && strings.HasPrefix(doc[0].Key, "$")
+}
+
+func lookupOption(doc bson.D, key string) string {
+	for _, element := range doc {
+		if element.Key == key {
+			if text, ok := element.Value.(string); ok {
+				return text
+			}
+		}
+	}
+
+	return ""
+}
+
+func isTruthy(value interface{}) bool {
+	if number, ok := asNumber(value); ok {
+		return number != 0
+	}
+
+	if flag, ok := value.(bool); ok {
+		return flag
+	}
+
+	return value != nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func Test_matchDocument(t *testing.T) {
+	doc := bson.M{
+		"title": "The Hobbit",
+		"pages": int32(310),
+		"tags":  bson.A{"fantasy", "classic"},
+		"authors": bson.A{
+			bson.M{"first_name": "J.R.R.", "last_name": "Tolkien"},
+		},
+		"series": nil,
+	}
+
+	tests := []struct {
+		name    string
+		filter  interface{}
+		want1   bool
+		wantErr bool
+	}{
+		{
+			name:   "Array contains value",
+			filter: bson.D{{Key: "tags", Value: "classic"}},
+			want1:  true,
+		},
+		{
+			name:   "Numbers compare across types",
+			filter: bson.D{{Key: "pages", Value: bson.D{{Key: "$gte", Value: 310.0}}}},
+			want1:  true,
+		},
+		{
+			name:   "Missing field equals null",
+			filter: bson.D{{Key: "subtitle", Value: nil}},
+			want1:  true,
+		},
+		{
+			name:   "Exists",
+			filter: bson.D{{Key: "subtitle", Value: bson.D{{Key: "$exists", Value: true}}}},
+			want1:  false,
+		},
+		{
+			name:   "Not equal",
+			filter: bson.D{{Key: "title", Value: bson.D{{Key: "$ne", Value: "The Hobbit"}}}},
+			want1:  false,
+		},
+		{
+			name:   "Nor",
+			filter: bson.D{{Key: "$nor", Value: bson.A{bson.D{{Key: "pages", Value: 100}}}}},
+			want1:  true,
+		},
+		{
+			name:   "Not regex",
+			filter: bson.D{{Key: "title", Value: bson.D{{Key: "$not", Value: bson.D{{Key: "$regex", Value: "^the"}, {Key: "$options", Value: "i"}}}}}},
+			want1:  false,
+		},
+		{
+			name:   "Element match",
+			filter: bson.D{{Key: "authors", Value: bson.D{{Key: "$elemMatch", Value: bson.D{{Key: "first_name", Value: "J.R.R."}, {Key: "last_name", Value: "Tolkien"}}}}}},
+			want1:  true,
+		},
+		{
+			name:    "Unsupported operator",
+			filter:  bson.D{{Key: "pages", Value: bson.D{{Key: "$where", Value: "true"}}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := normalizeFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("normalizeFilter error = %v", err)
+			}
+
+			got1, err := matchDocument(doc, filter)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("matchDocument error = %v, wantErr: %t", err, tt.wantErr)
+			}
+
+			if got1 != tt.want1 {
+				t.Errorf("matchDocument got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
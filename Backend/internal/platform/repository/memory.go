@@ -0,0 +1,582 @@
+// Package repository servers as the wrapper for our data persistance packages
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryRepository is an in memory implementation of Repository. Documents are kept as BSON and
+// filtered with the same query language as MongoDB, so handlers behave the same against it. It is
+// safe for concurrent use.
+type MemoryRepository struct {
+	mutex       sync.RWMutex
+	collections map[string]*memoryCollection
+}
+
+// memoryCollection holds the documents of one collection in insertion order, and the fields no two
+// of them may share a value of
+type memoryCollection struct {
+	ids       []primitive.ObjectID
+	documents map[primitive.ObjectID]bson.Raw
+	unique    []string
+}
+
+// NewMemoryRepository is used to create an empty in memory repository
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		collections: map[string]*memoryCollection{},
+	}
+}
+
+// Create is used to insert a new document into a collection
+func (db *MemoryRepository) Create(ctx context.Context, model interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	collectionName, err := getCollectionName(model)
+	if err != nil {
+		return err
+	}
+
+	model, err = setDefaultFields(model, true)
+	if err != nil {
+		return err
+	}
+
+	id := primitive.NewObjectID()
+
+	idField := reflect.ValueOf(model).Elem().FieldByName("ID")
+	if idField.IsValid() {
+		if existing, ok := idField.Interface().(primitive.ObjectID); ok && !existing.IsZero() {
+			id = existing
+		}
+	}
+
+	raw, err := toDocument(model, id)
+	if err != nil {
+		return err
+	}
+
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	collection := db.collection(collectionName)
+	if _, exists := collection.documents[id]; exists {
+		return fmt.Errorf("%w: %s in %s", ErrDuplicateKey, id.Hex(), collectionName)
+	}
+
+	if err = collection.checkUnique(collectionName, collection.unique, raw, id); err != nil {
+		return err
+	}
+
+	collection.ids = append(collection.ids, id)
+	collection.documents[id] = raw
+
+	if idField.IsValid() && idField.CanSet() {
+		idField.Set(reflect.ValueOf(id))
+	}
+
+	return nil
+}
+
+// Read is used to find one document based on a filter
+func (db *MemoryRepository) Read(ctx context.Context, model interface{}, filter interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	collectionName, err := getCollectionName(model)
+	if err != nil {
+		return err
+	}
+
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	collection := db.readCollection(collectionName)
+
+	ids, err := collection.find(filter)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		return ErrNotFound
+	}
+
+	return bson.Unmarshal(collection.documents[ids[0]], model)
+}
+
+// List is used to list all documents in a collection that match a filter
+func (db *MemoryRepository) List(ctx context.Context, results interface{}, filter interface{}, sortColumns Sort, offset int64, limit int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	collectionName, err := getCollectionName(results)
+	if err != nil {
+		return err
+	}
+
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	collection := db.readCollection(collectionName)
+
+	ids, err := collection.find(filter)
+	if err != nil {
+		return err
+	}
+
+	docs := make([]storedDocument, 0, len(ids))
+	for _, id := range ids {
+		doc, err := newStoredDocument(collection.documents[id])
+		if err != nil {
+			return err
+		}
+
+		docs = append(docs, doc)
+	}
+
+	sortDocuments(docs, buildSort(sortColumns))
+
+	return decodeDocuments(paginate(docs, offset, limit), results)
+}
+
+// Count is used to count the documents in a collection that match a filter
+func (db *MemoryRepository) Count(ctx context.Context, model interface{}, filter interface{}) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	collectionName, err := getCollectionName(model)
+	if err != nil {
+		return 0, err
+	}
+
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	ids, err := db.readCollection(collectionName).find(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(ids)), nil
+}
+
+// Update is used to replace a document in specified collection
+func (db *MemoryRepository) Update(ctx context.Context, model interface{}, filter interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	collectionName, err := getCollectionName(model)
+	if err != nil {
+		return err
+	}
+
+	model, err = setDefaultFields(model, false)
+	if err != nil {
+		return err
+	}
+
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	collection := db.collection(collectionName)
+
+	ids, err := collection.find(filter)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		return ErrNotFound
+	}
+
+	// Like a MongoDB replace, the stored document keeps its id
+	raw, err := toDocument(model, ids[0])
+	if err != nil {
+		return err
+	}
+
+	if err = collection.checkUnique(collectionName, collection.unique, raw, ids[0]); err != nil {
+		return err
+	}
+
+	collection.documents[ids[0]] = raw
+
+	return nil
+}
+
+// UpdateFields is used to atomically set fields on the first document matching a filter. The model
+// is populated with the document as it is after the update.
+func (db *MemoryRepository) UpdateFields(ctx context.Context, model interface{}, filter interface{}, fields bson.D) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	collectionName, err := getCollectionName(model)
+	if err != nil {
+		return err
+	}
+
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	collection := db.collection(collectionName)
+
+	ids, err := collection.find(filter)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		return ErrNotFound
+	}
+
+	var doc bson.D
+	if err = bson.Unmarshal(collection.documents[ids[0]], &doc); err != nil {
+		return err
+	}
+
+	set := append(bson.D{}, fields...)
+	set = append(set, bson.E{Key: "updated_at", Value: time.Now().UTC()})
+
+	for _, field := range set {
+		if field.Key == "_id" {
+			return fmt.Errorf("%w: the _id field can't be updated", ErrInvalidDocument)
+		}
+
+		doc = setField(doc, field.Key, field.Value)
+	}
+
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	if err = collection.checkUnique(collectionName, collection.unique, raw, ids[0]); err != nil {
+		return err
+	}
+
+	collection.documents[ids[0]] = raw
+
+	return bson.Unmarshal(raw, model)
+}
+
+// Delete is used to delete a document in specified collection
+func (db *MemoryRepository) Delete(ctx context.Context, model interface{}, filter interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	collectionName, err := getCollectionName(model)
+	if err != nil {
+		return err
+	}
+
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	collection := db.collection(collectionName)
+
+	ids, err := collection.find(filter)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		return ErrNotFound
+	}
+
+	delete(collection.documents, ids[0])
+	for i, id := range collection.ids {
+		if id == ids[0] {
+			collection.ids = append(collection.ids[:i], collection.ids[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+// EnsureUniqueIndex is used to refuse writes that would repeat the value of a field. Like creating
+// a MongoDB index, it fails when stored documents already repeat a value.
+func (db *MemoryRepository) EnsureUniqueIndex(ctx context.Context, model interface{}, field string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	collectionName, err := getCollectionName(model)
+	if err != nil {
+		return err
+	}
+
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	collection := db.collection(collectionName)
+	if slices.Contains(collection.unique, field) {
+		return nil
+	}
+
+	for _, id := range collection.ids {
+		if err = collection.checkUnique(collectionName, []string{field}, collection.documents[id], id); err != nil {
+			return err
+		}
+	}
+
+	collection.unique = append(collection.unique, field)
+
+	return nil
+}
+
+// IsNotFoundError verifies the type of error returning from a find query
+func (db *MemoryRepository) IsNotFoundError(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// Ping always succeeds, as the documents only live in memory
+func (db *MemoryRepository) Ping(_ context.Context) error {
+	return nil
+}
+
+// Close does nothing, as the documents only live in memory
+func (db *MemoryRepository) Close(_ context.Context) error {
+	return nil
+}
+
+// collection returns the named collection, creating it on first use like MongoDB does. Callers
+// must hold the mutex.
+func (db *MemoryRepository) collection(name string) *memoryCollection {
+	collection, ok := db.collections[name]
+	if !ok {
+		collection = &memoryCollection{
+			documents: map[primitive.ObjectID]bson.Raw{},
+		}
+		db.collections[name] = collection
+	}
+
+	return collection
+}
+
+// readCollection returns the named collection without creating it, for callers holding only the
+// read lock
+func (db *MemoryRepository) readCollection(name string) *memoryCollection {
+	collection, ok := db.collections[name]
+	if !ok {
+		return &memoryCollection{
+			documents: map[primitive.ObjectID]bson.Raw{},
+		}
+	}
+
+	return collection
+}
+
+// find returns the ids of every document matching the filter, in insertion order
+func (collection *memoryCollection) find(filter interface{}) ([]primitive.ObjectID, error) {
+	normalized, err := normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []primitive.ObjectID
+	for _, id := range collection.ids {
+		var doc bson.M
+		if err = bson.Unmarshal(collection.documents[id], &doc); err != nil {
+			return nil, err
+		}
+
+		matched, err := matchDocument(doc, normalized)
+		if err != nil {
+			return nil, err
+		}
+
+		if matched {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+// checkUnique returns ErrDuplicateKey when a document would repeat the value another document has
+// for one of the unique fields. Callers must hold the mutex.
+func (collection *memoryCollection) checkUnique(name string, fields []string, raw bson.Raw, id primitive.ObjectID) error {
+	for _, filter := range uniqueFilters(fields, raw, id) {
+		ids, err := collection.find(filter)
+		if err != nil {
+			return err
+		}
+
+		if len(ids) > 0 {
+			return fmt.Errorf("%w: %s in %s", ErrDuplicateKey, filter[0].Key, name)
+		}
+	}
+
+	return nil
+}
+
+// uniqueFilters builds, for each unique field a document has a value for, the filter finding any
+// other document with the same value. Empty values are left out, like a partial MongoDB index.
+func uniqueFilters(fields []string, raw bson.Raw, id primitive.ObjectID) []bson.D {
+	var filters []bson.D
+
+	for _, field := range fields {
+		value, ok := raw.Lookup(strings.Split(field, ".")...).StringValueOK()
+		if !ok || value == "" {
+			continue
+		}
+
+		filters = append(filters, bson.D{
+			{Key: field, Value: value},
+			{Key: "_id", Value: bson.D{{Key: "$ne", Value: id}}},
+		})
+	}
+
+	return filters
+}
+
+// toDocument marshals a model into BSON with the given id as its _id
+func toDocument(model interface{}, id primitive.ObjectID) (bson.Raw, error) {
+	data, err := bson.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc bson.D
+	if err = bson.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	withID := bson.D{{Key: "_id", Value: id}}
+	for _, element := range doc {
+		if element.Key != "_id" {
+			withID = append(withID, element)
+		}
+	}
+
+	return bson.Marshal(withID)
+}
+
+// setField sets a value at a dotted path in a document, creating embedded documents as needed
+func setField(doc bson.D, path string, value interface{}) bson.D {
+	key, rest, nested := strings.Cut(path, ".")
+
+	for i, element := range doc {
+		if element.Key != key {
+			continue
+		}
+
+		if !nested {
+			doc[i].Value = value
+			return doc
+		}
+
+		child, ok := element.Value.(bson.D)
+		if !ok {
+			child = bson.D{}
+		}
+
+		doc[i].Value = setField(child, rest, value)
+		return doc
+	}
+
+	if !nested {
+		return append(doc, bson.E{Key: key, Value: value})
+	}
+
+	return append(doc, bson.E{Key: key, Value: setField(bson.D{}, rest, value)})
+}
+
+// storedDocument is a document as it is stored along with its decoded fields, which sorting needs
+type storedDocument struct {
+	raw    bson.Raw
+	fields bson.M
+}
+
+// newStoredDocument decodes the fields of a stored document
+func newStoredDocument(raw bson.Raw) (storedDocument, error) {
+	doc := storedDocument{raw: raw}
+	err := bson.Unmarshal(raw, &doc.fields)
+
+	return doc, err
+}
+
+// decodeDocuments unmarshals documents into results, a pointer to a slice, replacing its contents
+func decodeDocuments(docs []storedDocument, results interface{}) error {
+	value := reflect.ValueOf(results)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("results must be a pointer to a slice")
+	}
+
+	elemType := value.Elem().Type().Elem()
+	slice := reflect.MakeSlice(value.Elem().Type(), 0, len(docs))
+
+	for _, doc := range docs {
+		elem := reflect.New(elemType)
+		if err := bson.Unmarshal(doc.raw, elem.Interface()); err != nil {
+			return err
+		}
+
+		slice = reflect.Append(slice, elem.Elem())
+	}
+
+	value.Elem().Set(slice)
+
+	return nil
+}
+
+// sortDocuments orders documents by each sort column in turn, where 1 is ascending and -1 descending
+func sortDocuments(docs []storedDocument, columns bson.D) {
+	sort.SliceStable(docs, func(i, j int) bool {
+		for _, column := range columns {
+			result := compareForSort(sortValue(docs[i].fields, column.Key), sortValue(docs[j].fields, column.Key))
+			if result == 0 {
+				continue
+			}
+
+			if direction, ok := asNumber(column.Value); ok && direction < 0 {
+				return result > 0
+			}
+
+			return result < 0
+		}
+
+		return false
+	})
+}
+
+// paginate applies a skip and limit to a result set. A limit of zero or less returns every result
+// after the offset, matching MongoDB.
+func paginate(docs []storedDocument, offset int64, limit int64) []storedDocument {
+	if offset < 0 {
+		offset = 0
+	}
+
+	if offset >= int64(len(docs)) {
+		return docs[:0]
+	}
+
+	docs = docs[offset:]
+
+	if limit > 0 && limit < int64(len(docs)) {
+		docs = docs[:limit]
+	}
+
+	return docs
+}
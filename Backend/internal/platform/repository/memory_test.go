@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"testing"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	testRepository(t, func(_ *testing.T) Repository {
+		return NewMemoryRepository()
+	})
+}
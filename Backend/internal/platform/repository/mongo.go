@@ -3,32 +3,39 @@ package repository
 
 import (
 	"Home-Intranet-v2-Backend/internal/platform/config"
-	"Home-Intranet-v2-Backend/internal/platform/pluralizer"
+	"Home-Intranet-v2-Backend/internal/platform/metrics"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"reflect"
-	"strconv"
-	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
+// immutableFieldCode is the MongoDB error code for an update that changes the _id of a document
+const immutableFieldCode = 66
+
+// MongoRepository is the MongoDB implementation of Repository
+type MongoRepository struct {
+	Mongo *mongo.Database
+}
+
 // Connect is used to create a new connection to our MongoDB
-func Connect() (*mongo.Database, error) {
-	username := config.GetDBUserName()
-	password := config.GetDBPassword()
-	host := config.GetDBHost()
-	database := config.GetDBName()
+func Connect(cfg config.DB) (*mongo.Database, error) {
+	uri := "mongodb://" + cfg.Host
 
-	uri := fmt.Sprintf("mongodb://%s:%s@%s", username, password, host)
+	// Credentials are escaped as they may contain characters with a meaning in the URI
+	if cfg.Username != "" {
+		uri = fmt.Sprintf("mongodb://%s:%s@%s", url.QueryEscape(cfg.Username), url.QueryEscape(cfg.Password), cfg.Host)
+	}
 
-	clientOptions := options.Client().ApplyURI(uri)
+	clientOptions := options.Client().ApplyURI(uri).SetPoolMonitor(metrics.MongoPoolMonitor())
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -42,11 +49,11 @@ func Connect() (*mongo.Database, error) {
 		return nil, fmt.Errorf("issue verifying Mongo Client connection: %w", err)
 	}
 
-	return client.Database(database), nil
+	return client.Database(cfg.Name), nil
 }
 
 // Create is used to insert a new document into a collection
-func (db *Repository) Create(ctx context.Context, model interface{}) error {
+func (db *MongoRepository) Create(ctx context.Context, model interface{}) error {
 	collectionName, err := getCollectionName(model)
 	if err != nil {
 		return err
@@ -72,7 +79,7 @@ func (db *Repository) Create(ctx context.Context, model interface{}) error {
 
 	res, err := collection.InsertOne(ctx, model)
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 
 	if idField.IsValid() && idField.CanSet() {
@@ -83,7 +90,7 @@ func (db *Repository) Create(ctx context.Context, model interface{}) error {
 }
 
 // Read is used to find one document based on a filter
-func (db *Repository) Read(ctx context.Context, model interface{}, filter interface{}) error {
+func (db *MongoRepository) Read(ctx context.Context, model interface{}, filter interface{}) error {
 	collectionName, err := getCollectionName(model)
 	if err != nil {
 		return err
@@ -92,42 +99,47 @@ func (db *Repository) Read(ctx context.Context, model interface{}, filter interf
 	collection := db.Mongo.Collection(collectionName)
 
 	if err = collection.FindOne(ctx, filter).Decode(model); err != nil {
-		return err
+		return translateError(err)
 	}
 
 	return nil
 }
 
-// List is used to list all documents in a collection
-func (db *Repository) List(ctx context.Context, model interface{}, filter map[string]string, sort map[string]string, offset int64, limit int64) ([]byte, error) {
-	collectionName, err := getCollectionName(model)
+// List is used to list all documents in a collection that match a filter
+func (db *MongoRepository) List(ctx context.Context, results interface{}, filter interface{}, sort Sort, offset int64, limit int64) error {
+	collectionName, err := getCollectionName(results)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	opts := options.Find()
 	opts.SetSkip(offset)
 	opts.SetLimit(limit)
-	opts.SetSort(buildBSON(sort))
+	opts.SetSort(buildSort(sort))
 
 	collection := db.Mongo.Collection(collectionName)
 
-	cursor, err := collection.Find(ctx, buildBSON(filter), opts)
+	cursor, err := collection.Find(ctx, filter, opts)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer cursor.Close(ctx)
 
-	var bsonResults []bson.M
-	if err = cursor.All(ctx, &bsonResults); err != nil {
-		return nil, err
+	return cursor.All(ctx, results)
+}
+
+// Count is used to count the documents in a collection that match a filter
+func (db *MongoRepository) Count(ctx context.Context, model interface{}, filter interface{}) (int64, error) {
+	collectionName, err := getCollectionName(model)
+	if err != nil {
+		return 0, err
 	}
 
-	return json.Marshal(bsonResults)
+	return db.Mongo.Collection(collectionName).CountDocuments(ctx, filter)
 }
 
-// Update is used to update a document in specified collection
-func (db *Repository) Update(ctx context.Context, model interface{}, filter interface{}) error {
+// Update is used to replace a document in specified collection
+func (db *MongoRepository) Update(ctx context.Context, model interface{}, filter interface{}) error {
 	collectionName, err := getCollectionName(model)
 	if err != nil {
 		return err
@@ -135,18 +147,27 @@ func (db *Repository) Update(ctx context.Context, model interface{}, filter inte
 
 	collection := db.Mongo.Collection(collectionName)
 
-	setDefaultFields(model, false)
-
-	_, err = collection.UpdateOne(ctx, filter, model)
+	model, err = setDefaultFields(model, false)
 	if err != nil {
 		return err
 	}
 
+	res, err := collection.ReplaceOne(ctx, filter, model)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if res.MatchedCount == 0 {
+		return translateError(mongo.ErrNoDocuments)
+	}
+
 	return nil
 }
 
-// Delete is used to delete a document in specified collection
-func (db *Repository) Delete(ctx context.Context, model interface{}, filter interface{}) error {
+// UpdateFields is used to atomically set fields on the first document matching a filter. The model
+// is populated with the document as it is after the update, which lets callers use the filter as a
+// guard that is checked and applied in a single operation.
+func (db *MongoRepository) UpdateFields(ctx context.Context, model interface{}, filter interface{}, fields bson.D) error {
 	collectionName, err := getCollectionName(model)
 	if err != nil {
 		return err
@@ -154,79 +175,92 @@ func (db *Repository) Delete(ctx context.Context, model interface{}, filter inte
 
 	collection := db.Mongo.Collection(collectionName)
 
-	_, err = collection.DeleteOne(ctx, filter)
-	if err != nil {
-		return err
-	}
+	set := append(bson.D{}, fields...)
+	set = append(set, bson.E{Key: "updated_at", Value: time.Now().UTC()})
 
-	return nil
-}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 
-// IsNotFoundError verifies the type of error returning from a find query
-func (db *Repository) IsNotFoundError(err error) bool {
-	return errors.Is(err, mongo.ErrNoDocuments)
-}
-
-func buildBSON(data map[string]string) bson.D {
-	doc := bson.D{}
-
-	for key, value := range data {
-		num, err := strconv.Atoi(value)
-		if err == nil {
-			doc = append(doc, bson.E{Key: key, Value: num})
-		} else {
-			doc = append(doc, bson.E{Key: key, Value: value})
-		}
-	}
+	err = collection.FindOneAndUpdate(ctx, filter, bson.D{{Key: "$set", Value: set}}, opts).Decode(model)
 
-	return doc
+	return translateError(err)
 }
 
-func getCollectionName(model interface{}) (string, error) {
-
-	if reflect.TypeOf(model).Kind() != reflect.Ptr {
-		return "", fmt.Errorf("model not a pointer")
+// Delete is used to delete a document in specified collection
+func (db *MongoRepository) Delete(ctx context.Context, model interface{}, filter interface{}) error {
+	collectionName, err := getCollectionName(model)
+	if err != nil {
+		return err
 	}
 
-	elemType := reflect.TypeOf(model).Elem()
+	collection := db.Mongo.Collection(collectionName)
 
-	if elemType.Kind() != reflect.Struct {
-		return "", fmt.Errorf("model not a struct")
+	res, err := collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
 	}
 
-	modelName := reflect.TypeOf(model).Elem().Name()
-	modelName = strings.ToLower(modelName)
-
-	collectionName := pluralizer.ToPlural(modelName)
+	if res.DeletedCount == 0 {
+		return translateError(mongo.ErrNoDocuments)
+	}
 
-	return collectionName, nil
+	return nil
 }
 
-func setDefaultFields(model interface{}, setCreate bool) (interface{}, error) {
-
-	now := time.Now().UTC()
+// EnsureUniqueIndex is used to create a unique index on a field, which is left as it is when it
+// already exists. The index is partial, covering only documents where the field is a non-empty
+// string.
+func (db *MongoRepository) EnsureUniqueIndex(ctx context.Context, model interface{}, field string) error {
+	collectionName, err := getCollectionName(model)
+	if err != nil {
+		return err
+	}
 
-	value := reflect.ValueOf(model)
-	if value.Kind() == reflect.Ptr {
-		value = value.Elem()
+	index := mongo.IndexModel{
+		Keys: bson.D{{Key: field, Value: 1}},
+		Options: options.Index().
+			SetName(field + "_unique").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.D{{Key: field, Value: bson.D{
+				{Key: "$type", Value: "string"},
+				{Key: "$gt", Value: ""},
+			}}}),
 	}
 
-	if value.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("model must be a struct or pointer to struct")
+	if _, err = db.Mongo.Collection(collectionName).Indexes().CreateOne(ctx, index); err != nil {
+		return fmt.Errorf("issue creating unique index on %s.%s: %w", collectionName, field, translateError(err))
 	}
 
-	if setCreate {
+	return nil
+}
+
+// IsNotFoundError verifies the type of error returning from a find query
+func (db *MongoRepository) IsNotFoundError(err error) bool {
+	return errors.Is(err, mongo.ErrNoDocuments) || errors.Is(err, ErrNotFound)
+}
 
-		createdAtField := value.FieldByName("CreatedAt")
-		if createdAtField.IsValid() && createdAtField.CanSet() {
-			createdAtField.Set(reflect.ValueOf(now))
-		}
-	}
+// Ping is used to check the MongoDB primary can still be reached
+func (db *MongoRepository) Ping(ctx context.Context) error {
+	return db.Mongo.Client().Ping(ctx, readpref.Primary())
+}
+
+// Close is used to disconnect the MongoDB client, waiting for in progress operations to finish
+func (db *MongoRepository) Close(ctx context.Context) error {
+	return db.Mongo.Client().Disconnect(ctx)
+}
 
-	updatedAtField := value.FieldByName("UpdatedAt")
-	if updatedAtField.IsValid() && updatedAtField.CanSet() {
-		updatedAtField.Set(reflect.ValueOf(now))
+// translateError wraps the MongoDB errors handlers need to tell apart in the matching repository
+// errors, keeping the original in the chain
+func translateError(err error) error {
+	var serverError mongo.ServerError
+
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case mongo.IsDuplicateKeyError(err):
+		return fmt.Errorf("%w: %w", ErrDuplicateKey, err)
+	case errors.As(err, &serverError) && serverError.HasErrorCode(immutableFieldCode):
+		return fmt.Errorf("%w: %w", ErrInvalidDocument, err)
 	}
 
-	return model, nil
+	return err
 }
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TestMongoRepository runs the behavioural suite against a real MongoDB. It is skipped unless
+// TEST_MONGO_URI points at a server, and each test uses its own database that is dropped afterwards.
+func TestMongoRepository(t *testing.T) {
+	uri := os.Getenv("TEST_MONGO_URI")
+	if uri == "" {
+		t.Skip("TEST_MONGO_URI not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("Could not connect to MongoDB: %v", err)
+	}
+
+	t.Cleanup(func() {
+		client.Disconnect(context.Background())
+	})
+
+	testRepository(t, func(t *testing.T) Repository {
+		database := client.Database("home_intranet_test_" + primitive.NewObjectID().Hex())
+
+		t.Cleanup(func() {
+			database.Drop(context.Background())
+		})
+
+		return &MongoRepository{
+			Mongo: database,
+		}
+	})
+}
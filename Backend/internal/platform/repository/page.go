@@ -0,0 +1,224 @@
+// Package repository servers as the wrapper for our data persistance packages
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ErrInvalidCursor is returned when a page cursor can't be decoded or belongs to a different sort
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Page is one page of a list along with the total number of matching documents and the cursors of
+// the pages either side of it. A cursor is empty when there is no page in that direction.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// pageCursor is the position of a page boundary. It holds the sort values of the document at the
+// boundary, ending with its _id so that documents with equal sort values are never skipped.
+type pageCursor struct {
+	Fields   []string `bson:"f"`
+	Values   bson.A   `bson:"v"`
+	Backward bool     `bson:"b"`
+}
+
+// ListPage is used to list one page of the documents of type T that match a filter. An empty cursor
+// starts at the first page, otherwise the page continues from the cursor of a previous page. Rather
+// than skipping over earlier documents, the cursor is turned into a filter on the sort values, so
+// every page costs the same however far into the list it is.
+func ListPage[T any](ctx context.Context, repo Repository, filter interface{}, sort Sort, cursor string, limit int64) (Page[T], error) {
+	if limit < 1 {
+		return Page[T]{}, fmt.Errorf("limit must be at least 1")
+	}
+
+	order := cursorSort(sort)
+
+	position, err := decodeCursor(cursor, order)
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	total, err := repo.Count(ctx, new(T), filter)
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	query := filter
+	querySort := order
+
+	if position != nil {
+		boundary := afterFilter(order, position.Values, position.Backward)
+		if filter == nil {
+			query = boundary
+		} else {
+			query = bson.D{{Key: "$and", Value: bson.A{filter, boundary.D()}}}
+		}
+
+		if position.Backward {
+			querySort = reverseSort(order)
+		}
+	}
+
+	// One extra document tells us whether there is another page beyond this one
+	items, err := List[T](ctx, repo, query, querySort, 0, limit+1)
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	more := int64(len(items)) > limit
+	if more {
+		items = items[:limit]
+	}
+
+	backward := position != nil && position.Backward
+	if backward {
+		slices.Reverse(items)
+	}
+
+	page := Page[T]{
+		Items: items,
+		Total: total,
+	}
+
+	if len(items) == 0 {
+		return page, nil
+	}
+
+	if more || backward {
+		if page.NextCursor, err = encodeCursor(items[len(items)-1], order, false); err != nil {
+			return Page[T]{}, err
+		}
+	}
+
+	if (position != nil && !backward) || (backward && more) {
+		if page.PrevCursor, err = encodeCursor(items[0], order, true); err != nil {
+			return Page[T]{}, err
+		}
+	}
+
+	return page, nil
+}
+
+// cursorSort adds _id to the end of a sort when it isn't already there, which makes the order of
+// the documents total
+func cursorSort(sort Sort) Sort {
+	for _, field := range sort {
+		if field.Field == "_id" {
+			return sort
+		}
+	}
+
+	return append(append(Sort{}, sort...), Asc("_id"))
+}
+
+// reverseSort flips the direction of every field in a sort
+func reverseSort(sort Sort) Sort {
+	reversed := make(Sort, 0, len(sort))
+	for _, field := range sort {
+		reversed = append(reversed, SortField{Field: field.Field, Descending: !field.Descending})
+	}
+
+	return reversed
+}
+
+// afterFilter matches the documents that come after the given sort values, or before them when
+// backward is set. Each field only decides the order when every earlier field is equal, so this is
+// an Or with one branch per field.
+func afterFilter(order Sort, values bson.A, backward bool) Filter {
+	branches := make([]Filter, 0, len(order))
+
+	for i, field := range order {
+		conditions := make([]Filter, 0, i+1)
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, Eq(order[j].Field, values[j]))
+		}
+
+		if field.Descending != backward {
+			conditions = append(conditions, Lt(field.Field, values[i]))
+		} else {
+			conditions = append(conditions, Gt(field.Field, values[i]))
+		}
+
+		branches = append(branches, And(conditions...))
+	}
+
+	return Or(branches...)
+}
+
+// encodeCursor builds the cursor for the position of item in a list with the given order
+func encodeCursor(item interface{}, order Sort, backward bool) (string, error) {
+	data, err := bson.Marshal(item)
+	if err != nil {
+		return "", err
+	}
+
+	var doc bson.M
+	if err = bson.Unmarshal(data, &doc); err != nil {
+		return "", err
+	}
+
+	position := pageCursor{
+		Fields:   sortFields(order),
+		Values:   make(bson.A, 0, len(order)),
+		Backward: backward,
+	}
+
+	for _, field := range order {
+		position.Values = append(position.Values, sortValue(doc, field.Field))
+	}
+
+	data, err = bson.Marshal(position)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor reads a cursor made by encodeCursor, checking it was made for the same order. An
+// empty cursor decodes to nil.
+func decodeCursor(cursor string, order Sort) (*pageCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var position pageCursor
+	if err = bson.Unmarshal(data, &position); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if !reflect.DeepEqual(position.Fields, sortFields(order)) || len(position.Values) != len(order) {
+		return nil, ErrInvalidCursor
+	}
+
+	return &position, nil
+}
+
+// sortFields describes a sort as a list of field names, with a minus in front of descending ones
+func sortFields(order Sort) []string {
+	fields := make([]string, 0, len(order))
+	for _, field := range order {
+		if field.Descending {
+			fields = append(fields, "-"+field.Field)
+		} else {
+			fields = append(fields, field.Field)
+		}
+	}
+
+	return fields
+}
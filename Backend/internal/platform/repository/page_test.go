@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestListPage(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	// Sizes repeat so pages have to fall back on the _id to split documents with equal sort values
+	for i, name := range []string{"A", "B", "C", "D", "E", "F", "G"} {
+		if _, err := Create(ctx, repo, widget{Name: name, Shelf: "12", Size: i / 2}); err != nil {
+			t.Fatalf("Create error = %v", err)
+		}
+	}
+
+	if _, err := Create(ctx, repo, widget{Name: "Elsewhere", Shelf: "4"}); err != nil {
+		t.Fatalf("Create error = %v", err)
+	}
+
+	filter := bson.D{{Key: "shelf", Value: "12"}}
+	sort := Sort{Desc("size")}
+
+	names := func(page Page[widget]) []string {
+		result := []string{}
+		for _, w := range page.Items {
+			result = append(result, w.Name)
+		}
+
+		return result
+	}
+
+	listPage := func(t *testing.T, cursor string) Page[widget] {
+		t.Helper()
+
+		page, err := ListPage[widget](ctx, repo, filter, sort, cursor, 3)
+		if err != nil {
+			t.Fatalf("ListPage error = %v", err)
+		}
+
+		if page.Total != 7 {
+			t.Errorf("ListPage total = %v, want: %v", page.Total, 7)
+		}
+
+		return page
+	}
+
+	first := listPage(t, "")
+	if got, want := names(first), []string{"G", "E", "F"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListPage first page = %v, want: %v", got, want)
+	}
+
+	if first.PrevCursor != "" || first.NextCursor == "" {
+		t.Fatalf("ListPage first page cursors = %q, %q", first.PrevCursor, first.NextCursor)
+	}
+
+	second := listPage(t, first.NextCursor)
+	if got, want := names(second), []string{"C", "D", "A"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListPage second page = %v, want: %v", got, want)
+	}
+
+	last := listPage(t, second.NextCursor)
+	if got, want := names(last), []string{"B"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListPage last page = %v, want: %v", got, want)
+	}
+
+	if last.NextCursor != "" || last.PrevCursor == "" {
+		t.Fatalf("ListPage last page cursors = %q, %q", last.PrevCursor, last.NextCursor)
+	}
+
+	back := listPage(t, last.PrevCursor)
+	if got, want := names(back), []string{"C", "D", "A"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListPage page before the last = %v, want: %v", got, want)
+	}
+
+	start := listPage(t, back.PrevCursor)
+	if got, want := names(start), []string{"G", "E", "F"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListPage page before that = %v, want: %v", got, want)
+	}
+
+	if start.PrevCursor != "" || start.NextCursor == "" {
+		t.Errorf("ListPage returning to the first page cursors = %q, %q", start.PrevCursor, start.NextCursor)
+	}
+}
+
+func TestListPage_errors(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	for _, name := range []string{"A", "B"} {
+		if _, err := Create(ctx, repo, widget{Name: name}); err != nil {
+			t.Fatalf("Create error = %v", err)
+		}
+	}
+
+	page, err := ListPage[widget](ctx, repo, bson.D{}, Sort{Asc("name")}, "", 1)
+	if err != nil {
+		t.Fatalf("ListPage error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		sort    Sort
+		cursor  string
+		limit   int64
+		wantErr error
+	}{
+		{
+			name:    "Cursor that isn't base64",
+			sort:    Sort{Asc("name")},
+			cursor:  "not a cursor!",
+			limit:   1,
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "Cursor from a different sort",
+			sort:    Sort{Desc("name")},
+			cursor:  page.NextCursor,
+			limit:   1,
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:  "Limit below one",
+			sort:  Sort{Asc("name")},
+			limit: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ListPage[widget](ctx, repo, bson.D{}, tt.sort, tt.cursor, tt.limit)
+
+			if err == nil {
+				t.Fatal("ListPage error = nil, want an error")
+			}
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("ListPage error = %v, want: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+// Package repository servers as the wrapper for our data persistance packages
+package repository
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FieldType is the type a filter value is parsed as before it is compared with a field
+type FieldType int
+
+const (
+	// StringField values are used as given
+	StringField FieldType = iota
+	// IntField values are parsed as integers
+	IntField
+	// BoolField values are parsed as true or false
+	BoolField
+	// TimeField values are parsed as RFC 3339 times, or as dates at midnight UTC
+	TimeField
+	// ObjectIDField values are parsed as hex document ids
+	ObjectIDField
+)
+
+// FilterFields lists the fields a list endpoint can be filtered on by their stored names, along with
+// their types. Declaring the type means a shelf named "12" stays a string while a size of 12 is a
+// number.
+type FilterFields map[string]FieldType
+
+// ParsePaging reads the cursor and limit query parameters of a list request. With no cursor the
+// first page is returned, and the limit defaults to 20.
+func ParsePaging(values url.Values) (string, int64, error) {
+	limitString := values.Get("limit")
+
+	// Get default limit value
+	if limitString == "" {
+		limitString = "20"
+	}
+
+	// Convert to int
+	limit, err := strconv.ParseInt(limitString, 10, 64)
+	if err != nil || limit < 1 {
+		return "", 0, fmt.Errorf("limit must be a positive number")
+	}
+
+	return values.Get("cursor"), limit, nil
+}
+
+// ParseFilter builds a Filter from the filter query parameters of a list request. Parameters other
+// than filter are ignored. The syntax is
+//
+//	filter[<field>]=<value>                      field equals value
+//	filter[<field>][<operator>]=<value>          field compared with value
+//	filter[or][<group>][<field>][<operator>]=... conditions in a group are combined with AND and the
+//	                                             groups with OR
+//
+// The operators are eq, ne, gt, gte, lt, lte, in (a comma separated list), contains and prefix
+// (string fields only, matching the value as plain text) and exists (true or false). Every condition
+// outside of an or group has to be met, so a date range is written as
+//
+//	?filter[checked_out_time][gte]=2026-01-01&filter[checked_out_time][lt]=2026-02-01
+func ParseFilter(values url.Values, fields FilterFields) (Filter, error) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if key == "filter" || strings.HasPrefix(key, "filter[") {
+			keys = append(keys, key)
+		}
+	}
+
+	// Sorting keeps the compiled filter the same for the same query
+	sort.Strings(keys)
+
+	var conditions []Filter
+	groups := map[string][]Filter{}
+	var groupNames []string
+
+	for _, key := range keys {
+		path, err := parseFilterKey(key)
+		if err != nil {
+			return Filter{}, err
+		}
+
+		group := ""
+		if path[0] == "or" {
+			if len(path) < 3 {
+				return Filter{}, fmt.Errorf("filter parameter %s needs a group and a field", key)
+			}
+
+			group = path[1]
+			path = path[2:]
+		}
+
+		if len(path) > 2 {
+			return Filter{}, fmt.Errorf("filter parameter %s has too many parts", key)
+		}
+
+		operator := "eq"
+		if len(path) == 2 {
+			operator = path[1]
+		}
+
+		for _, value := range values[key] {
+			filter, err := parseCondition(path[0], operator, value, fields)
+			if err != nil {
+				return Filter{}, err
+			}
+
+			if group == "" {
+				conditions = append(conditions, filter)
+				continue
+			}
+
+			if _, ok := groups[group]; !ok {
+				groupNames = append(groupNames, group)
+			}
+
+			groups[group] = append(groups[group], filter)
+		}
+	}
+
+	if len(groupNames) > 0 {
+		alternatives := make([]Filter, 0, len(groupNames))
+		for _, name := range groupNames {
+			alternatives = append(alternatives, And(groups[name]...))
+		}
+
+		conditions = append(conditions, Or(alternatives...))
+	}
+
+	return And(conditions...), nil
+}
+
+// parseFilterKey splits a parameter such as filter[due_date][lt] into its bracketed parts
+func parseFilterKey(key string) ([]string, error) {
+	rest := strings.TrimPrefix(key, "filter")
+
+	var path []string
+	for rest != "" {
+		if !strings.HasPrefix(rest, "[") {
+			return nil, fmt.Errorf("filter parameter %s is malformed", key)
+		}
+
+		part, remaining, found := strings.Cut(rest[1:], "]")
+		if !found || part == "" {
+			return nil, fmt.Errorf("filter parameter %s is malformed", key)
+		}
+
+		path = append(path, part)
+		rest = remaining
+	}
+
+	if len(path) == 0 {
+		return nil, fmt.Errorf("filter parameter %s needs a field", key)
+	}
+
+	return path, nil
+}
+
+// parseCondition builds the filter for one operator applied to a field
+func parseCondition(field string, operator string, value string, fields FilterFields) (Filter, error) {
+	fieldType, ok := fields[field]
+	if !ok {
+		return Filter{}, fmt.Errorf("filtering on %s is not supported", field)
+	}
+
+	switch operator {
+	case "eq", "ne", "gt", "gte", "lt", "lte":
+		if fieldType == BoolField && operator != "eq" && operator != "ne" {
+			return Filter{}, fmt.Errorf("filter operator %s can't be used on %s", operator, field)
+		}
+
+		parsed, err := parseFilterValue(field, fieldType, value)
+		if err != nil {
+			return Filter{}, err
+		}
+
+		return map[string]func(string, interface{}) Filter{
+			"eq":  Eq,
+			"ne":  Ne,
+			"gt":  Gt,
+			"gte": Gte,
+			"lt":  Lt,
+			"lte": Lte,
+		}[operator](field, parsed), nil
+	case "in":
+		var list []interface{}
+		for _, item := range strings.Split(value, ",") {
+			parsed, err := parseFilterValue(field, fieldType, strings.TrimSpace(item))
+			if err != nil {
+				return Filter{}, err
+			}
+
+			list = append(list, parsed)
+		}
+
+		return In(field, list...), nil
+	case "contains", "prefix":
+		if fieldType != StringField {
+			return Filter{}, fmt.Errorf("filter operator %s can only be used on text fields, not %s", operator, field)
+		}
+
+		// The value is escaped so a client can't send a regular expression that is slow to match
+		pattern := regexp.QuoteMeta(value)
+		if operator == "prefix" {
+			pattern = "^" + pattern
+		}
+
+		return Regex(field, pattern, ""), nil
+	case "exists":
+		exists, err := strconv.ParseBool(value)
+		if err != nil {
+			return Filter{}, fmt.Errorf("filter operator exists on %s must be true or false", field)
+		}
+
+		return Exists(field, exists), nil
+	}
+
+	return Filter{}, fmt.Errorf("unknown filter operator %s", operator)
+}
+
+// parseFilterValue converts a query string value to the type of the field it is compared with
+func parseFilterValue(field string, fieldType FieldType, value string) (interface{}, error) {
+	switch fieldType {
+	case IntField:
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("filter on %s must be a whole number", field)
+		}
+
+		return number, nil
+	case BoolField:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("filter on %s must be true or false", field)
+		}
+
+		return boolean, nil
+	case TimeField:
+		if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+			return parsed.UTC(), nil
+		}
+
+		parsed, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return nil, fmt.Errorf("filter on %s must be a date such as 2026-01-01 or an RFC 3339 time", field)
+		}
+
+		return parsed, nil
+	case ObjectIDField:
+		id, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			return nil, fmt.Errorf("filter on %s must be a valid id", field)
+		}
+
+		return id, nil
+	}
+
+	return value, nil
+}
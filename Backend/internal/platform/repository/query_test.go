@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParsePaging(t *testing.T) {
+	tests := []struct {
+		name       string
+		values     url.Values
+		wantCursor string
+		wantLimit  int64
+		wantErr    bool
+	}{
+		{
+			name:      "Defaults",
+			values:    url.Values{},
+			wantLimit: 20,
+		},
+		{
+			name:       "Cursor and limit",
+			values:     url.Values{"cursor": {"abc"}, "limit": {"5"}},
+			wantCursor: "abc",
+			wantLimit:  5,
+		},
+		{
+			name:    "Limit isn't a number",
+			values:  url.Values{"limit": {"ten"}},
+			wantErr: true,
+		},
+		{
+			name:    "Limit isn't positive",
+			values:  url.Values{"limit": {"0"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, limit, err := ParsePaging(tt.values)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePaging error = %v, wantErr: %t", err, tt.wantErr)
+			}
+
+			if cursor != tt.wantCursor || limit != tt.wantLimit {
+				t.Errorf("ParsePaging got = %q, %v, want: %q, %v", cursor, limit, tt.wantCursor, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	fields := FilterFields{
+		"name":    StringField,
+		"shelf":   StringField,
+		"size":    IntField,
+		"active":  BoolField,
+		"made_at": TimeField,
+		"_id":     ObjectIDField,
+	}
+
+	id := primitive.NewObjectID()
+
+	tests := []struct {
+		name    string
+		query   string
+		want1   Filter
+		wantErr bool
+	}{
+		{
+			name:  "No filter parameters",
+			query: "limit=10&sort-col=name",
+			want1: Filter{},
+		},
+		{
+			name:  "Numeric looking text stays text",
+			query: "filter[shelf]=12",
+			want1: Eq("shelf", "12"),
+		},
+		{
+			name:  "Typed values",
+			query: "filter[size][gt]=3&filter[active]=true&filter[_id][ne]=" + id.Hex(),
+			want1: And(Ne("_id", id), Eq("active", true), Gt("size", int64(3))),
+		},
+		{
+			name:  "Date range",
+			query: "filter[made_at][gte]=2026-01-01&filter[made_at][lt]=2026-02-01T12:00:00Z",
+			want1: And(
+				Gte("made_at", time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)),
+				Lt("made_at", time.Date(2026, time.February, 1, 12, 0, 0, 0, time.UTC)),
+			),
+		},
+		{
+			name:  "In, prefix and exists",
+			query: "filter[shelf][in]=4, 12&filter[name][prefix]=Sp&filter[made_at][exists]=false",
+			want1: And(Exists("made_at", false), Regex("name", "^Sp", ""), In("shelf", "4", "12")),
+		},
+		{
+			name:  "Contains and prefix are plain text",
+			query: "filter[name][contains]=(a%2B)%2B$&filter[shelf][prefix]=.*",
+			want1: And(Regex("name", `\(a\+\)\+\$`, ""), Regex("shelf", `^\.\*`, "")),
+		},
+		{
+			name:  "Or groups",
+			query: "filter[shelf]=12&filter[or][a][size][lt]=2&filter[or][b][name]=Gizmo&filter[or][b][active]=false",
+			want1: And(
+				Eq("shelf", "12"),
+				Or(Lt("size", int64(2)), And(Eq("active", false), Eq("name", "Gizmo"))),
+			),
+		},
+		{
+			name:    "Unknown field",
+			query:   "filter[colour]=red",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown operator",
+			query:   "filter[size][near]=3",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid number",
+			query:   "filter[size]=three",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid date",
+			query:   "filter[made_at][gte]=yesterday",
+			wantErr: true,
+		},
+		{
+			name:    "Contains on a number",
+			query:   "filter[size][contains]=1",
+			wantErr: true,
+		},
+		{
+			name:    "Regular expressions aren't accepted",
+			query:   "filter[name][regex]=^(a+)+$",
+			wantErr: true,
+		},
+		{
+			name:    "Comparison on a boolean",
+			query:   "filter[active][gt]=false",
+			wantErr: true,
+		},
+		{
+			name:    "Malformed parameter",
+			query:   "filter[name=Gizmo",
+			wantErr: true,
+		},
+		{
+			name:    "Or without a group",
+			query:   "filter[or][name]=Gizmo",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("Failed to parse query: %v", err)
+			}
+
+			got1, err := ParseFilter(values, fields)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFilter error = %v, wantErr: %t", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got1.D(), tt.want1.D()) {
+				t.Errorf("ParseFilter got1 = %v, want1: %v", got1.D(), tt.want1.D())
+			}
+		})
+	}
+}
@@ -1,9 +1,191 @@
 // Package repository servers as the wrapper for our data persistance packages
 package repository
 
-import "go.mongodb.org/mongo-driver/mongo"
+import (
+	"Home-Intranet-v2-Backend/internal/platform/config"
+	"Home-Intranet-v2-Backend/internal/platform/pluralizer"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
 
-// Repository is the collection of data peristance wrappers
-type Repository struct {
-	Mongo *mongo.Database
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Open is used to connect to the storage backend selected by the driver configuration
+func Open(cfg config.DB) (Repository, error) {
+	switch cfg.Driver {
+	case "mongo":
+		mongo, err := Connect(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		return &MongoRepository{
+			Mongo: mongo,
+		}, nil
+	case "sqlite":
+		db, err := OpenSQLite(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		return &SQLiteRepository{
+			DB: db,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", cfg.Driver)
+	}
+}
+
+var (
+	// ErrNotFound is returned when no document matches a filter
+	ErrNotFound = errors.New("document not found")
+
+	// ErrDuplicateKey is returned when a document would have the same key as one already stored
+	ErrDuplicateKey = errors.New("duplicate key")
+
+	// ErrInvalidDocument is returned when a change to a document is refused, such as changing its _id
+	ErrInvalidDocument = errors.New("invalid document")
+)
+
+// Repository is the collection of data peristance wrappers. Every storage backend implements it, so
+// handlers can be given a real database or an in memory one for testing.
+type Repository interface {
+	// Create is used to insert a new document into a collection
+	Create(ctx context.Context, model interface{}) error
+
+	// Read is used to find one document based on a filter
+	Read(ctx context.Context, model interface{}, filter interface{}) error
+
+	// List is used to decode all documents in a collection that match a filter into results, which
+	// must be a pointer to a slice of models
+	List(ctx context.Context, results interface{}, filter interface{}, sort Sort, offset int64, limit int64) error
+
+	// Count is used to count the documents in a collection that match a filter
+	Count(ctx context.Context, model interface{}, filter interface{}) (int64, error)
+
+	// Update is used to replace a document in specified collection
+	Update(ctx context.Context, model interface{}, filter interface{}) error
+
+	// UpdateFields is used to atomically set fields on the first document matching a filter
+	UpdateFields(ctx context.Context, model interface{}, filter interface{}, fields bson.D) error
+
+	// Delete is used to delete a document in specified collection
+	Delete(ctx context.Context, model interface{}, filter interface{}) error
+
+	// EnsureUniqueIndex is used to make sure no two documents in the collection of model have the
+	// same value of a text field. Documents where the field is empty are left out, so any number of
+	// them can leave it empty. Writes that would repeat a value fail with ErrDuplicateKey.
+	EnsureUniqueIndex(ctx context.Context, model interface{}, field string) error
+
+	// IsNotFoundError verifies the type of error returning from a find query
+	IsNotFoundError(err error) bool
+
+	// Ping is used to check the storage backend can still be reached
+	Ping(ctx context.Context) error
+
+	// Close is used to release the connection to the storage backend once it is no longer needed
+	Close(ctx context.Context) error
+}
+
+// SortField is a field a list is ordered by
+type SortField struct {
+	Field      string
+	Descending bool
+}
+
+// Sort is the order of a list. Documents are ordered by each field in turn, so later fields only
+// decide between documents that are equal on the earlier ones.
+type Sort []SortField
+
+// Asc orders a list by a field, smallest first
+func Asc(field string) SortField {
+	return SortField{Field: field}
+}
+
+// Desc orders a list by a field, largest first
+func Desc(field string) SortField {
+	return SortField{Field: field, Descending: true}
+}
+
+// buildSort converts a sort to a sort document, keeping the order of the fields
+func buildSort(sort Sort) bson.D {
+	doc := bson.D{}
+
+	for _, field := range sort {
+		direction := 1
+		if field.Descending {
+			direction = -1
+		}
+
+		doc = append(doc, bson.E{Key: field.Field, Value: direction})
+	}
+
+	return doc
+}
+
+// CollectionName returns the collection a model, or a slice of models, is stored in. The model
+// has to be a pointer.
+func CollectionName(model interface{}) (string, error) {
+	return getCollectionName(model)
+}
+
+func getCollectionName(model interface{}) (string, error) {
+
+	if reflect.TypeOf(model).Kind() != reflect.Ptr {
+		return "", fmt.Errorf("model not a pointer")
+	}
+
+	elemType := reflect.TypeOf(model).Elem()
+
+	// A slice of models, or of pointers to models, is stored in the collection of its element type
+	if elemType.Kind() == reflect.Slice {
+		elemType = elemType.Elem()
+		if elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+	}
+
+	if elemType.Kind() != reflect.Struct {
+		return "", fmt.Errorf("model not a struct")
+	}
+
+	modelName := elemType.Name()
+	modelName = strings.ToLower(modelName)
+
+	collectionName := pluralizer.ToPlural(modelName)
+
+	return collectionName, nil
+}
+
+func setDefaultFields(model interface{}, setCreate bool) (interface{}, error) {
+
+	now := time.Now().UTC()
+
+	value := reflect.ValueOf(model)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("model must be a struct or pointer to struct")
+	}
+
+	if setCreate {
+
+		createdAtField := value.FieldByName("CreatedAt")
+		if createdAtField.IsValid() && createdAtField.CanSet() {
+			createdAtField.Set(reflect.ValueOf(now))
+		}
+	}
+
+	updatedAtField := value.FieldByName("UpdatedAt")
+	if updatedAtField.IsValid() && updatedAtField.CanSet() {
+		updatedAtField.Set(reflect.ValueOf(now))
+	}
+
+	return model, nil
 }
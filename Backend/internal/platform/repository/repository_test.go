@@ -0,0 +1,533 @@
+package repository
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/config"
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// widget is the model the behavioural suite stores, covering the field types the library uses
+type widget struct {
+	Model  `bson:",inline" json:",inline"`
+	Name   string    `bson:"name" json:"name"`
+	Shelf  string    `bson:"shelf" json:"shelf"`
+	Size   int       `bson:"size" json:"size"`
+	Active bool      `bson:"active" json:"active"`
+	MadeAt time.Time `bson:"made_at" json:"made_at"`
+	Parts  []part    `bson:"parts" json:"parts"`
+}
+
+type part struct {
+	ID   primitive.ObjectID `bson:"_id" json:"_id"`
+	Name string             `bson:"name" json:"name"`
+}
+
+// testRepository is the behavioural suite every Repository implementation has to pass
+func testRepository(t *testing.T, newRepository func(t *testing.T) Repository) {
+	ctx := context.Background()
+	madeAt := time.Date(2026, time.January, 10, 0, 0, 0, 0, time.UTC)
+	sharedPart := primitive.NewObjectID()
+
+	seed := func(t *testing.T, repo Repository) []widget {
+		widgets := []widget{
+			{Name: "Sprocket", Shelf: "12", Size: 3, Active: true, MadeAt: madeAt, Parts: []part{{ID: sharedPart, Name: "Gear"}}},
+			{Name: "Gizmo", Shelf: "4", Size: 10, Active: false, MadeAt: madeAt.AddDate(0, 1, 0), Parts: []part{}},
+			{Name: "sprocket deluxe", Shelf: "12", Size: 7, Active: true, MadeAt: madeAt.AddDate(0, 2, 0), Parts: []part{{ID: sharedPart, Name: "Gear"}, {ID: primitive.NewObjectID(), Name: "Spring"}}},
+		}
+
+		for i := range widgets {
+			if err := repo.Create(ctx, &widgets[i]); err != nil {
+				t.Fatalf("Create error = %v", err)
+			}
+		}
+
+		return widgets
+	}
+
+	list := func(t *testing.T, repo Repository, filter interface{}, sort Sort, offset int64, limit int64) []string {
+		var widgets []widget
+		if err := repo.List(ctx, &widgets, filter, sort, offset, limit); err != nil {
+			t.Fatalf("List error = %v", err)
+		}
+
+		names := []string{}
+		for _, w := range widgets {
+			names = append(names, w.Name)
+		}
+
+		return names
+	}
+
+	t.Run("Create sets the id and timestamps", func(t *testing.T) {
+		repo := newRepository(t)
+		item := widget{Name: "Sprocket"}
+
+		if err := repo.Create(ctx, &item); err != nil {
+			t.Fatalf("Create error = %v", err)
+		}
+
+		if item.ID.IsZero() {
+			t.Error("Create did not set the id")
+		}
+
+		if item.CreatedAt.IsZero() || item.UpdatedAt.IsZero() {
+			t.Error("Create did not set the timestamps")
+		}
+	})
+
+	t.Run("Read finds a document by filter", func(t *testing.T) {
+		repo := newRepository(t)
+		widgets := seed(t, repo)
+
+		var got widget
+		if err := repo.Read(ctx, &got, bson.D{{Key: "_id", Value: widgets[1].ID}}); err != nil {
+			t.Fatalf("Read error = %v", err)
+		}
+
+		if got.Name != "Gizmo" || got.Size != 10 || !got.MadeAt.Equal(widgets[1].MadeAt) {
+			t.Errorf("Read got = %+v, want: %+v", got, widgets[1])
+		}
+
+		err := repo.Read(ctx, &got, bson.D{{Key: "name", Value: "Missing"}})
+		if !repo.IsNotFoundError(err) {
+			t.Errorf("Read missing error = %v, want not found", err)
+		}
+	})
+
+	t.Run("List filters documents", func(t *testing.T) {
+		repo := newRepository(t)
+		seed(t, repo)
+
+		tests := []struct {
+			name   string
+			filter interface{}
+			want   []string
+		}{
+			{
+				name:   "Empty filter",
+				filter: bson.D{},
+				want:   []string{"Gizmo", "Sprocket", "sprocket deluxe"},
+			},
+			{
+				name:   "String equality",
+				filter: bson.D{{Key: "shelf", Value: "12"}},
+				want:   []string{"Sprocket", "sprocket deluxe"},
+			},
+			{
+				name:   "Boolean equality",
+				filter: bson.D{{Key: "active", Value: false}},
+				want:   []string{"Gizmo"},
+			},
+			{
+				name:   "Case insensitive regex",
+				filter: bson.D{{Key: "name", Value: bson.D{{Key: "$regex", Value: "SPROCKET"}, {Key: "$options", Value: "i"}}}},
+				want:   []string{"Sprocket", "sprocket deluxe"},
+			},
+			{
+				name: "Or of conditions",
+				filter: bson.D{{Key: "$or", Value: bson.A{
+					bson.D{{Key: "size", Value: 10}},
+					bson.D{{Key: "size", Value: 3}},
+				}}},
+				want: []string{"Gizmo", "Sprocket"},
+			},
+			{
+				name:   "Embedded array field",
+				filter: bson.D{{Key: "parts._id", Value: sharedPart}},
+				want:   []string{"Sprocket", "sprocket deluxe"},
+			},
+			{
+				name:   "Date range",
+				filter: bson.D{{Key: "made_at", Value: bson.D{{Key: "$gt", Value: madeAt}, {Key: "$lte", Value: madeAt.AddDate(0, 1, 0)}}}},
+				want:   []string{"Gizmo"},
+			},
+			{
+				name:   "In list",
+				filter: bson.D{{Key: "size", Value: bson.D{{Key: "$in", Value: bson.A{7, 10}}}}},
+				want:   []string{"Gizmo", "sprocket deluxe"},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got := list(t, repo, tt.filter, Sort{Asc("name")}, 0, 0)
+
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("List got = %v, want: %v", got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("List sorts and pages documents", func(t *testing.T) {
+		repo := newRepository(t)
+		seed(t, repo)
+
+		if got, want := list(t, repo, bson.D{}, Sort{Desc("size")}, 0, 0), []string{"Gizmo", "sprocket deluxe", "Sprocket"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("List descending got = %v, want: %v", got, want)
+		}
+
+		if got, want := list(t, repo, bson.D{}, Sort{Asc("size")}, 1, 1), []string{"sprocket deluxe"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("List page got = %v, want: %v", got, want)
+		}
+
+		if got, want := list(t, repo, bson.D{}, Sort{Asc("size")}, 5, 1), []string{}; !reflect.DeepEqual(got, want) {
+			t.Errorf("List past the end got = %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Count counts matching documents", func(t *testing.T) {
+		repo := newRepository(t)
+		seed(t, repo)
+
+		tests := []struct {
+			name   string
+			filter interface{}
+			want   int64
+		}{
+			{
+				name:   "Empty filter",
+				filter: bson.D{},
+				want:   3,
+			},
+			{
+				name:   "Matching filter",
+				filter: Gte("size", 7),
+				want:   2,
+			},
+			{
+				name:   "No matches",
+				filter: bson.D{{Key: "name", Value: "Missing"}},
+				want:   0,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := repo.Count(ctx, &widget{}, tt.filter)
+				if err != nil {
+					t.Fatalf("Count error = %v", err)
+				}
+
+				if got != tt.want {
+					t.Errorf("Count got = %v, want: %v", got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("Update replaces a document", func(t *testing.T) {
+		repo := newRepository(t)
+		widgets := seed(t, repo)
+
+		updated := widgets[0]
+		updated.Name = "Sprocket Mk II"
+		updated.Parts = nil
+
+		if err := repo.Update(ctx, &updated, bson.D{{Key: "_id", Value: updated.ID}}); err != nil {
+			t.Fatalf("Update error = %v", err)
+		}
+
+		var got widget
+		if err := repo.Read(ctx, &got, bson.D{{Key: "_id", Value: updated.ID}}); err != nil {
+			t.Fatalf("Read error = %v", err)
+		}
+
+		if got.Name != "Sprocket Mk II" || len(got.Parts) != 0 {
+			t.Errorf("Update got = %+v", got)
+		}
+
+		if !got.CreatedAt.Equal(widgets[0].CreatedAt.Truncate(time.Millisecond)) {
+			t.Errorf("Update changed created_at to %v", got.CreatedAt)
+		}
+
+		if got.UpdatedAt.Before(widgets[0].UpdatedAt.Truncate(time.Millisecond)) {
+			t.Errorf("Update did not move updated_at forward: %v", got.UpdatedAt)
+		}
+
+		missing := widget{Name: "Missing"}
+		err := repo.Update(ctx, &missing, bson.D{{Key: "_id", Value: primitive.NewObjectID()}})
+		if !repo.IsNotFoundError(err) {
+			t.Errorf("Update missing error = %v, want not found", err)
+		}
+	})
+
+	t.Run("UpdateFields applies a guarded update once", func(t *testing.T) {
+		repo := newRepository(t)
+		widgets := seed(t, repo)
+
+		guard := bson.D{{Key: "_id", Value: widgets[1].ID}, {Key: "active", Value: false}}
+
+		var got widget
+		if err := repo.UpdateFields(ctx, &got, guard, bson.D{{Key: "active", Value: true}, {Key: "size", Value: 11}}); err != nil {
+			t.Fatalf("UpdateFields error = %v", err)
+		}
+
+		if !got.Active || got.Size != 11 || got.Name != "Gizmo" {
+			t.Errorf("UpdateFields got = %+v", got)
+		}
+
+		err := repo.UpdateFields(ctx, &got, guard, bson.D{{Key: "active", Value: true}})
+		if !repo.IsNotFoundError(err) {
+			t.Errorf("UpdateFields second update error = %v, want not found", err)
+		}
+	})
+
+	t.Run("UpdateFields lets only one concurrent update through", func(t *testing.T) {
+		repo := newRepository(t)
+		widgets := seed(t, repo)
+
+		guard := bson.D{{Key: "_id", Value: widgets[1].ID}, {Key: "active", Value: false}}
+
+		var wg sync.WaitGroup
+		results := make(chan error, 10)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var got widget
+				results <- repo.UpdateFields(ctx, &got, guard, bson.D{{Key: "active", Value: true}})
+			}()
+		}
+		wg.Wait()
+		close(results)
+
+		succeeded := 0
+		for err := range results {
+			if err == nil {
+				succeeded++
+			} else if !repo.IsNotFoundError(err) {
+				t.Errorf("UpdateFields unexpected error = %v", err)
+			}
+		}
+
+		if succeeded != 1 {
+			t.Errorf("UpdateFields succeeded %d times, want: 1", succeeded)
+		}
+	})
+
+	t.Run("EnsureUniqueIndex refuses repeated values", func(t *testing.T) {
+		repo := newRepository(t)
+		widgets := seed(t, repo)
+
+		if err := repo.EnsureUniqueIndex(ctx, &widget{}, "shelf"); !errors.Is(err, ErrDuplicateKey) {
+			t.Errorf("EnsureUniqueIndex on repeated values error = %v, want: %v", err, ErrDuplicateKey)
+		}
+
+		if err := repo.EnsureUniqueIndex(ctx, &widget{}, "name"); err != nil {
+			t.Fatalf("EnsureUniqueIndex error = %v", err)
+		}
+
+		if err := repo.EnsureUniqueIndex(ctx, &widget{}, "name"); err != nil {
+			t.Errorf("EnsureUniqueIndex again error = %v", err)
+		}
+
+		if err := repo.Create(ctx, &widget{Name: "Gizmo"}); !errors.Is(err, ErrDuplicateKey) {
+			t.Errorf("Create repeated name error = %v, want: %v", err, ErrDuplicateKey)
+		}
+
+		for i := 0; i < 2; i++ {
+			if err := repo.Create(ctx, &widget{Shelf: "1"}); err != nil {
+				t.Errorf("Create empty name error = %v", err)
+			}
+		}
+
+		updated := widgets[0]
+		updated.Size = 4
+		if err := repo.Update(ctx, &updated, bson.D{{Key: "_id", Value: updated.ID}}); err != nil {
+			t.Errorf("Update keeping the name error = %v", err)
+		}
+
+		updated.Name = "Gizmo"
+		if err := repo.Update(ctx, &updated, bson.D{{Key: "_id", Value: updated.ID}}); !errors.Is(err, ErrDuplicateKey) {
+			t.Errorf("Update to a repeated name error = %v, want: %v", err, ErrDuplicateKey)
+		}
+
+		var got widget
+		err := repo.UpdateFields(ctx, &got, bson.D{{Key: "_id", Value: widgets[2].ID}}, bson.D{{Key: "name", Value: "Gizmo"}})
+		if !errors.Is(err, ErrDuplicateKey) {
+			t.Errorf("UpdateFields to a repeated name error = %v, want: %v", err, ErrDuplicateKey)
+		}
+
+		if got, want := list(t, repo, bson.D{{Key: "name", Value: "Gizmo"}}, Sort{}, 0, 0), []string{"Gizmo"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("List repeated name got = %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Errors wrap the repository errors", func(t *testing.T) {
+		repo := newRepository(t)
+		widgets := seed(t, repo)
+
+		var got widget
+		err := repo.Read(ctx, &got, bson.D{{Key: "name", Value: "Missing"}})
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Read missing error = %v, want: %v", err, ErrNotFound)
+		}
+
+		duplicate := widget{Model: Model{ID: widgets[0].ID}, Name: "Copy"}
+		err = repo.Create(ctx, &duplicate)
+		if !errors.Is(err, ErrDuplicateKey) {
+			t.Errorf("Create duplicate error = %v, want: %v", err, ErrDuplicateKey)
+		}
+
+		err = repo.UpdateFields(ctx, &got, bson.D{{Key: "_id", Value: widgets[0].ID}}, bson.D{{Key: "_id", Value: primitive.NewObjectID()}})
+		if !errors.Is(err, ErrInvalidDocument) {
+			t.Errorf("UpdateFields _id error = %v, want: %v", err, ErrInvalidDocument)
+		}
+	})
+
+	t.Run("Delete removes a document", func(t *testing.T) {
+		repo := newRepository(t)
+		widgets := seed(t, repo)
+
+		filter := bson.D{{Key: "_id", Value: widgets[2].ID}}
+
+		if err := repo.Delete(ctx, &widget{}, filter); err != nil {
+			t.Fatalf("Delete error = %v", err)
+		}
+
+		var got widget
+		if err := repo.Read(ctx, &got, filter); !repo.IsNotFoundError(err) {
+			t.Errorf("Read deleted error = %v, want not found", err)
+		}
+
+		if err := repo.Delete(ctx, &widget{}, filter); !repo.IsNotFoundError(err) {
+			t.Errorf("Delete missing error = %v, want not found", err)
+		}
+
+		if got, want := list(t, repo, bson.D{}, Sort{Asc("name")}, 0, 0), []string{"Gizmo", "Sprocket"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("List after delete got = %v, want: %v", got, want)
+		}
+	})
+}
+
+func Test_buildSort(t *testing.T) {
+	tests := []struct {
+		name  string
+		sort  Sort
+		want1 bson.D
+	}{
+		{
+			name:  "No fields",
+			sort:  Sort{},
+			want1: bson.D{},
+		},
+		{
+			name:  "Field order is kept",
+			sort:  Sort{Asc("shelf"), Desc("title"), Asc("_id")},
+			want1: bson.D{{Key: "shelf", Value: 1}, {Key: "title", Value: -1}, {Key: "_id", Value: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1 := buildSort(tt.sort)
+
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("buildSort got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func Test_getCollectionName(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   interface{}
+		want1   string
+		wantErr bool
+	}{
+		{
+			name:  "Pointer to struct",
+			model: &widget{},
+			want1: "widgets",
+		},
+		{
+			name:  "Pointer to slice",
+			model: &[]widget{},
+			want1: "widgets",
+		},
+		{
+			name:  "Pointer to slice of pointers",
+			model: &[]*widget{},
+			want1: "widgets",
+		},
+		{
+			name:    "Struct value",
+			model:   widget{},
+			wantErr: true,
+		},
+		{
+			name:    "Pointer to non struct",
+			model:   new(string),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1, err := getCollectionName(tt.model)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getCollectionName error = %v, wantErr: %t", err, tt.wantErr)
+			}
+
+			if got1 != tt.want1 {
+				t.Errorf("getCollectionName got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		name     string
+		driver   string
+		path     func(t *testing.T) string
+		wantType Repository
+		wantErr  bool
+	}{
+		{
+			name:   "SQLite driver",
+			driver: "sqlite",
+			path: func(t *testing.T) string {
+				return filepath.Join(t.TempDir(), "library.db")
+			},
+			wantType: &SQLiteRepository{},
+		},
+		{
+			name:   "Unknown driver",
+			driver: "postgres",
+			path: func(_ *testing.T) string {
+				return ""
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1, err := Open(config.DB{Driver: tt.driver, Path: tt.path(t)})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Open error = %v, wantErr: %t", err, tt.wantErr)
+			}
+
+			if tt.wantType != nil && reflect.TypeOf(got1) != reflect.TypeOf(tt.wantType) {
+				t.Errorf("Open got1 = %T, want: %T", got1, tt.wantType)
+			}
+
+			if sqlite, ok := got1.(*SQLiteRepository); ok {
+				sqlite.DB.Close()
+			}
+		})
+	}
+}
@@ -0,0 +1,499 @@
+// Package repository servers as the wrapper for our data persistance packages
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	// Importing the driver registers it with database/sql
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// SQLiteRepository is the embedded SQLite implementation of Repository. Each collection is a table
+// of BSON documents keyed by their ObjectID, and filters are evaluated with the same query language
+// as MongoDB. Lookups by _id use the primary key, every other query scans the table, which suits
+// the size of a household library. Fields inside the documents can't be indexed by SQLite, so
+// unique fields are checked by the transaction writing a document instead.
+type SQLiteRepository struct {
+	DB *sql.DB
+
+	tables sync.Map
+	unique sync.Map
+}
+
+// sqliteRow is a stored document along with its primary key
+type sqliteRow struct {
+	id  string
+	raw bson.Raw
+}
+
+// queryer is the part of *sql.DB and *sql.Tx used to read documents
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// OpenSQLite is used to open, or create, the SQLite database file at path
+func OpenSQLite(path string) (*sql.DB, error) {
+	if path == "" {
+		return nil, fmt.Errorf("no SQLite database path configured")
+	}
+
+	// Immediate transactions take the write lock up front, so a read followed by a write in one
+	// transaction can't interleave with another writer
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate", path)
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("issue opening SQLite database: %w", err)
+	}
+
+	// SQLite allows a single writer, so a single connection avoids lock contention altogether
+	db.SetMaxOpenConns(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err = db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("issue verifying SQLite database: %w", err)
+	}
+
+	return db, nil
+}
+
+// Create is used to insert a new document into a collection
+func (db *SQLiteRepository) Create(ctx context.Context, model interface{}) error {
+	table, err := db.table(ctx, model)
+	if err != nil {
+		return err
+	}
+
+	model, err = setDefaultFields(model, true)
+	if err != nil {
+		return err
+	}
+
+	id := primitive.NewObjectID()
+
+	idField := reflect.ValueOf(model).Elem().FieldByName("ID")
+	if idField.IsValid() {
+		if existing, ok := idField.Interface().(primitive.ObjectID); ok && !existing.IsZero() {
+			id = existing
+		}
+	}
+
+	raw, err := toDocument(model, id)
+	if err != nil {
+		return err
+	}
+
+	err = db.withTransaction(ctx, func(tx *sql.Tx) error {
+		if err := db.checkUnique(ctx, tx, table, db.uniqueFields(table), raw, id); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (id, document) VALUES (?, ?)`, table), id.Hex(), []byte(raw))
+		return err
+	})
+
+	var sqliteError *sqlite.Error
+	if errors.As(err, &sqliteError) && sqliteError.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY {
+		return fmt.Errorf("%w: %s in %s: %w", ErrDuplicateKey, id.Hex(), table, err)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if idField.IsValid() && idField.CanSet() {
+		idField.Set(reflect.ValueOf(id))
+	}
+
+	return nil
+}
+
+// Read is used to find one document based on a filter
+func (db *SQLiteRepository) Read(ctx context.Context, model interface{}, filter interface{}) error {
+	table, err := db.table(ctx, model)
+	if err != nil {
+		return err
+	}
+
+	rows, err := findRows(ctx, db.DB, table, filter, true)
+	if err != nil {
+		return err
+	}
+
+	if len(rows) == 0 {
+		return ErrNotFound
+	}
+
+	return bson.Unmarshal(rows[0].raw, model)
+}
+
+// List is used to list all documents in a collection that match a filter
+func (db *SQLiteRepository) List(ctx context.Context, results interface{}, filter interface{}, sortColumns Sort, offset int64, limit int64) error {
+	table, err := db.table(ctx, results)
+	if err != nil {
+		return err
+	}
+
+	rows, err := findRows(ctx, db.DB, table, filter, false)
+	if err != nil {
+		return err
+	}
+
+	docs := make([]storedDocument, 0, len(rows))
+	for _, row := range rows {
+		doc, err := newStoredDocument(row.raw)
+		if err != nil {
+			return err
+		}
+
+		docs = append(docs, doc)
+	}
+
+	sortDocuments(docs, buildSort(sortColumns))
+
+	return decodeDocuments(paginate(docs, offset, limit), results)
+}
+
+// Count is used to count the documents in a collection that match a filter
+func (db *SQLiteRepository) Count(ctx context.Context, model interface{}, filter interface{}) (int64, error) {
+	table, err := db.table(ctx, model)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := findRows(ctx, db.DB, table, filter, false)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(rows)), nil
+}
+
+// Update is used to replace a document in specified collection
+func (db *SQLiteRepository) Update(ctx context.Context, model interface{}, filter interface{}) error {
+	table, err := db.table(ctx, model)
+	if err != nil {
+		return err
+	}
+
+	model, err = setDefaultFields(model, false)
+	if err != nil {
+		return err
+	}
+
+	return db.withTransaction(ctx, func(tx *sql.Tx) error {
+		rows, err := findRows(ctx, tx, table, filter, true)
+		if err != nil {
+			return err
+		}
+
+		if len(rows) == 0 {
+			return ErrNotFound
+		}
+
+		id, err := primitive.ObjectIDFromHex(rows[0].id)
+		if err != nil {
+			return err
+		}
+
+		// Like a MongoDB replace, the stored document keeps its id
+		raw, err := toDocument(model, id)
+		if err != nil {
+			return err
+		}
+
+		if err = db.checkUnique(ctx, tx, table, db.uniqueFields(table), raw, id); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET document = ? WHERE id = ?`, table), []byte(raw), rows[0].id)
+		return err
+	})
+}
+
+// UpdateFields is used to atomically set fields on the first document matching a filter. The model
+// is populated with the document as it is after the update.
+func (db *SQLiteRepository) UpdateFields(ctx context.Context, model interface{}, filter interface{}, fields bson.D) error {
+	table, err := db.table(ctx, model)
+	if err != nil {
+		return err
+	}
+
+	var updated bson.Raw
+
+	err = db.withTransaction(ctx, func(tx *sql.Tx) error {
+		rows, err := findRows(ctx, tx, table, filter, true)
+		if err != nil {
+			return err
+		}
+
+		if len(rows) == 0 {
+			return ErrNotFound
+		}
+
+		var doc bson.D
+		if err = bson.Unmarshal(rows[0].raw, &doc); err != nil {
+			return err
+		}
+
+		set := append(bson.D{}, fields...)
+		set = append(set, bson.E{Key: "updated_at", Value: time.Now().UTC()})
+
+		for _, field := range set {
+			if field.Key == "_id" {
+				return fmt.Errorf("%w: the _id field can't be updated", ErrInvalidDocument)
+			}
+
+			doc = setField(doc, field.Key, field.Value)
+		}
+
+		updated, err = bson.Marshal(doc)
+		if err != nil {
+			return err
+		}
+
+		id, err := primitive.ObjectIDFromHex(rows[0].id)
+		if err != nil {
+			return err
+		}
+
+		if err = db.checkUnique(ctx, tx, table, db.uniqueFields(table), updated, id); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET document = ? WHERE id = ?`, table), []byte(updated), rows[0].id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return bson.Unmarshal(updated, model)
+}
+
+// Delete is used to delete a document in specified collection
+func (db *SQLiteRepository) Delete(ctx context.Context, model interface{}, filter interface{}) error {
+	table, err := db.table(ctx, model)
+	if err != nil {
+		return err
+	}
+
+	return db.withTransaction(ctx, func(tx *sql.Tx) error {
+		rows, err := findRows(ctx, tx, table, filter, true)
+		if err != nil {
+			return err
+		}
+
+		if len(rows) == 0 {
+			return ErrNotFound
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = ?`, table), rows[0].id)
+		return err
+	})
+}
+
+// EnsureUniqueIndex is used to refuse writes that would repeat the value of a field. Like creating
+// a MongoDB index, it fails when stored documents already repeat a value.
+func (db *SQLiteRepository) EnsureUniqueIndex(ctx context.Context, model interface{}, field string) error {
+	table, err := db.table(ctx, model)
+	if err != nil {
+		return err
+	}
+
+	fields := db.uniqueFields(table)
+	if slices.Contains(fields, field) {
+		return nil
+	}
+
+	err = db.withTransaction(ctx, func(tx *sql.Tx) error {
+		rows, err := findRows(ctx, tx, table, bson.D{}, false)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			id, err := primitive.ObjectIDFromHex(row.id)
+			if err != nil {
+				return err
+			}
+
+			if err = db.checkUnique(ctx, tx, table, []string{field}, row.raw, id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	db.unique.Store(table, append(slices.Clone(fields), field))
+
+	return nil
+}
+
+// IsNotFoundError verifies the type of error returning from a find query
+func (db *SQLiteRepository) IsNotFoundError(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, sql.ErrNoRows)
+}
+
+// Ping is used to check the SQLite database file can still be opened
+func (db *SQLiteRepository) Ping(ctx context.Context) error {
+	return db.DB.PingContext(ctx)
+}
+
+// Close is used to close the SQLite database, flushing the write ahead log into the file
+func (db *SQLiteRepository) Close(_ context.Context) error {
+	return db.DB.Close()
+}
+
+// table returns the quoted table name for a model, creating the table the first time the
+// collection is used like MongoDB does
+func (db *SQLiteRepository) table(ctx context.Context, model interface{}) (string, error) {
+	collectionName, err := getCollectionName(model)
+	if err != nil {
+		return "", err
+	}
+
+	table := `"` + strings.ReplaceAll(collectionName, `"`, `""`) + `"`
+
+	if _, ok := db.tables.Load(collectionName); ok {
+		return table, nil
+	}
+
+	_, err = db.DB.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (id TEXT PRIMARY KEY, document BLOB NOT NULL)`, table))
+	if err != nil {
+		return "", fmt.Errorf("issue creating table %s: %w", collectionName, err)
+	}
+
+	db.tables.Store(collectionName, true)
+
+	return table, nil
+}
+
+// uniqueFields returns the fields of a table no two documents may share a value of
+func (db *SQLiteRepository) uniqueFields(table string) []string {
+	fields, ok := db.unique.Load(table)
+	if !ok {
+		return nil
+	}
+
+	return fields.([]string)
+}
+
+// checkUnique returns ErrDuplicateKey when a document would repeat the value another document has
+// for one of the unique fields. It has to run in the transaction writing the document, so no other
+// write can land between the check and the write.
+func (db *SQLiteRepository) checkUnique(ctx context.Context, tx *sql.Tx, table string, fields []string, raw bson.Raw, id primitive.ObjectID) error {
+	for _, filter := range uniqueFilters(fields, raw, id) {
+		rows, err := findRows(ctx, tx, table, filter, true)
+		if err != nil {
+			return err
+		}
+
+		if len(rows) > 0 {
+			return fmt.Errorf("%w: %s in %s", ErrDuplicateKey, filter[0].Key, table)
+		}
+	}
+
+	return nil
+}
+
+// withTransaction runs fn in a transaction, committing when it succeeds and rolling back otherwise
+func (db *SQLiteRepository) withTransaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// findRows returns the documents in a table matching a filter, in insertion order. When the filter
+// names an _id the lookup goes through the primary key instead of scanning the table.
+func findRows(ctx context.Context, q queryer, table string, filter interface{}, first bool) ([]sqliteRow, error) {
+	normalized, err := normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT id, document FROM %s`, table)
+	args := []interface{}{}
+
+	if id, ok := filterID(normalized); ok {
+		query += ` WHERE id = ?`
+		args = append(args, id.Hex())
+	}
+
+	query += ` ORDER BY rowid`
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []sqliteRow
+	for rows.Next() {
+		var row sqliteRow
+		var data []byte
+		if err = rows.Scan(&row.id, &data); err != nil {
+			return nil, err
+		}
+
+		row.raw = bson.Raw(data)
+
+		var doc bson.M
+		if err = bson.Unmarshal(row.raw, &doc); err != nil {
+			return nil, err
+		}
+
+		matched, err := matchDocument(doc, normalized)
+		if err != nil {
+			return nil, err
+		}
+
+		if matched {
+			found = append(found, row)
+			if first {
+				break
+			}
+		}
+	}
+
+	return found, rows.Err()
+}
+
+// filterID returns the ObjectID a filter requires the _id to equal, if it has one
+func filterID(filter bson.D) (primitive.ObjectID, bool) {
+	for _, element := range filter {
+		if element.Key == "_id" {
+			id, ok := element.Value.(primitive.ObjectID)
+			return id, ok
+		}
+	}
+
+	return primitive.NilObjectID, false
+}
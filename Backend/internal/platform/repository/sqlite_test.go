@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLiteRepository(t *testing.T) {
+	testRepository(t, func(t *testing.T) Repository {
+		db, err := OpenSQLite(filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatalf("OpenSQLite error = %v", err)
+		}
+
+		t.Cleanup(func() {
+			db.Close()
+		})
+
+		return &SQLiteRepository{
+			DB: db,
+		}
+	})
+}
+
+func TestOpenSQLite(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    func(t *testing.T) string
+		wantErr bool
+	}{
+		{
+			name: "New database file",
+			path: func(t *testing.T) string {
+				return filepath.Join(t.TempDir(), "library.db")
+			},
+		},
+		{
+			name: "No path configured",
+			path: func(_ *testing.T) string {
+				return ""
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := OpenSQLite(tt.path(t))
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OpenSQLite error = %v, wantErr: %t", err, tt.wantErr)
+			}
+
+			if db != nil {
+				db.Close()
+			}
+		})
+	}
+}
+
+func TestSQLiteRepository_PingClose(t *testing.T) {
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite error = %v", err)
+	}
+
+	repo := &SQLiteRepository{DB: db}
+
+	if err = repo.Ping(context.Background()); err != nil {
+		t.Errorf("Ping error = %v, want: nil", err)
+	}
+
+	if err = repo.Close(context.Background()); err != nil {
+		t.Errorf("Close error = %v, want: nil", err)
+	}
+
+	if err = repo.Ping(context.Background()); err == nil {
+		t.Error("Ping after Close error = nil, want an error")
+	}
+}
@@ -10,8 +10,8 @@ import (
 
 func TestBadRequest(t *testing.T) {
 	type args struct {
-		w    http.ResponseWriter
-		data interface{}
+		w      http.ResponseWriter
+		detail string
 	}
 	tests := []struct {
 		name     string
@@ -21,39 +21,44 @@ func TestBadRequest(t *testing.T) {
 		wantBody map[string]interface{}
 	}{
 		{
-			name: "Simple string data",
+			name: "Detail message",
 			args: func(_ *testing.T) args {
 				return args{
-					w:    httptest.NewRecorder(),
-					data: "Invalid input",
+					w:      httptest.NewRecorder(),
+					detail: "limit must be a positive number",
 				}
 			},
 			want1:    nil,
 			wantCode: http.StatusBadRequest,
 			wantBody: map[string]interface{}{
-				"message": "data validation failed",
-				"data":    "Invalid input",
+				"type":   "about:blank",
+				"title":  "Bad Request",
+				"status": float64(400),
+				"code":   "bad_request",
+				"detail": "limit must be a positive number",
 			},
 		},
 		{
-			name: "Struct data",
+			name: "Request id is repeated",
 			args: func(_ *testing.T) args {
 				return args{
-					w: httptest.NewRecorder(),
-					data: struct {
-						Field string `json:"field"`
-					}{
-						Field: "Invalid",
-					},
+					w: func() http.ResponseWriter {
+						rec := httptest.NewRecorder()
+						rec.Header().Set(RequestIDHeader, "req-123")
+						return rec
+					}(),
+					detail: "invalid id",
 				}
 			},
 			want1:    nil,
 			wantCode: http.StatusBadRequest,
 			wantBody: map[string]interface{}{
-				"message": "data validation failed",
-				"data": map[string]interface{}{
-					"field": "Invalid",
-				},
+				"type":       "about:blank",
+				"title":      "Bad Request",
+				"status":     float64(400),
+				"code":       "bad_request",
+				"detail":     "invalid id",
+				"request_id": "req-123",
 			},
 		},
 	}
@@ -62,7 +67,7 @@ func TestBadRequest(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tArgs := tt.args(t)
 
-			got1 := BadRequest(tArgs.w, tArgs.data)
+			got1 := BadRequest(tArgs.w, tArgs.detail)
 
 			if !reflect.DeepEqual(got1, tt.want1) {
 				t.Errorf("BadRequest got1 = %v, want1: %v", got1, tt.want1)
@@ -77,8 +82,8 @@ func TestBadRequest(t *testing.T) {
 				t.Errorf("BadRequest status code = %v, want: %v", rec.Code, tt.wantCode)
 			}
 
-			if rec.Header().Get("Content-Type") != "application/json" {
-				t.Errorf("BadRequest Content-Type = %v, want: application/json", rec.Header().Get("Content-Type"))
+			if rec.Header().Get("Content-Type") != "application/problem+json" {
+				t.Errorf("BadRequest Content-Type = %v, want: application/problem+json", rec.Header().Get("Content-Type"))
 			}
 
 			var gotBody map[string]interface{}
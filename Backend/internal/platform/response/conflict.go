@@ -0,0 +1,15 @@
+// Package response contains the templates for building our responses to the user
+package response
+
+import (
+	"net/http"
+)
+
+// Conflict is used to send a 409 response to the user
+func Conflict(w http.ResponseWriter, detail string) interface{} {
+	return WriteProblem(w, Problem{
+		Status: http.StatusConflict,
+		Detail: detail,
+		Code:   CodeConflict,
+	})
+}
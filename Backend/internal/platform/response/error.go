@@ -0,0 +1,44 @@
+// Package response contains the templates for building our responses to the user
+package response
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/validation"
+	"errors"
+	"net/http"
+)
+
+// Error is used to send the response matching an error from the repository or validation layers.
+// Only validation errors are sent with their message. Repository errors can wrap the storage
+// backend's own error, so they are sent with a fixed message and callers should log them.
+func Error(w http.ResponseWriter, err error) interface{} {
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		return ValidationFailed(w, fields)
+	}
+
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return NotFound(w, "the document was not found")
+	case errors.Is(err, repository.ErrDuplicateKey):
+		return WriteProblem(w, Problem{
+			Status: http.StatusConflict,
+			Detail: "a document with the same key already exists",
+			Code:   CodeDuplicateKey,
+		})
+	case errors.Is(err, repository.ErrInvalidCursor):
+		return WriteProblem(w, Problem{
+			Status: http.StatusBadRequest,
+			Detail: "the cursor is not valid for this list",
+			Code:   CodeInvalidCursor,
+		})
+	case errors.Is(err, repository.ErrInvalidDocument):
+		return WriteProblem(w, Problem{
+			Status: http.StatusUnprocessableEntity,
+			Detail: "the change to the document is not allowed",
+			Code:   CodeValidationFailed,
+		})
+	}
+
+	return InternalServerError(w, err)
+}
@@ -0,0 +1,177 @@
+package response
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"Home-Intranet-v2-Backend/internal/platform/validation"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	type args struct {
+		w   http.ResponseWriter
+		err error
+	}
+	tests := []struct {
+		name     string
+		args     func(t *testing.T) args
+		want1    interface{}
+		wantCode int
+		wantBody map[string]interface{}
+	}{
+		{
+			name: "Not found",
+			args: func(_ *testing.T) args {
+				return args{
+					w:   httptest.NewRecorder(),
+					err: fmt.Errorf("issue reading book: %w", repository.ErrNotFound),
+				}
+			},
+			want1:    nil,
+			wantCode: http.StatusNotFound,
+			wantBody: map[string]interface{}{
+				"type":   "about:blank",
+				"title":  "Not Found",
+				"status": float64(404),
+				"code":   "not_found",
+				"detail": "the document was not found",
+			},
+		},
+		{
+			name: "Duplicate key",
+			args: func(_ *testing.T) args {
+				return args{
+					w:   httptest.NewRecorder(),
+					err: fmt.Errorf("%w: 42 in books", repository.ErrDuplicateKey),
+				}
+			},
+			want1:    nil,
+			wantCode: http.StatusConflict,
+			wantBody: map[string]interface{}{
+				"type":   "about:blank",
+				"title":  "Conflict",
+				"status": float64(409),
+				"code":   "duplicate_key",
+				"detail": "a document with the same key already exists",
+			},
+		},
+		{
+			name: "Validation errors list every field",
+			args: func(_ *testing.T) args {
+				return args{
+					w: httptest.NewRecorder(),
+					err: fmt.Errorf("issue validating book: %w", validation.Errors{
+						{Field: "title", Code: "required", Message: "is required"},
+						{Field: "shelf", Code: "max_length", Message: "must be at most 20 characters"},
+					}),
+				}
+			},
+			want1:    nil,
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: map[string]interface{}{
+				"type":   "about:blank",
+				"title":  "Unprocessable Entity",
+				"status": float64(422),
+				"code":   "validation_failed",
+				"detail": "the request has invalid fields",
+				"errors": []interface{}{
+					map[string]interface{}{"field": "title", "code": "required", "message": "is required"},
+					map[string]interface{}{"field": "shelf", "code": "max_length", "message": "must be at most 20 characters"},
+				},
+			},
+		},
+		{
+			name: "Invalid cursor",
+			args: func(_ *testing.T) args {
+				return args{
+					w:   httptest.NewRecorder(),
+					err: repository.ErrInvalidCursor,
+				}
+			},
+			want1:    nil,
+			wantCode: http.StatusBadRequest,
+			wantBody: map[string]interface{}{
+				"type":   "about:blank",
+				"title":  "Bad Request",
+				"status": float64(400),
+				"code":   "invalid_cursor",
+				"detail": "the cursor is not valid for this list",
+			},
+		},
+		{
+			name: "Invalid document",
+			args: func(_ *testing.T) args {
+				return args{
+					w:   httptest.NewRecorder(),
+					err: fmt.Errorf("%w: %w", repository.ErrInvalidDocument, errors.New("(ImmutableField) Performing an update on the path '_id' would modify the immutable field '_id'")),
+				}
+			},
+			want1:    nil,
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: map[string]interface{}{
+				"type":   "about:blank",
+				"title":  "Unprocessable Entity",
+				"status": float64(422),
+				"code":   "validation_failed",
+				"detail": "the change to the document is not allowed",
+			},
+		},
+		{
+			name: "Unknown error",
+			args: func(_ *testing.T) args {
+				return args{
+					w:   httptest.NewRecorder(),
+					err: errors.New("disk full"),
+				}
+			},
+			want1:    nil,
+			wantCode: http.StatusInternalServerError,
+			wantBody: map[string]interface{}{
+				"type":   "about:blank",
+				"title":  "Internal Server Error",
+				"status": float64(500),
+				"code":   "internal_error",
+				"detail": "an unexpected error occurred",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tArgs := tt.args(t)
+
+			got1 := Error(tArgs.w, tArgs.err)
+
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("Error got1 = %v, want1: %v", got1, tt.want1)
+			}
+
+			rec, ok := tArgs.w.(*httptest.ResponseRecorder)
+			if !ok {
+				t.Fatal("ResponseRecorder not found")
+			}
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("Error status code = %v, want: %v", rec.Code, tt.wantCode)
+			}
+
+			if rec.Header().Get("Content-Type") != "application/problem+json" {
+				t.Errorf("Error Content-Type = %v, want: application/problem+json", rec.Header().Get("Content-Type"))
+			}
+
+			var gotBody map[string]interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &gotBody); err != nil {
+				t.Fatalf("Failed to unmarshal response body: %v", err)
+			}
+
+			if !reflect.DeepEqual(gotBody, tt.wantBody) {
+				t.Errorf("Error body = %v, want: %v", gotBody, tt.wantBody)
+			}
+		})
+	}
+}
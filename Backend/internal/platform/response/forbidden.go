@@ -0,0 +1,16 @@
+// Package response contains the templates for building our responses to the user
+package response
+
+import (
+	"net/http"
+)
+
+// Forbidden is used to send a 403 response to the user, when the logged in user isn't allowed to
+// do what they asked
+func Forbidden(w http.ResponseWriter, detail string) interface{} {
+	return WriteProblem(w, Problem{
+		Status: http.StatusForbidden,
+		Detail: detail,
+		Code:   CodeForbidden,
+	})
+}
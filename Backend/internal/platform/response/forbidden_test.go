@@ -0,0 +1,76 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestForbidden(t *testing.T) {
+	type args struct {
+		w      http.ResponseWriter
+		detail string
+	}
+	tests := []struct {
+		name     string
+		args     func(t *testing.T) args
+		want1    interface{}
+		wantCode int
+		wantBody map[string]interface{}
+	}{
+		{
+			name: "Detail message",
+			args: func(_ *testing.T) args {
+				return args{
+					w:      httptest.NewRecorder(),
+					detail: "guests can't delete books",
+				}
+			},
+			want1:    nil,
+			wantCode: http.StatusForbidden,
+			wantBody: map[string]interface{}{
+				"type":   "about:blank",
+				"title":  "Forbidden",
+				"status": float64(403),
+				"code":   "forbidden",
+				"detail": "guests can't delete books",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tArgs := tt.args(t)
+
+			got1 := Forbidden(tArgs.w, tArgs.detail)
+
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("Forbidden got1 = %v, want1: %v", got1, tt.want1)
+			}
+
+			rec, ok := tArgs.w.(*httptest.ResponseRecorder)
+			if !ok {
+				t.Fatal("ResponseRecorder not found")
+			}
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("Forbidden status code = %v, want: %v", rec.Code, tt.wantCode)
+			}
+
+			if rec.Header().Get("Content-Type") != "application/problem+json" {
+				t.Errorf("Forbidden Content-Type = %v, want: application/problem+json", rec.Header().Get("Content-Type"))
+			}
+
+			var gotBody map[string]interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &gotBody); err != nil {
+				t.Fatalf("Failed to unmarshal response body: %v", err)
+			}
+
+			if !reflect.DeepEqual(gotBody, tt.wantBody) {
+				t.Errorf("Forbidden body = %v, want: %v", gotBody, tt.wantBody)
+			}
+		})
+	}
+}
@@ -2,16 +2,15 @@
 package response
 
 import (
-	"encoding/json"
 	"net/http"
 )
 
-// InternalServerError is used to send a 500 response to the user
-func InternalServerError(w http.ResponseWriter, data interface{}) interface{} {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusInternalServerError)
-	return json.NewEncoder(w).Encode(map[string]interface{}{
-		"message": "internal server error",
-		"data":    &data,
+// InternalServerError is used to send a 500 response to the user. The error is not sent, since it
+// can reveal details of the server, so callers should log it.
+func InternalServerError(w http.ResponseWriter, _ error) interface{} {
+	return WriteProblem(w, Problem{
+		Status: http.StatusInternalServerError,
+		Detail: "an unexpected error occurred",
+		Code:   CodeInternal,
 	})
 }
@@ -2,6 +2,7 @@ package response
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -10,8 +11,8 @@ import (
 
 func TestInternalServerError(t *testing.T) {
 	type args struct {
-		w    http.ResponseWriter
-		data interface{}
+		w   http.ResponseWriter
+		err error
 	}
 	tests := []struct {
 		name     string
@@ -21,42 +22,21 @@ func TestInternalServerError(t *testing.T) {
 		wantBody map[string]interface{}
 	}{
 		{
-			name: "Simple string data",
+			name: "Error message is not leaked",
 			args: func(_ *testing.T) args {
 				return args{
-					w:    httptest.NewRecorder(),
-					data: "Server error occurred",
+					w:   httptest.NewRecorder(),
+					err: errors.New("connection refused to 10.0.0.5"),
 				}
 			},
 			want1:    nil,
 			wantCode: http.StatusInternalServerError,
 			wantBody: map[string]interface{}{
-				"message": "internal server error",
-				"data":    "Server error occurred",
-			},
-		},
-		{
-			name: "Struct data",
-			args: func(_ *testing.T) args {
-				return args{
-					w: httptest.NewRecorder(),
-					data: struct {
-						ErrorCode int    `json:"error_code"`
-						Details   string `json:"details"`
-					}{
-						ErrorCode: 500,
-						Details:   "Unexpected error",
-					},
-				}
-			},
-			want1:    nil,
-			wantCode: http.StatusInternalServerError,
-			wantBody: map[string]interface{}{
-				"message": "internal server error",
-				"data": map[string]interface{}{
-					"error_code": float64(500), // JSON numbers are floats
-					"details":    "Unexpected error",
-				},
+				"type":   "about:blank",
+				"title":  "Internal Server Error",
+				"status": float64(500),
+				"code":   "internal_error",
+				"detail": "an unexpected error occurred",
 			},
 		},
 	}
@@ -65,7 +45,7 @@ func TestInternalServerError(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tArgs := tt.args(t)
 
-			got1 := InternalServerError(tArgs.w, tArgs.data)
+			got1 := InternalServerError(tArgs.w, tArgs.err)
 
 			if !reflect.DeepEqual(got1, tt.want1) {
 				t.Errorf("InternalServerError got1 = %v, want1: %v", got1, tt.want1)
@@ -80,8 +60,8 @@ func TestInternalServerError(t *testing.T) {
 				t.Errorf("InternalServerError status code = %v, want: %v", rec.Code, tt.wantCode)
 			}
 
-			if rec.Header().Get("Content-Type") != "application/json" {
-				t.Errorf("InternalServerError Content-Type = %v, want: application/json", rec.Header().Get("Content-Type"))
+			if rec.Header().Get("Content-Type") != "application/problem+json" {
+				t.Errorf("InternalServerError Content-Type = %v, want: application/problem+json", rec.Header().Get("Content-Type"))
 			}
 
 			var gotBody map[string]interface{}
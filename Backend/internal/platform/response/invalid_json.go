@@ -0,0 +1,59 @@
+// Package response contains the templates for building our responses to the user
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// InvalidJSON is used to send a 400 response when a request body can't be decoded. A value of the
+// wrong type is reported against its field, without repeating the decoder's error.
+func InvalidJSON(w http.ResponseWriter, err error) interface{} {
+	problem := Problem{
+		Status: http.StatusBadRequest,
+		Detail: "the request body is not valid JSON",
+		Code:   CodeInvalidJSON,
+	}
+
+	var typeError *json.UnmarshalTypeError
+	var timeError *time.ParseError
+
+	switch {
+	case errors.As(err, &typeError) && typeError.Field != "":
+		problem.Detail = "the request body has a value of the wrong type"
+		problem.Errors = []FieldError{{
+			Field:   typeError.Field,
+			Code:    "invalid_type",
+			Message: "must be " + jsonTypeName(typeError.Type),
+		}}
+	case errors.As(err, &timeError):
+		problem.Detail = "the request body has a time that isn't in RFC 3339 format"
+	}
+
+	return WriteProblem(w, problem)
+}
+
+// jsonTypeName describes the JSON type a Go type is decoded from
+func jsonTypeName(goType reflect.Type) string {
+	for goType.Kind() == reflect.Ptr {
+		goType = goType.Elem()
+	}
+
+	switch goType.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+
+	return "an object"
+}
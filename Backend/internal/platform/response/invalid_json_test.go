@@ -0,0 +1,120 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInvalidJSON(t *testing.T) {
+	type args struct {
+		w   http.ResponseWriter
+		err error
+	}
+	tests := []struct {
+		name     string
+		args     func(t *testing.T) args
+		want1    interface{}
+		wantCode int
+		wantBody map[string]interface{}
+	}{
+		{
+			name: "Syntax error",
+			args: func(_ *testing.T) args {
+				return args{
+					w:   httptest.NewRecorder(),
+					err: json.Unmarshal([]byte(`{"title":`), &struct{}{}),
+				}
+			},
+			want1:    nil,
+			wantCode: http.StatusBadRequest,
+			wantBody: map[string]interface{}{
+				"type":   "about:blank",
+				"title":  "Bad Request",
+				"status": float64(400),
+				"code":   "invalid_json",
+				"detail": "the request body is not valid JSON",
+			},
+		},
+		{
+			name: "Wrong type is reported on the field",
+			args: func(_ *testing.T) args {
+				return args{
+					w: httptest.NewRecorder(),
+					err: json.Unmarshal([]byte(`{"shelf": 12}`), &struct {
+						Shelf string `json:"shelf"`
+					}{}),
+				}
+			},
+			want1:    nil,
+			wantCode: http.StatusBadRequest,
+			wantBody: map[string]interface{}{
+				"type":   "about:blank",
+				"title":  "Bad Request",
+				"status": float64(400),
+				"code":   "invalid_json",
+				"detail": "the request body has a value of the wrong type",
+				"errors": []interface{}{
+					map[string]interface{}{"field": "shelf", "code": "invalid_type", "message": "must be a string"},
+				},
+			},
+		},
+		{
+			name: "Invalid time",
+			args: func(_ *testing.T) args {
+				return args{
+					w: httptest.NewRecorder(),
+					err: json.Unmarshal([]byte(`{"due_date": "tomorrow"}`), &struct {
+						DueDate time.Time `json:"due_date"`
+					}{}),
+				}
+			},
+			want1:    nil,
+			wantCode: http.StatusBadRequest,
+			wantBody: map[string]interface{}{
+				"type":   "about:blank",
+				"title":  "Bad Request",
+				"status": float64(400),
+				"code":   "invalid_json",
+				"detail": "the request body has a time that isn't in RFC 3339 format",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tArgs := tt.args(t)
+
+			got1 := InvalidJSON(tArgs.w, tArgs.err)
+
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("InvalidJSON got1 = %v, want1: %v", got1, tt.want1)
+			}
+
+			rec, ok := tArgs.w.(*httptest.ResponseRecorder)
+			if !ok {
+				t.Fatal("ResponseRecorder not found")
+			}
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("InvalidJSON status code = %v, want: %v", rec.Code, tt.wantCode)
+			}
+
+			if rec.Header().Get("Content-Type") != "application/problem+json" {
+				t.Errorf("InvalidJSON Content-Type = %v, want: application/problem+json", rec.Header().Get("Content-Type"))
+			}
+
+			var gotBody map[string]interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &gotBody); err != nil {
+				t.Fatalf("Failed to unmarshal response body: %v", err)
+			}
+
+			if !reflect.DeepEqual(gotBody, tt.wantBody) {
+				t.Errorf("InvalidJSON body = %v, want: %v", gotBody, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+// Package response contains the templates for building our responses to the user
+package response
+
+import (
+	"net/http"
+)
+
+// NotFound is used to send a 404 response to the user
+func NotFound(w http.ResponseWriter, detail string) interface{} {
+	return WriteProblem(w, Problem{
+		Status: http.StatusNotFound,
+		Detail: detail,
+		Code:   CodeNotFound,
+	})
+}
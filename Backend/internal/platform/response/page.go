@@ -0,0 +1,40 @@
+// Package response contains the templates for building our responses to the user
+package response
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Page is used to send a page of a list to the user. The cursors of the pages either side are also
+// sent as RFC 8288 Link headers, pointing at the same request with the cursor swapped.
+func Page[T any](w http.ResponseWriter, request *http.Request, page repository.Page[T]) {
+	links := []string{}
+
+	for _, link := range []struct {
+		rel    string
+		cursor string
+	}{
+		{rel: "next", cursor: page.NextCursor},
+		{rel: "prev", cursor: page.PrevCursor},
+	} {
+		if link.cursor == "" {
+			continue
+		}
+
+		query := request.URL.Query()
+		query.Set("cursor", link.cursor)
+
+		target := url.URL{Path: request.URL.Path, RawQuery: query.Encode()}
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, target.String(), link.rel))
+	}
+
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+
+	SuccessResponse(w, page)
+}
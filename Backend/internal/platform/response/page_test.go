@@ -0,0 +1,63 @@
+package response
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/repository"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestPage(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		page     repository.Page[string]
+		wantLink string
+	}{
+		{
+			name:   "Only page",
+			target: "/v1/books",
+			page:   repository.Page[string]{Items: []string{"a"}, Total: 1},
+		},
+		{
+			name:     "First page",
+			target:   "/v1/books?limit=1",
+			page:     repository.Page[string]{Items: []string{"a"}, Total: 2, NextCursor: "next"},
+			wantLink: `</v1/books?cursor=next&limit=1>; rel="next"`,
+		},
+		{
+			name:     "Middle page",
+			target:   "/v1/books?cursor=here&filter%5Bshelf%5D=12&limit=1",
+			page:     repository.Page[string]{Items: []string{"b"}, Total: 3, NextCursor: "next", PrevCursor: "prev"},
+			wantLink: `</v1/books?cursor=next&filter%5Bshelf%5D=12&limit=1>; rel="next", </v1/books?cursor=prev&filter%5Bshelf%5D=12&limit=1>; rel="prev"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			Page(rec, httptest.NewRequest(http.MethodGet, tt.target, nil), tt.page)
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("Page status code = %v, want: %v", rec.Code, http.StatusOK)
+			}
+
+			if link := rec.Header().Get("Link"); link != tt.wantLink {
+				t.Errorf("Page Link = %v, want: %v", link, tt.wantLink)
+			}
+
+			var body struct {
+				Data repository.Page[string] `json:"data"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to unmarshal response body: %v", err)
+			}
+
+			if !reflect.DeepEqual(body.Data, tt.page) {
+				t.Errorf("Page data = %+v, want: %+v", body.Data, tt.page)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Package response contains the templates for building our responses to the user
+package response
+
+import (
+	"Home-Intranet-v2-Backend/internal/platform/validation"
+	"encoding/json"
+	"net/http"
+)
+
+// RequestIDHeader is the header carrying the id of the request, which error responses repeat so a
+// user's report can be matched with the logs
+const RequestIDHeader = "X-Request-Id"
+
+// The stable codes of error responses. Clients should switch on these rather than on the
+// messages, which are written for people and may change.
+const (
+	CodeBadRequest       = "bad_request"
+	CodeInvalidJSON      = "invalid_json"
+	CodeInvalidCursor    = "invalid_cursor"
+	CodeValidationFailed = "validation_failed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeDuplicateKey     = "duplicate_key"
+	CodeInternal         = "internal_error"
+)
+
+// Problem is the body of every error response, an RFC 7807 problem details object extended with a
+// stable code, the request id and the problems with individual fields
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes what is wrong with one field of a request. Field is the path to it in the
+// JSON body, such as authors.0.last_name.
+type FieldError = validation.FieldError
+
+// WriteProblem is used to send a problem to the user as application/problem+json. The type, title
+// and request id are filled in when they are missing.
+func WriteProblem(w http.ResponseWriter, problem Problem) interface{} {
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+
+	if problem.RequestID == "" {
+		problem.RequestID = w.Header().Get(RequestIDHeader)
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
+	return json.NewEncoder(w).Encode(problem)
+}
@@ -0,0 +1,59 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestWriteProblem(t *testing.T) {
+	tests := []struct {
+		name      string
+		problem   Problem
+		requestID string
+		want1     Problem
+	}{
+		{
+			name:    "Defaults are filled in",
+			problem: Problem{Status: http.StatusTeapot, Code: "teapot"},
+			want1:   Problem{Type: "about:blank", Title: "I'm a teapot", Status: http.StatusTeapot, Code: "teapot"},
+		},
+		{
+			name:      "Request id comes from the response header",
+			problem:   Problem{Status: http.StatusBadRequest, Code: CodeBadRequest, Detail: "bad"},
+			requestID: "req-123",
+			want1:     Problem{Type: "about:blank", Title: "Bad Request", Status: http.StatusBadRequest, Code: CodeBadRequest, Detail: "bad", RequestID: "req-123"},
+		},
+		{
+			name:    "Given values are kept",
+			problem: Problem{Type: "https://example.com/problems/late", Title: "Late", Status: http.StatusConflict, Code: "late"},
+			want1:   Problem{Type: "https://example.com/problems/late", Title: "Late", Status: http.StatusConflict, Code: "late"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			if tt.requestID != "" {
+				rec.Header().Set(RequestIDHeader, tt.requestID)
+			}
+
+			WriteProblem(rec, tt.problem)
+
+			if rec.Code != tt.want1.Status {
+				t.Errorf("WriteProblem status code = %v, want: %v", rec.Code, tt.want1.Status)
+			}
+
+			var got1 Problem
+			if err := json.Unmarshal(rec.Body.Bytes(), &got1); err != nil {
+				t.Fatalf("Failed to unmarshal response body: %v", err)
+			}
+
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("WriteProblem got1 = %+v, want1: %+v", got1, tt.want1)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+// Package response contains the templates for building our responses to the user
+package response
+
+import (
+	"net/http"
+)
+
+// Unauthorized is used to send a 401 response to the user, when a request needs a logged in user
+// and doesn't have one
+func Unauthorized(w http.ResponseWriter, detail string) interface{} {
+	return WriteProblem(w, Problem{
+		Status: http.StatusUnauthorized,
+		Detail: detail,
+		Code:   CodeUnauthorized,
+	})
+}
@@ -0,0 +1,76 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestUnauthorized(t *testing.T) {
+	type args struct {
+		w      http.ResponseWriter
+		detail string
+	}
+	tests := []struct {
+		name     string
+		args     func(t *testing.T) args
+		want1    interface{}
+		wantCode int
+		wantBody map[string]interface{}
+	}{
+		{
+			name: "Detail message",
+			args: func(_ *testing.T) args {
+				return args{
+					w:      httptest.NewRecorder(),
+					detail: "log in to continue",
+				}
+			},
+			want1:    nil,
+			wantCode: http.StatusUnauthorized,
+			wantBody: map[string]interface{}{
+				"type":   "about:blank",
+				"title":  "Unauthorized",
+				"status": float64(401),
+				"code":   "unauthorized",
+				"detail": "log in to continue",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tArgs := tt.args(t)
+
+			got1 := Unauthorized(tArgs.w, tArgs.detail)
+
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("Unauthorized got1 = %v, want1: %v", got1, tt.want1)
+			}
+
+			rec, ok := tArgs.w.(*httptest.ResponseRecorder)
+			if !ok {
+				t.Fatal("ResponseRecorder not found")
+			}
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("Unauthorized status code = %v, want: %v", rec.Code, tt.wantCode)
+			}
+
+			if rec.Header().Get("Content-Type") != "application/problem+json" {
+				t.Errorf("Unauthorized Content-Type = %v, want: application/problem+json", rec.Header().Get("Content-Type"))
+			}
+
+			var gotBody map[string]interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &gotBody); err != nil {
+				t.Fatalf("Failed to unmarshal response body: %v", err)
+			}
+
+			if !reflect.DeepEqual(gotBody, tt.wantBody) {
+				t.Errorf("Unauthorized body = %v, want: %v", gotBody, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+// Package response contains the templates for building our responses to the user
+package response
+
+import (
+	"net/http"
+)
+
+// ValidationFailed is used to send a 422 response listing every invalid field of a request
+func ValidationFailed(w http.ResponseWriter, fields []FieldError) interface{} {
+	return WriteProblem(w, Problem{
+		Status: http.StatusUnprocessableEntity,
+		Detail: "the request has invalid fields",
+		Code:   CodeValidationFailed,
+		Errors: fields,
+	})
+}
@@ -0,0 +1,83 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestValidationFailed(t *testing.T) {
+	type args struct {
+		w      http.ResponseWriter
+		fields []FieldError
+	}
+	tests := []struct {
+		name     string
+		args     func(t *testing.T) args
+		want1    interface{}
+		wantCode int
+		wantBody map[string]interface{}
+	}{
+		{
+			name: "Every field is listed",
+			args: func(_ *testing.T) args {
+				return args{
+					w: httptest.NewRecorder(),
+					fields: []FieldError{
+						{Field: "title", Code: "required", Message: "is required"},
+						{Field: "authors.0.last_name", Code: "max_length", Message: "must be at most 100 characters"},
+					},
+				}
+			},
+			want1:    nil,
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: map[string]interface{}{
+				"type":   "about:blank",
+				"title":  "Unprocessable Entity",
+				"status": float64(422),
+				"code":   "validation_failed",
+				"detail": "the request has invalid fields",
+				"errors": []interface{}{
+					map[string]interface{}{"field": "title", "code": "required", "message": "is required"},
+					map[string]interface{}{"field": "authors.0.last_name", "code": "max_length", "message": "must be at most 100 characters"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tArgs := tt.args(t)
+
+			got1 := ValidationFailed(tArgs.w, tArgs.fields)
+
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("ValidationFailed got1 = %v, want1: %v", got1, tt.want1)
+			}
+
+			rec, ok := tArgs.w.(*httptest.ResponseRecorder)
+			if !ok {
+				t.Fatal("ResponseRecorder not found")
+			}
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("ValidationFailed status code = %v, want: %v", rec.Code, tt.wantCode)
+			}
+
+			if rec.Header().Get("Content-Type") != "application/problem+json" {
+				t.Errorf("ValidationFailed Content-Type = %v, want: application/problem+json", rec.Header().Get("Content-Type"))
+			}
+
+			var gotBody map[string]interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &gotBody); err != nil {
+				t.Fatalf("Failed to unmarshal response body: %v", err)
+			}
+
+			if !reflect.DeepEqual(gotBody, tt.wantBody) {
+				t.Errorf("ValidationFailed body = %v, want: %v", gotBody, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+// Package validation checks models against the rules declared in their validate struct tags
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError describes what is wrong with one field of a request. Field is the path to it in the
+// JSON body, such as authors.0.last_name.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Errors is every field error found on a value. It is an error itself so it can be returned
+// through the layers that don't care about the individual fields.
+type Errors []FieldError
+
+// Error joins the field errors into one message
+func (errs Errors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, fieldError := range errs {
+		messages = append(messages, fmt.Sprintf("%s %s", fieldError.Field, fieldError.Message))
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Err returns the errors as an error, or nil when there are none
+func (errs Errors) Err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+// Prefix puts a path in front of the field of every error, which is used when a value is checked
+// on its own but sits inside a larger request
+func (errs Errors) Prefix(path string) Errors {
+	prefixed := make(Errors, 0, len(errs))
+	for _, fieldError := range errs {
+		fieldError.Field = path + "." + fieldError.Field
+		prefixed = append(prefixed, fieldError)
+	}
+
+	return prefixed
+}
+
+// Struct checks a struct, or a pointer to one, against the rules in its validate tags and returns
+// every error it finds. Fields are named by their json tags. The rules are separated by commas:
+//
+//	required                 the field can't be empty, and a slice or map can't have no items
+//	required_if=Field value  the field can't be empty when another field has the given value
+//	required_without=Field   the field can't be empty when another field is empty
+//	max=N                    a string has at most N characters, or a slice at most N items
+//	min=N                    a string has at least N characters, or a slice at least N items
+//	oneof=a b c              a string that isn't empty is one of the space separated values
+//	dive                     every item of a slice is checked as well
+//
+// Unknown rules are a programming mistake, so they panic rather than being reported to the user.
+func Struct(value interface{}) Errors {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validation: %s is not a struct", v.Type()))
+	}
+
+	return checkStruct(v, "")
+}
+
+func checkStruct(v reflect.Value, path string) Errors {
+	var errs Errors
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Embedded structs such as repository.Model are inlined into the JSON of their parent
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			errs = append(errs, checkStruct(v.Field(i), path)...)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		rules := field.Tag.Get("validate")
+		if rules == "" || rules == "-" {
+			continue
+		}
+
+		errs = append(errs, checkField(v, v.Field(i), joinPath(path, jsonName(field)), rules)...)
+	}
+
+	return errs
+}
+
+func checkField(parent reflect.Value, value reflect.Value, path string, rules string) Errors {
+	var errs Errors
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "required":
+			if isEmpty(value) {
+				errs = append(errs, FieldError{Field: path, Code: "required", Message: "is required"})
+			}
+		case "required_if":
+			other, want, ok := strings.Cut(param, " ")
+			if !ok {
+				panic(fmt.Sprintf("validation: required_if on %s needs a field and a value", path))
+			}
+
+			if isEmpty(value) && fmt.Sprint(otherField(parent, other).Interface()) == want {
+				errs = append(errs, FieldError{
+					Field:   path,
+					Code:    "required",
+					Message: fmt.Sprintf("is required when %s is %s", otherName(parent, other), want),
+				})
+			}
+		case "required_without":
+			if isEmpty(value) && isEmpty(otherField(parent, param)) {
+				errs = append(errs, FieldError{
+					Field:   path,
+					Code:    "required",
+					Message: fmt.Sprintf("is required when %s is empty", otherName(parent, param)),
+				})
+			}
+		case "max":
+			if size, unit := length(value, path); size > limit(param, path) {
+				errs = append(errs, FieldError{
+					Field:   path,
+					Code:    "max_" + unit,
+					Message: fmt.Sprintf("must be at most %s %s", param, plural(unit, param)),
+				})
+			}
+		case "min":
+			if size, unit := length(value, path); size < limit(param, path) {
+				errs = append(errs, FieldError{
+					Field:   path,
+					Code:    "min_" + unit,
+					Message: fmt.Sprintf("must be at least %s %s", param, plural(unit, param)),
+				})
+			}
+		case "oneof":
+			allowed := strings.Fields(param)
+			if value.Kind() != reflect.String || len(allowed) == 0 {
+				panic(fmt.Sprintf("validation: oneof on %s needs a string and values", path))
+			}
+
+			if value.String() != "" && !slices.Contains(allowed, value.String()) {
+				errs = append(errs, FieldError{
+					Field:   path,
+					Code:    "oneof",
+					Message: "must be one of " + listOf(allowed),
+				})
+			}
+		case "dive":
+			if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+				panic(fmt.Sprintf("validation: dive on %s needs a slice", path))
+			}
+
+			for i := 0; i < value.Len(); i++ {
+				item := value.Index(i)
+				for item.Kind() == reflect.Ptr && !item.IsNil() {
+					item = item.Elem()
+				}
+
+				if item.Kind() == reflect.Struct {
+					errs = append(errs, checkStruct(item, joinPath(path, strconv.Itoa(i)))...)
+				}
+			}
+		default:
+			panic(fmt.Sprintf("validation: unknown rule %q on %s", name, path))
+		}
+	}
+
+	return errs
+}
+
+// isEmpty reports whether a value counts as missing for the required rules. A slice or map with no
+// items is empty as well as a nil one, so that "authors": [] isn't taken as a list of authors.
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+
+	return value.IsZero()
+}
+
+// length measures a string in characters or a slice in items, naming the unit for the error code
+func length(value reflect.Value, path string) (int, string) {
+	switch value.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(value.String()), "length"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return value.Len(), "items"
+	}
+
+	panic(fmt.Sprintf("validation: %s has no length", path))
+}
+
+func limit(param string, path string) int {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		panic(fmt.Sprintf("validation: bad limit %q on %s", param, path))
+	}
+
+	return n
+}
+
+// plural names the unit of a limit for a message, such as "20 characters" or "1 item"
+func plural(unit string, param string) string {
+	noun := "item"
+	if unit == "length" {
+		noun = "character"
+	}
+
+	if param == "1" {
+		return noun
+	}
+
+	return noun + "s"
+}
+
+// listOf joins values for a message, such as "a, b or c"
+func listOf(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+
+	return strings.Join(values[:len(values)-1], ", ") + " or " + values[len(values)-1]
+}
+
+func otherField(parent reflect.Value, name string) reflect.Value {
+	field := parent.FieldByName(name)
+	if !field.IsValid() {
+		panic(fmt.Sprintf("validation: %s has no field %s", parent.Type(), name))
+	}
+
+	return field
+}
+
+// otherName is the json name of another field, used in messages so they match the request body
+func otherName(parent reflect.Value, name string) string {
+	field, _ := parent.Type().FieldByName(name)
+
+	return jsonName(field)
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+
+	return name
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
@@ -0,0 +1,144 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+)
+
+type embedded struct {
+	Code string `json:"code" validate:"max=3"`
+}
+
+type item struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type listing struct {
+	Items  []item            `json:"items" validate:"required"`
+	Labels map[string]string `json:"labels" validate:"required"`
+}
+
+type sample struct {
+	embedded
+	Title    string   `json:"title" validate:"required,max=5"`
+	Tags     []string `json:"tags" validate:"min=1,max=2"`
+	Items    []item   `json:"items" validate:"dive"`
+	Active   bool     `json:"active"`
+	Owner    string   `json:"owner" validate:"required_if=Active true"`
+	First    string   `json:"first" validate:"required_without=Last"`
+	Last     string   `json:"last"`
+	Kind     string   `json:"kind" validate:"oneof=book comic"`
+	Untagged string
+}
+
+func TestStruct(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want1 Errors
+	}{
+		{
+			name: "Valid",
+			value: sample{
+				Title: "Dune",
+				Tags:  []string{"sf"},
+				Items: []item{{Name: "a"}},
+				First: "Frank",
+			},
+			want1: nil,
+		},
+		{
+			name:  "Pointers are followed",
+			value: &sample{Title: "Dune", Tags: []string{"sf"}, Last: "Herbert"},
+			want1: nil,
+		},
+		{
+			name: "Every error is returned",
+			value: sample{
+				embedded: embedded{Code: "ABCD"},
+				Title:    "",
+				Items:    []item{{Name: "a"}, {}},
+				Active:   true,
+				Kind:     "film",
+			},
+			want1: Errors{
+				{Field: "code", Code: "max_length", Message: "must be at most 3 characters"},
+				{Field: "title", Code: "required", Message: "is required"},
+				{Field: "tags", Code: "min_items", Message: "must be at least 1 item"},
+				{Field: "items.1.name", Code: "required", Message: "is required"},
+				{Field: "owner", Code: "required", Message: "is required when active is true"},
+				{Field: "first", Code: "required", Message: "is required when last is empty"},
+				{Field: "kind", Code: "oneof", Message: "must be one of book or comic"},
+			},
+		},
+		{
+			name:  "Empty slices and maps are missing",
+			value: listing{Items: []item{}, Labels: map[string]string{}},
+			want1: Errors{
+				{Field: "items", Code: "required", Message: "is required"},
+				{Field: "labels", Code: "required", Message: "is required"},
+			},
+		},
+		{
+			name:  "Slices and maps with items are present",
+			value: listing{Items: []item{{Name: "a"}}, Labels: map[string]string{"a": "b"}},
+			want1: nil,
+		},
+		{
+			name:  "Lengths count characters rather than bytes",
+			value: sample{Title: "Élan!", Tags: []string{"a", "b", "c"}, Last: "x"},
+			want1: Errors{
+				{Field: "tags", Code: "max_items", Message: "must be at most 2 items"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1 := Struct(tt.value)
+
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("Struct got1 = %v, want1: %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestStruct_unknownRule(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Struct did not panic on an unknown rule")
+		}
+	}()
+
+	Struct(struct {
+		Name string `validate:"sometimes"`
+	}{})
+}
+
+func TestErrors(t *testing.T) {
+	errs := Errors{
+		{Field: "title", Code: "required", Message: "is required"},
+		{Field: "shelf", Code: "max_length", Message: "must be at most 20 characters"},
+	}
+
+	if got1, want1 := errs.Error(), "title is required; shelf must be at most 20 characters"; got1 != want1 {
+		t.Errorf("Error got1 = %v, want1: %v", got1, want1)
+	}
+
+	if got1 := Errors(nil).Err(); got1 != nil {
+		t.Errorf("Err got1 = %v, want1: nil", got1)
+	}
+
+	if got1 := errs.Err(); !reflect.DeepEqual(got1, error(errs)) {
+		t.Errorf("Err got1 = %v, want1: %v", got1, errs)
+	}
+
+	want1 := Errors{
+		{Field: "authors.0.title", Code: "required", Message: "is required"},
+		{Field: "authors.0.shelf", Code: "max_length", Message: "must be at most 20 characters"},
+	}
+	if got1 := errs.Prefix("authors.0"); !reflect.DeepEqual(got1, want1) {
+		t.Errorf("Prefix got1 = %v, want1: %v", got1, want1)
+	}
+}